@@ -7,17 +7,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/leapstack-labs/leapsql/internal/deps"
 	"github.com/leapstack-labs/leapsql/internal/docs"
 	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/lint"
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	"github.com/leapstack-labs/leapsql/internal/project"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
 )
 
 const (
 	defaultModelsDir = "models"
 	defaultSeedsDir  = "seeds"
 	defaultMacrosDir = "macros"
+	defaultTestsDir  = "tests"
 	defaultStateFile = ".leapsql/state.db"
 )
 
@@ -33,8 +41,12 @@ var (
 	modelsDir    string
 	seedsDir     string
 	macrosDir    string
+	testsDir     string
+	vendorDir    string
 	databasePath string
 	statePath    string
+	configPath   string
+	varsYAML     string
 	env          string
 	verbose      bool
 )
@@ -56,9 +68,14 @@ func main() {
 			Description: "List all models and their dependencies",
 			Run:         listCmd,
 		},
+		"test": {
+			Name:        "test",
+			Description: "Run data tests against built models",
+			Run:         testCmd,
+		},
 		"seed": {
 			Name:        "seed",
-			Description: "Load seed data from CSV files",
+			Description: "Load seed data from CSV, Parquet, JSON and Excel files",
 			Run:         seedCmd,
 		},
 		"dag": {
@@ -66,6 +83,21 @@ func main() {
 			Description: "Show the dependency graph",
 			Run:         dagCmd,
 		},
+		"lint": {
+			Name:        "lint",
+			Description: "Check the SQL of models against lint rules",
+			Run:         lintCmd,
+		},
+		"fmt": {
+			Name:        "fmt",
+			Description: "Format the SQL of models",
+			Run:         fmtCmd,
+		},
+		"deps": {
+			Name:        "deps",
+			Description: "Install packages declared in packages.yaml",
+			Run:         depsCmd,
+		},
 		"docs": {
 			Name:        "docs",
 			Description: "Generate and serve documentation site",
@@ -110,7 +142,7 @@ func printUsage(commands map[string]*Command) {
 	fmt.Println("Usage: leapsql <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	for _, cmd := range []string{"run", "build", "list", "seed", "dag", "docs", "version"} {
+	for _, cmd := range []string{"run", "build", "list", "test", "seed", "fmt", "lint", "deps", "dag", "docs", "version"} {
 		if c, ok := commands[cmd]; ok {
 			fmt.Printf("  %-12s %s\n", c.Name, c.Description)
 		}
@@ -123,8 +155,12 @@ func setupFlags(fs *flag.FlagSet) {
 	fs.StringVar(&modelsDir, "models", defaultModelsDir, "Path to models directory")
 	fs.StringVar(&seedsDir, "seeds", defaultSeedsDir, "Path to seeds directory")
 	fs.StringVar(&macrosDir, "macros", defaultMacrosDir, "Path to macros directory")
+	fs.StringVar(&testsDir, "tests", defaultTestsDir, "Path to singular tests directory")
+	fs.StringVar(&vendorDir, "vendor", deps.DefaultVendorDir, "Path to installed packages directory")
 	fs.StringVar(&databasePath, "database", "", "Path to DuckDB database (empty for in-memory)")
 	fs.StringVar(&statePath, "state", defaultStateFile, "Path to state database")
+	fs.StringVar(&configPath, "config", project.DefaultConfigFile, "Path to project config file")
+	fs.StringVar(&varsYAML, "vars", "", "Project variables overriding config vars, e.g. '{start_date: 2024-01-01}'")
 	fs.StringVar(&env, "env", "dev", "Environment name")
 	fs.BoolVar(&verbose, "v", false, "Verbose output")
 }
@@ -138,17 +174,57 @@ func createEngine() (*engine.Engine, error) {
 		}
 	}
 
+	projectCfg, err := project.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	vars := projectCfg.Vars
+	if varsYAML != "" {
+		override, err := project.ParseVars(varsYAML)
+		if err != nil {
+			return nil, err
+		}
+		vars = project.MergeVars(vars, override)
+	}
+
 	cfg := engine.Config{
 		ModelsDir:    modelsDir,
 		SeedsDir:     seedsDir,
 		MacrosDir:    macrosDir,
+		TestsDir:     testsDir,
+		VendorDir:    vendorDir,
 		DatabasePath: databasePath,
 		StatePath:    statePath,
+		Vars:         vars,
+		OnRunStart:   projectCfg.OnRunStart,
+		OnRunEnd:     projectCfg.OnRunEnd,
+	}
+	if t := projectCfg.Target; t != nil {
+		cfg.Target = &starctx.TargetInfo{Type: t.Type, Schema: t.Schema, Database: t.Database}
+		if cfg.Target.Type == "" {
+			cfg.Target.Type = "duckdb"
+		}
 	}
 
 	return engine.New(cfg)
 }
 
+// printWarnings prints non-fatal model warnings collected during discovery.
+func printWarnings(eng *engine.Engine) {
+	models := eng.GetModels()
+	paths := make([]string, 0, len(models))
+	for path := range models {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, w := range models[path].Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+	}
+}
+
 // runCmd executes the run/build command.
 func runCmd(args []string) error {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
@@ -166,24 +242,17 @@ func runCmd(args []string) error {
 	ctx := context.Background()
 	startTime := time.Now()
 
-	// Load seeds
-	if verbose {
-		fmt.Println("Loading seeds...")
-	}
-	if err := eng.LoadSeeds(ctx); err != nil {
-		return fmt.Errorf("failed to load seeds: %w", err)
-	}
-
-	// Discover models
+	// Discover models and seeds; seeds are loaded during the run as needed
 	if verbose {
 		fmt.Println("Discovering models...")
 	}
 	if err := eng.Discover(); err != nil {
 		return fmt.Errorf("failed to discover models: %w", err)
 	}
+	printWarnings(eng)
 
 	models := eng.GetModels()
-	fmt.Printf("Found %d models\n", len(models))
+	fmt.Printf("Found %d models and %d seeds\n", len(models), len(eng.GetSeeds()))
 
 	// Run models
 	var run interface{ ID() string }
@@ -243,11 +312,13 @@ func listCmd(args []string) error {
 	if err := eng.Discover(); err != nil {
 		return fmt.Errorf("failed to discover models: %w", err)
 	}
+	printWarnings(eng)
 
 	models := eng.GetModels()
+	seeds := eng.GetSeeds()
 	graph := eng.GetGraph()
 
-	fmt.Printf("Models (%d total):\n\n", len(models))
+	fmt.Printf("Models (%d total, %d seeds):\n\n", len(models), len(seeds))
 
 	// Get execution order
 	sorted, err := graph.TopologicalSort()
@@ -257,6 +328,9 @@ func listCmd(args []string) error {
 
 	for i, node := range sorted {
 		m := models[node.ID]
+		if m == nil {
+			m = seeds[node.ID]
+		}
 		if m == nil {
 			continue
 		}
@@ -273,6 +347,106 @@ func listCmd(args []string) error {
 	return nil
 }
 
+// testCmd runs data tests.
+func testCmd(args []string) error {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	setupFlags(fs)
+	select_ := fs.String("select", "", "Comma-separated list of models to test")
+	unit := fs.Bool("unit", false, "Run model unit tests against fixtures instead of data tests")
+	fs.Parse(args)
+
+	eng, err := createEngine()
+	if err != nil {
+		return err
+	}
+	defer eng.Close()
+
+	ctx := context.Background()
+
+	if err := eng.Discover(); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	printWarnings(eng)
+
+	var selected []string
+	if *select_ != "" {
+		selected = strings.Split(*select_, ",")
+		for i := range selected {
+			selected[i] = strings.TrimSpace(selected[i])
+		}
+	}
+
+	if *unit {
+		return unitTestCmd(ctx, eng, selected)
+	}
+
+	run, results, err := eng.Test(ctx, env, selected)
+	if err != nil {
+		return fmt.Errorf("test failed: %w", err)
+	}
+
+	for _, r := range results {
+		target := r.TestName
+		if r.ModelPath != "" {
+			target = fmt.Sprintf("%s %s", r.ModelPath, r.TestName)
+		}
+		switch r.Status {
+		case "pass":
+			fmt.Printf("  PASS  %s\n", target)
+		case "error":
+			fmt.Printf("  ERROR %s: %s\n", target, r.Error)
+		default:
+			fmt.Printf("  %-5s %s (%d failing rows)\n", strings.ToUpper(string(r.Status)), target, r.Failures)
+			if verbose {
+				for _, row := range r.Sample {
+					fmt.Printf("          %v\n", row)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("Run %s: %s (%d tests)\n", run.ID, run.Status, len(results))
+	if run.Error != "" {
+		return fmt.Errorf("%s", run.Error)
+	}
+	return nil
+}
+
+// unitTestCmd runs model unit tests and prints row diffs for failures.
+func unitTestCmd(ctx context.Context, eng *engine.Engine, selected []string) error {
+	results, err := eng.UnitTest(ctx, selected)
+	if err != nil {
+		return fmt.Errorf("unit test failed: %w", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		target := fmt.Sprintf("%s %s", r.ModelPath, r.Name)
+		switch {
+		case r.Error != "":
+			failed++
+			fmt.Printf("  ERROR %s: %s\n", target, r.Error)
+		case r.Passed:
+			fmt.Printf("  PASS  %s\n", target)
+		default:
+			failed++
+			fmt.Printf("  FAIL  %s\n", target)
+			for _, row := range r.Missing {
+				fmt.Printf("          - %v\n", row)
+			}
+			for _, row := range r.Unexpected {
+				fmt.Printf("          + %v\n", row)
+			}
+		}
+	}
+
+	fmt.Printf("%d unit tests, %d failed\n", len(results), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d unit tests failed", failed, len(results))
+	}
+	return nil
+}
+
 // seedCmd loads seed data.
 func seedCmd(args []string) error {
 	fs := flag.NewFlagSet("seed", flag.ExitOnError)
@@ -346,6 +520,183 @@ func dagCmd(args []string) error {
 	return nil
 }
 
+// fmtCmd formats the SQL of model files, or with --check lists the files
+// that aren't formatted. Paths default to the models directory.
+func fmtCmd(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	setupFlags(fs)
+	check := fs.Bool("check", false, "Report unformatted files without changing them")
+	fs.Parse(args)
+
+	projectCfg, err := project.Load(configPath)
+	if err != nil {
+		return err
+	}
+	dialect := lineage.DefaultDialect()
+	if t := projectCfg.Target; t != nil && t.Type != "" {
+		d, ok := lineage.GetDialect(t.Type)
+		if !ok {
+			return fmt.Errorf("unknown target type %q", t.Type)
+		}
+		dialect = d
+	}
+	opts, err := projectCfg.Format.PrintOptions(dialect)
+	if err != nil {
+		return err
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{modelsDir}
+	}
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(p) == ".sql" {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var unformatted, skipped int
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		formatted, err := parser.FormatContent(string(data), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipped %s: %v\n", file, err)
+			skipped++
+			continue
+		}
+		if formatted == string(data) {
+			continue
+		}
+		unformatted++
+		if *check {
+			fmt.Printf("Would reformat %s\n", file)
+			continue
+		}
+		if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Formatted %s\n", file)
+	}
+
+	if *check && (unformatted > 0 || skipped > 0) {
+		return fmt.Errorf("%d of %d files need formatting, %d could not be formatted", unformatted, len(files), skipped)
+	}
+	if verbose {
+		fmt.Printf("%d files checked, %d changed, %d skipped\n", len(files), unformatted, skipped)
+	}
+	return nil
+}
+
+// lintCmd checks models against the lint rules enabled in the project
+// config, and fails if any finding is an error.
+func lintCmd(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	setupFlags(fs)
+	select_ := fs.String("select", "", "Comma-separated list of models to lint")
+	format := fs.String("format", lint.FormatText, "Output format: text, json or sarif")
+	fix := fs.Bool("fix", false, "Apply the fixes of findings to model files")
+	fs.Parse(args)
+
+	projectCfg, err := project.Load(configPath)
+	if err != nil {
+		return err
+	}
+	if err := projectCfg.Lint.Validate(); err != nil {
+		return err
+	}
+
+	eng, err := createEngine()
+	if err != nil {
+		return err
+	}
+	defer eng.Close()
+
+	if err := eng.Discover(); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	models := eng.GetModels()
+	var paths []string
+	if *select_ != "" {
+		for _, path := range strings.Split(*select_, ",") {
+			paths = append(paths, strings.TrimSpace(path))
+		}
+	} else {
+		for path := range models {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+
+	linter := lint.New(projectCfg.Lint, eng.Dialect())
+	wd, _ := os.Getwd()
+	var findings []lint.Finding
+	failed := 0
+	for _, path := range paths {
+		m, ok := models[path]
+		if !ok {
+			return fmt.Errorf("model %s not found", path)
+		}
+		if m.Language == parser.LanguageStarlark || m.Package != "" {
+			continue
+		}
+
+		rendered, err := eng.RenderModel(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+			continue
+		}
+		found, err := linter.Lint(m, rendered.SQL, rendered.FilePosition)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: model %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		if *fix {
+			content, unfixed := lint.ApplyFixes(m.RawContent, found)
+			if fixed := len(found) - len(unfixed); fixed > 0 {
+				if err := os.WriteFile(m.FilePath, []byte(content), 0644); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Fixed %d problems in %s\n", fixed, m.FilePath)
+			}
+			found = unfixed
+		}
+		for i := range found {
+			if rel, err := filepath.Rel(wd, found[i].File); err == nil && !strings.HasPrefix(rel, "..") {
+				found[i].File = rel
+			}
+			if found[i].Severity == lint.SeverityError {
+				failed++
+			}
+		}
+		findings = append(findings, found...)
+	}
+
+	if err := lint.Write(os.Stdout, *format, findings); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("lint failed with %d errors", failed)
+	}
+	return nil
+}
+
 // versionCmd shows version information.
 func versionCmd(args []string) error {
 	fmt.Println("LeapSQL v0.1.0")
@@ -353,6 +704,60 @@ func versionCmd(args []string) error {
 	return nil
 }
 
+// depsCmd handles package management subcommands.
+func depsCmd(args []string) error {
+	if len(args) < 1 {
+		fmt.Println("Usage: leapsql deps <install> [options]")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  install  Resolve, vendor and lock the packages in packages.yaml")
+		return nil
+	}
+
+	switch args[0] {
+	case "install":
+		return depsInstallCmd(args[1:])
+	default:
+		return fmt.Errorf("unknown deps subcommand: %s", args[0])
+	}
+}
+
+// depsInstallCmd vendors packages and writes the lockfile.
+func depsInstallCmd(args []string) error {
+	fs := flag.NewFlagSet("deps install", flag.ExitOnError)
+	manifestPath := fs.String("packages", deps.DefaultManifestFile, "Path to packages.yaml")
+	lockPath := fs.String("lock", deps.DefaultLockFile, "Path to packages.lock")
+	vendorPath := fs.String("vendor", deps.DefaultVendorDir, "Directory to install packages into")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: leapsql deps install [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	installer := &deps.Installer{
+		ManifestPath: *manifestPath,
+		LockPath:     *lockPath,
+		VendorDir:    *vendorPath,
+	}
+
+	lock, err := installer.Install(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	for _, pkg := range lock.Packages {
+		fmt.Printf("  %-20s %s (%.12s)\n", pkg.Name, pkg.Version, pkg.Commit)
+	}
+	fmt.Printf("Installed %d packages into %s\n", len(lock.Packages), *vendorPath)
+	return nil
+}
+
 // docsCmd handles the docs subcommands.
 func docsCmd(args []string) error {
 	if len(args) < 1 {
@@ -378,6 +783,7 @@ func docsCmd(args []string) error {
 func docsBuildCmd(args []string) error {
 	fs := flag.NewFlagSet("docs build", flag.ExitOnError)
 	modelsPath := fs.String("models", modelsDir, "Path to models directory")
+	seedsPath := fs.String("seeds", seedsDir, "Path to seeds directory")
 	outputPath := fs.String("output", "./docs-site", "Output directory for generated site")
 	projectName := fs.String("project", "LeapSQL Project", "Project name for documentation")
 
@@ -409,6 +815,10 @@ func docsBuildCmd(args []string) error {
 		return fmt.Errorf("failed to load models: %w", err)
 	}
 
+	if err := gen.LoadSeeds(*seedsPath); err != nil {
+		return fmt.Errorf("failed to load seeds: %w", err)
+	}
+
 	if err := gen.Build(*outputPath); err != nil {
 		return fmt.Errorf("failed to build docs: %w", err)
 	}
@@ -423,6 +833,7 @@ func docsBuildCmd(args []string) error {
 func docsServeCmd(args []string) error {
 	fs := flag.NewFlagSet("docs serve", flag.ExitOnError)
 	modelsPath := fs.String("models", modelsDir, "Path to models directory")
+	seedsPath := fs.String("seeds", seedsDir, "Path to seeds directory")
 	outputPath := fs.String("output", "./.leapsql-docs", "Output directory for generated site")
 	projectName := fs.String("project", "LeapSQL Project", "Project name for documentation")
 	port := fs.Int("port", 8080, "Port to serve on")
@@ -454,6 +865,10 @@ func docsServeCmd(args []string) error {
 		return fmt.Errorf("failed to load models: %w", err)
 	}
 
+	if err := gen.LoadSeeds(*seedsPath); err != nil {
+		return fmt.Errorf("failed to load seeds: %w", err)
+	}
+
 	if err := gen.Serve(*outputPath, *port); err != nil {
 		return fmt.Errorf("failed to serve docs: %w", err)
 	}
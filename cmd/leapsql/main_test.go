@@ -2,9 +2,15 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/adapter"
+	"github.com/leapstack-labs/leapsql/internal/state"
 )
 
 func testdataDir(t *testing.T) string {
@@ -93,6 +99,68 @@ func TestRunCmd(t *testing.T) {
 	}
 }
 
+func TestRunCmd_Vars(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"leapsql.yaml":        "vars:\n  start_date: 2024-01-01\n  region: eu\n",
+		"models/windowed.sql": `SELECT '{{ var("start_date") }}' AS start_date, '{{ var("region") }}' AS region, '{{ var("end_date", "open") }}' AS end_date`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{
+		"-models", filepath.Join(tmpDir, "models"),
+		"-seeds", filepath.Join(tmpDir, "seeds"),
+		"-macros", filepath.Join(tmpDir, "macros"),
+		"-config", filepath.Join(tmpDir, "leapsql.yaml"),
+		"-database", filepath.Join(tmpDir, "test.db"),
+		"-state", filepath.Join(tmpDir, "state.db"),
+		"-env", "test",
+		"-vars", "{region: us}",
+	}
+	if err := runCmd(args); err != nil {
+		t.Fatalf("runCmd() error = %v", err)
+	}
+
+	store := state.NewSQLiteStore()
+	if err := store.Open(filepath.Join(tmpDir, "state.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	run, err := store.GetLatestRun("test")
+	if err != nil || run == nil {
+		t.Fatalf("GetLatestRun() = %v, %v", run, err)
+	}
+	if run.Vars["start_date"] != "2024-01-01" || run.Vars["region"] != "us" {
+		t.Errorf("run vars = %v, want start_date from config and region from -vars", run.Vars)
+	}
+
+	db := adapter.NewDuckDBAdapter()
+	if err := db.Connect(context.Background(), adapter.Config{Path: filepath.Join(tmpDir, "test.db")}); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(context.Background(), "SELECT start_date, region, end_date FROM windowed")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer rows.Close()
+	var start, region, end string
+	if !rows.Next() || rows.Scan(&start, &region, &end) != nil {
+		t.Fatal("windowed has no rows")
+	}
+	if start != "2024-01-01" || region != "us" || end != "open" {
+		t.Errorf("windowed = %s, %s, %s", start, region, end)
+	}
+}
+
 func TestRunCmd_Select(t *testing.T) {
 	td := testdataDir(t)
 	tmpDir := t.TempDir()
@@ -144,6 +212,90 @@ func TestRunCmd_SelectWithDownstream(t *testing.T) {
 	}
 }
 
+func TestTestCmd(t *testing.T) {
+	td := testdataDir(t)
+	tmpDir := t.TempDir()
+
+	args := []string{
+		"-models", filepath.Join(td, "models"),
+		"-seeds", filepath.Join(td, "seeds"),
+		"-macros", filepath.Join(td, "macros"),
+		"-tests", filepath.Join(tmpDir, "tests"),
+		"-database", filepath.Join(tmpDir, "warehouse.duckdb"),
+		"-state", filepath.Join(tmpDir, "state.db"),
+	}
+
+	// Tests run against built models
+	if err := runCmd(args); err != nil {
+		t.Fatalf("runCmd() error = %v", err)
+	}
+
+	err := testCmd(args)
+	if err != nil {
+		t.Errorf("testCmd() error = %v", err)
+	}
+}
+
+func TestTestCmd_Unit(t *testing.T) {
+	td := testdataDir(t)
+	tmpDir := t.TempDir()
+
+	args := []string{
+		"-models", filepath.Join(td, "models"),
+		"-macros", filepath.Join(td, "macros"),
+		"-state", filepath.Join(tmpDir, "state.db"),
+		"-unit",
+	}
+
+	err := testCmd(args)
+	if err != nil {
+		t.Errorf("testCmd(-unit) error = %v", err)
+	}
+}
+
+func TestDepsInstallCmd(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+
+	// A local package repository with a single tagged release
+	work := filepath.Join(tmpDir, "work")
+	os.MkdirAll(filepath.Join(work, "macros"), 0755)
+	os.WriteFile(filepath.Join(work, "macros", "text.star"), []byte("def hello():\n    return 'hi'\n"), 0644)
+	repo := filepath.Join(tmpDir, "text.git")
+	for _, args := range [][]string{
+		{"-C", work, "init", "--quiet"},
+		{"-C", work, "add", "-A"},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init"},
+		{"-C", work, "tag", "v0.1.0"},
+		{"clone", "--quiet", "--bare", work, repo},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	manifest := filepath.Join(tmpDir, "packages.yaml")
+	os.WriteFile(manifest, []byte("packages:\n  - name: text\n    git: "+repo+"\n    version: \"^0.1.0\"\n"), 0644)
+
+	err := depsCmd([]string{"install",
+		"-packages", manifest,
+		"-lock", filepath.Join(tmpDir, "packages.lock"),
+		"-vendor", filepath.Join(tmpDir, "_vendor"),
+	})
+	if err != nil {
+		t.Fatalf("depsCmd(install) error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "_vendor", "text", "macros", "text.star")); err != nil {
+		t.Errorf("package not vendored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "packages.lock")); err != nil {
+		t.Errorf("lockfile not written: %v", err)
+	}
+}
+
 func TestCreateEngine_BadStatePath(t *testing.T) {
 	td := testdataDir(t)
 
@@ -165,3 +317,80 @@ func TestMain(m *testing.M) {
 	// Run tests
 	os.Exit(m.Run())
 }
+
+func TestFmtCmd(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.sql")
+	if err := os.WriteFile(model, []byte("SELECT a,b FROM t WHERE a>1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "leapsql.yaml")
+	if err := os.WriteFile(config, []byte("format:\n  keyword_case: lower\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-models", dir, "-config", config}
+
+	if err := fmtCmd(append(args, "-check")); err == nil {
+		t.Error("fmtCmd() -check should fail for an unformatted file")
+	}
+	if err := fmtCmd(args); err != nil {
+		t.Fatalf("fmtCmd() error = %v", err)
+	}
+	data, err := os.ReadFile(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select a, b from t where a > 1\n"; string(data) != want {
+		t.Errorf("formatted model = %q, want %q", data, want)
+	}
+	if err := fmtCmd(append(args, "-check")); err != nil {
+		t.Errorf("fmtCmd() -check error = %v", err)
+	}
+
+	// A file that can't be formatted fails the check too
+	if err := os.WriteFile(filepath.Join(dir, "broken.sql"), []byte("SELECT (a FROM t\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fmtCmd(append(args, "-check")); err == nil {
+		t.Error("fmtCmd() -check should fail for a file that can't be formatted")
+	}
+}
+
+func TestLintCmd(t *testing.T) {
+	td := testdataDir(t)
+	tmpDir := t.TempDir()
+
+	args := []string{
+		"-models", filepath.Join(td, "models"),
+		"-seeds", filepath.Join(td, "seeds"),
+		"-macros", filepath.Join(td, "macros"),
+		"-state", filepath.Join(tmpDir, "state.db"),
+		"-format", "sarif",
+	}
+	if err := lintCmd(args); err != nil {
+		t.Errorf("lintCmd() error = %v", err)
+	}
+
+	// Errors fail the command
+	config := filepath.Join(tmpDir, "leapsql.yaml")
+	if err := os.WriteFile(config, []byte("lint:\n  directories:\n    marts:\n      implicit-cross-join: error\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := lintCmd(append(args, "-config", config)); err == nil {
+		t.Error("lintCmd() should fail when a finding is an error")
+	}
+
+	// So does SQL that doesn't parse
+	models := filepath.Join(tmpDir, "models")
+	if err := os.MkdirAll(models, 0755); err != nil {
+		t.Fatal(err)
+	}
+	broken := "/*---\nmaterialized: view\n---*/\nSELECT a,\n  (b * ) AS c\nFROM t\n"
+	if err := os.WriteFile(filepath.Join(models, "broken.sql"), []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := lintCmd([]string{"-models", models, "-state", filepath.Join(tmpDir, "broken.db"), "-format", "json"})
+	if err == nil || !strings.Contains(err.Error(), "1 errors") {
+		t.Errorf("lintCmd() error = %v, want 1 error for the syntax error", err)
+	}
+}
@@ -5,6 +5,9 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
 )
 
 // Config holds the configuration for connecting to a database.
@@ -74,6 +77,60 @@ type Metadata struct {
 	SizeBytes int64
 }
 
+// CSVOptions controls how a CSV file is parsed and loaded.
+// Zero values fall back to the database's automatic detection.
+type CSVOptions struct {
+	// Delimiter is the field separator (e.g., ",", ";", "\t")
+	Delimiter string
+
+	// Quote is the quoting character
+	Quote string
+
+	// Header indicates whether the first line contains column names (default true)
+	Header *bool
+
+	// NullStrings are the values interpreted as NULL
+	NullStrings []string
+
+	// DateFormat is a strftime-style format for DATE columns (e.g., "%d/%m/%Y")
+	DateFormat string
+
+	// TimestampFormat is a strftime-style format for TIMESTAMP columns
+	TimestampFormat string
+
+	// ColumnTypes overrides the inferred type of specific columns (e.g., zip: VARCHAR)
+	ColumnTypes map[string]string
+
+	// FullRefresh drops and recreates the table. Otherwise an existing table
+	// with matching columns is emptied and reloaded, keeping its definition.
+	FullRefresh bool
+}
+
+// File formats supported by Adapter.LoadFile.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+	FormatJSON    = "json"
+	FormatNDJSON  = "ndjson"
+	FormatExcel   = "xlsx"
+)
+
+// fileFormats maps file extensions to their format.
+var fileFormats = map[string]string{
+	".csv":     FormatCSV,
+	".parquet": FormatParquet,
+	".json":    FormatJSON,
+	".ndjson":  FormatNDJSON,
+	".jsonl":   FormatNDJSON,
+	".xlsx":    FormatExcel,
+}
+
+// FileFormat returns the format of a data file (or glob pattern) based on its
+// extension, or "" if the format is not supported.
+func FileFormat(path string) string {
+	return fileFormats[strings.ToLower(filepath.Ext(path))]
+}
+
 // Rows wraps sql.Rows to provide a consistent interface across adapters.
 type Rows struct {
 	*sql.Rows
@@ -101,4 +158,28 @@ type Adapter interface {
 	// LoadCSV loads data from a CSV file into a table.
 	// If the table doesn't exist, it will be created with inferred schema.
 	LoadCSV(ctx context.Context, tableName string, filePath string) error
+
+	// LoadFile loads a CSV, Parquet, JSON, NDJSON or Excel file into a table,
+	// dispatching on the file extension. filePath may be a glob pattern to
+	// load several files of the same format into one table. Excel files
+	// need DuckDB's spatial extension, downloaded on first use.
+	LoadFile(ctx context.Context, tableName string, filePath string, opts CSVOptions) error
+}
+
+// Float64 returns the value of a numeric driver type that isn't a Go
+// number, such as DuckDB's DECIMAL, and whether v is one.
+func Float64(v any) (float64, bool) {
+	if f, ok := v.(interface{ Float64() float64 }); ok {
+		return f.Float64(), true
+	}
+	if v == nil {
+		return 0, false
+	}
+	// Driver types such as DuckDB's DECIMAL implement Float64 on the pointer
+	ptr := reflect.New(reflect.TypeOf(v))
+	ptr.Elem().Set(reflect.ValueOf(v))
+	if f, ok := ptr.Interface().(interface{ Float64() float64 }); ok {
+		return f.Float64(), true
+	}
+	return 0, false
 }
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	_ "github.com/marcboeker/go-duckdb"
 )
@@ -162,19 +164,241 @@ func (a *DuckDBAdapter) LoadCSV(ctx context.Context, tableName string, filePath
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	// Use DuckDB's read_csv_auto to load the CSV with automatic schema detection
-	query := fmt.Sprintf(
-		"CREATE OR REPLACE TABLE %s AS SELECT * FROM read_csv_auto('%s', header=true)",
-		tableName,
-		absPath,
-	)
+	source := fmt.Sprintf("read_csv(%s)", strings.Join(csvReaderArgs(absPath, CSVOptions{}), ", "))
+	return a.loadSource(ctx, tableName, source, true)
+}
+
+// LoadFile loads a data file into a table, choosing the DuckDB reader from the
+// file extension (see FileFormat). filePath may be a glob pattern such as
+// "events/*.parquet" to load several files into one table. CSV parsing options
+// only apply to CSV files; ColumnTypes and FullRefresh apply to all formats.
+// Excel files need DuckDB's spatial extension, which is installed on first
+// use and so needs network access then.
+func (a *DuckDBAdapter) LoadFile(ctx context.Context, tableName string, filePath string, opts CSVOptions) error {
+	if a.db == nil {
+		return fmt.Errorf("database connection not established")
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	source, err := fileReader(absPath, opts)
+	if err != nil {
+		return err
+	}
+
+	if FileFormat(absPath) == FormatExcel {
+		if err := a.loadSpatial(ctx); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filepath.Base(filePath), err)
+		}
+	}
+
+	return a.loadSource(ctx, tableName, source, opts.FullRefresh)
+}
+
+// loadSpatial loads DuckDB's spatial extension, whose GDAL reader reads
+// Excel files. DuckDB downloads the extension the first time it is
+// installed, which needs network access.
+func (a *DuckDBAdapter) loadSpatial(ctx context.Context) error {
+	if err := a.Exec(ctx, "LOAD spatial"); err == nil {
+		return nil
+	}
+	if err := a.Exec(ctx, "INSTALL spatial"); err != nil {
+		return fmt.Errorf("reading Excel files needs DuckDB's spatial extension, which is downloaded on first use and could not be installed "+
+			"(run INSTALL spatial once with network access, or convert the file to CSV or Parquet): %w", err)
+	}
+	if err := a.Exec(ctx, "LOAD spatial"); err != nil {
+		return fmt.Errorf("failed to load DuckDB's spatial extension: %w", err)
+	}
+	return nil
+}
+
+// loadSource creates or reloads a table from a reader expression. Unless
+// fullRefresh is set, an existing table with the same columns is emptied and
+// reloaded so its definition (and anything depending on it) is kept.
+func (a *DuckDBAdapter) loadSource(ctx context.Context, tableName, source string, fullRefresh bool) error {
+	if schema, _, ok := strings.Cut(tableName, "."); ok {
+		if err := a.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
 
+	if !fullRefresh && a.sameColumns(ctx, tableName, source) {
+		// Keep the existing table definition and reload its rows
+		if err := a.Exec(ctx, fmt.Sprintf("DELETE FROM %s", tableName)); err != nil {
+			return fmt.Errorf("failed to truncate %s: %w", tableName, err)
+		}
+		if err := a.Exec(ctx, fmt.Sprintf("INSERT INTO %s BY NAME SELECT * FROM %s", tableName, source)); err != nil {
+			return fmt.Errorf("failed to load %s: %w", tableName, err)
+		}
+		return nil
+	}
+
+	query := fmt.Sprintf("CREATE OR REPLACE TABLE %s AS SELECT * FROM %s", tableName, source)
 	if err := a.Exec(ctx, query); err != nil {
-		return fmt.Errorf("failed to load CSV: %w", err)
+		return fmt.Errorf("failed to load %s: %w", tableName, err)
 	}
 
 	return nil
 }
 
+// sameColumns reports whether an existing table has exactly the columns and
+// types that the source query would produce.
+func (a *DuckDBAdapter) sameColumns(ctx context.Context, tableName, source string) bool {
+	meta, err := a.GetTableMetadata(ctx, tableName)
+	if err != nil {
+		return false
+	}
+
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf("DESCRIBE SELECT * FROM %s", source))
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return false
+	}
+
+	i := 0
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for j := range values {
+			ptrs[j] = &values[j]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return false
+		}
+		// DESCRIBE returns column_name, column_type, ...
+		if i >= len(meta.Columns) ||
+			!strings.EqualFold(fmt.Sprint(values[0]), meta.Columns[i].Name) ||
+			!strings.EqualFold(fmt.Sprint(values[1]), meta.Columns[i].Type) {
+			return false
+		}
+		i++
+	}
+
+	return rows.Err() == nil && i == len(meta.Columns)
+}
+
+// csvReaderArgs builds the argument list for DuckDB's read_csv function.
+func csvReaderArgs(path string, opts CSVOptions) []string {
+	header := true
+	if opts.Header != nil {
+		header = *opts.Header
+	}
+
+	args := []string{
+		quoteLiteral(path),
+		"auto_detect=true",
+		fmt.Sprintf("header=%t", header),
+	}
+
+	if opts.Delimiter != "" {
+		args = append(args, "delim="+quoteLiteral(opts.Delimiter))
+	}
+	if opts.Quote != "" {
+		args = append(args, "quote="+quoteLiteral(opts.Quote))
+	}
+	if len(opts.NullStrings) > 0 {
+		nulls := make([]string, len(opts.NullStrings))
+		for i, n := range opts.NullStrings {
+			nulls[i] = quoteLiteral(n)
+		}
+		args = append(args, "nullstr=["+strings.Join(nulls, ", ")+"]")
+	}
+	if opts.DateFormat != "" {
+		args = append(args, "dateformat="+quoteLiteral(opts.DateFormat))
+	}
+	if opts.TimestampFormat != "" {
+		args = append(args, "timestampformat="+quoteLiteral(opts.TimestampFormat))
+	}
+	if len(opts.ColumnTypes) > 0 {
+		names := make([]string, 0, len(opts.ColumnTypes))
+		for name := range opts.ColumnTypes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		types := make([]string, len(names))
+		for i, name := range names {
+			types[i] = fmt.Sprintf("%s: %s", quoteLiteral(name), quoteLiteral(opts.ColumnTypes[name]))
+		}
+		args = append(args, "types={"+strings.Join(types, ", ")+"}")
+	}
+
+	return args
+}
+
+// fileReader builds the DuckDB table function expression that reads path.
+func fileReader(path string, opts CSVOptions) (string, error) {
+	format := FileFormat(path)
+	if format == FormatExcel && strings.ContainsAny(path, "*?[") {
+		return "", fmt.Errorf("glob patterns are not supported for Excel files: %s", path)
+	}
+	path = caselessGlob(path)
+
+	var reader string
+	switch format {
+	case FormatCSV:
+		return fmt.Sprintf("read_csv(%s)", strings.Join(csvReaderArgs(path, opts), ", ")), nil
+	case FormatParquet:
+		reader = fmt.Sprintf("read_parquet(%s, union_by_name=true)", quoteLiteral(path))
+	case FormatJSON:
+		reader = fmt.Sprintf("read_json(%s, format='auto')", quoteLiteral(path))
+	case FormatNDJSON:
+		reader = fmt.Sprintf("read_json(%s, format='newline_delimited')", quoteLiteral(path))
+	case FormatExcel:
+		reader = fmt.Sprintf("st_read(%s, open_options=['HEADERS=FORCE', 'FIELD_TYPES=AUTO'])", quoteLiteral(path))
+	default:
+		return "", fmt.Errorf("unsupported file format: %s", filepath.Base(path))
+	}
+
+	if len(opts.ColumnTypes) == 0 {
+		return reader, nil
+	}
+
+	// Non-CSV readers have no type overrides, so cast the columns instead
+	names := make([]string, 0, len(opts.ColumnTypes))
+	for name := range opts.ColumnTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	casts := make([]string, len(names))
+	for i, name := range names {
+		casts[i] = fmt.Sprintf("CAST(%s AS %s) AS %s", name, opts.ColumnTypes[name], name)
+	}
+	return fmt.Sprintf("(SELECT * REPLACE (%s) FROM %s)", strings.Join(casts, ", "), reader), nil
+}
+
+// caselessGlob makes the extension of a glob pattern match in any case, as
+// FileFormat reads it, so "orders/*.csv" also loads "orders/2024.CSV".
+func caselessGlob(path string) string {
+	if !strings.ContainsAny(path, "*?[") {
+		return path
+	}
+	ext := filepath.Ext(path)
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(path, ext))
+	for _, r := range ext {
+		if lower, upper := unicode.ToLower(r), unicode.ToUpper(r); lower != upper {
+			b.WriteString("[" + string(lower) + string(upper) + "]")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// quoteLiteral quotes a string as a SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // Ensure DuckDBAdapter implements Adapter interface
 var _ Adapter = (*DuckDBAdapter)(nil)
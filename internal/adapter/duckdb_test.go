@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -272,6 +273,247 @@ func TestDuckDBAdapter_LoadCSV(t *testing.T) {
 	}
 }
 
+func TestDuckDBAdapter_LoadFile_CSVOptions(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewDuckDBAdapter()
+
+	if err := adapter.Connect(ctx, Config{Path: ":memory:"}); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer adapter.Close()
+
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "zips.csv")
+
+	csvContent := `id;zip;signup;note
+001;02134;31/01/2024;NA
+002;10001;15/02/2024;'a;b'`
+
+	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("failed to write CSV file: %v", err)
+	}
+
+	opts := CSVOptions{
+		Delimiter:   ";",
+		Quote:       "'",
+		NullStrings: []string{"NA"},
+		DateFormat:  "%d/%m/%Y",
+		ColumnTypes: map[string]string{"id": "VARCHAR", "zip": "VARCHAR"},
+	}
+	if err := adapter.LoadFile(ctx, "raw.zips", csvPath, opts); err != nil {
+		t.Fatalf("failed to load CSV: %v", err)
+	}
+
+	rows, err := adapter.Query(ctx, "SELECT id, zip, CAST(signup AS VARCHAR), note FROM raw.zips ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to query loaded data: %v", err)
+	}
+
+	var got [][4]any
+	for rows.Next() {
+		var id, zip, signup string
+		var note *string
+		if err := rows.Scan(&id, &zip, &signup, &note); err != nil {
+			rows.Close()
+			t.Fatalf("failed to scan: %v", err)
+		}
+		got = append(got, [4]any{id, zip, signup, note})
+	}
+	rows.Close()
+
+	if len(got) != 2 {
+		t.Fatalf("got %d rows, want 2", len(got))
+	}
+	if got[0][0] != "001" || got[0][1] != "02134" {
+		t.Errorf("leading zeros not preserved: %v", got[0])
+	}
+	if got[0][2] != "2024-01-31" {
+		t.Errorf("date format not applied: %v", got[0][2])
+	}
+	if got[0][3].(*string) != nil {
+		t.Errorf("expected NA to load as NULL, got %v", *got[0][3].(*string))
+	}
+	if n := got[1][3].(*string); n == nil || *n != "a;b" {
+		t.Errorf("quoted field not parsed: %v", n)
+	}
+
+	// Reloading with unchanged columns keeps the table and replaces its rows
+	if err := adapter.Exec(ctx, "CREATE VIEW raw.zip_view AS SELECT zip FROM raw.zips"); err != nil {
+		t.Fatalf("failed to create view: %v", err)
+	}
+	if err := adapter.LoadFile(ctx, "raw.zips", csvPath, opts); err != nil {
+		t.Fatalf("failed to reload CSV: %v", err)
+	}
+	rows, err = adapter.Query(ctx, "SELECT COUNT(*) FROM raw.zip_view")
+	if err != nil {
+		t.Fatalf("dependent view broken after reload: %v", err)
+	}
+	var count int
+	if rows.Next() {
+		rows.Scan(&count)
+	}
+	rows.Close()
+	if count != 2 {
+		t.Errorf("got %d rows after reload, want 2", count)
+	}
+}
+
+func TestDuckDBAdapter_LoadFile(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewDuckDBAdapter()
+
+	if err := adapter.Connect(ctx, Config{Path: ":memory:"}); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer adapter.Close()
+
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"rates.json":     `[{"code": "EUR", "rate": 1.1}, {"code": "GBP", "rate": 1.3}]`,
+		"events.ndjson":  "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n",
+		"readme.txt":     "not data",
+		"parts/keep.txt": "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Two Parquet files loaded through one glob, whose extension matches in any case
+	for i, name := range []string{"part1.parquet", "part2.PARQUET"} {
+		id := name[4:5]
+		path := filepath.Join(tmpDir, "parts", name)
+		query := "COPY (SELECT " + id + " AS id, 'p" + id + "' AS zip) TO " + quoteLiteral(path) + " (FORMAT PARQUET)"
+		if err := adapter.Exec(ctx, query); err != nil {
+			t.Fatalf("failed to write parquet file %d: %v", i, err)
+		}
+	}
+
+	cases := []struct {
+		table string
+		path  string
+		opts  CSVOptions
+		count int
+	}{
+		{"rates", filepath.Join(tmpDir, "rates.json"), CSVOptions{}, 2},
+		{"raw.events", filepath.Join(tmpDir, "events.ndjson"), CSVOptions{}, 3},
+		{"parts", filepath.Join(tmpDir, "parts", "*.parquet"), CSVOptions{ColumnTypes: map[string]string{"id": "VARCHAR"}}, 2},
+	}
+	for _, tc := range cases {
+		if err := adapter.LoadFile(ctx, tc.table, tc.path, tc.opts); err != nil {
+			t.Fatalf("LoadFile(%s) failed: %v", tc.path, err)
+		}
+		meta, err := adapter.GetTableMetadata(ctx, tc.table)
+		if err != nil {
+			t.Fatalf("table %s not created: %v", tc.table, err)
+		}
+		if meta.RowCount != int64(tc.count) {
+			t.Errorf("%s has %d rows, want %d", tc.table, meta.RowCount, tc.count)
+		}
+	}
+
+	// Column type overrides apply to non-CSV formats too
+	meta, _ := adapter.GetTableMetadata(ctx, "parts")
+	if meta.Columns[0].Name != "id" || meta.Columns[0].Type != "VARCHAR" {
+		t.Errorf("column type override not applied: %+v", meta.Columns[0])
+	}
+
+	if err := adapter.LoadFile(ctx, "readme", filepath.Join(tmpDir, "readme.txt"), CSVOptions{}); err == nil {
+		t.Error("expected error for unsupported file format")
+	}
+}
+
+func TestDuckDBAdapter_LoadFile_Excel(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewDuckDBAdapter()
+
+	if err := adapter.Connect(ctx, Config{Path: ":memory:"}); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer adapter.Close()
+
+	if err := adapter.loadSpatial(ctx); err != nil {
+		t.Skipf("spatial extension not available: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rates.xlsx")
+	query := "COPY (SELECT 'EUR' AS code, 1.1 AS rate UNION ALL SELECT 'GBP', 1.3) TO " + quoteLiteral(path) + " WITH (FORMAT GDAL, DRIVER 'xlsx')"
+	if err := adapter.Exec(ctx, query); err != nil {
+		t.Fatalf("failed to write Excel file: %v", err)
+	}
+	if err := adapter.LoadFile(ctx, "rates", path, CSVOptions{}); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	meta, err := adapter.GetTableMetadata(ctx, "rates")
+	if err != nil {
+		t.Fatalf("table not created: %v", err)
+	}
+	if meta.RowCount != 2 {
+		t.Errorf("rates has %d rows, want 2", meta.RowCount)
+	}
+}
+
+func TestDuckDBAdapter_LoadFile_ExcelWithoutExtension(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewDuckDBAdapter()
+
+	if err := adapter.Connect(ctx, Config{Path: ":memory:"}); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer adapter.Close()
+
+	// An empty extension directory and an unreachable repository stand in
+	// for a machine without network access
+	settings := []string{
+		"SET extension_directory = " + quoteLiteral(t.TempDir()),
+		"SET custom_extension_repository = 'http://127.0.0.1:9'",
+	}
+	for _, s := range settings {
+		if err := adapter.Exec(ctx, s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+
+	err := adapter.LoadFile(ctx, "rates", filepath.Join(t.TempDir(), "rates.xlsx"), CSVOptions{})
+	if err == nil {
+		t.Skip("spatial extension is built in")
+	}
+	if !strings.Contains(err.Error(), "needs DuckDB's spatial extension") {
+		t.Errorf("unclear error: %v", err)
+	}
+}
+
+func TestFileReader(t *testing.T) {
+	cases := map[string]string{
+		"/s/a.csv":        "read_csv('/s/a.csv', auto_detect=true, header=true)",
+		"/s/a.PARQUET":    "read_parquet('/s/a.PARQUET', union_by_name=true)",
+		"/s/a.json":       "read_json('/s/a.json', format='auto')",
+		"/s/a.jsonl":      "read_json('/s/a.jsonl', format='newline_delimited')",
+		"/s/a.xlsx":       "st_read('/s/a.xlsx', open_options=['HEADERS=FORCE', 'FIELD_TYPES=AUTO'])",
+		"/s/d/*.parquet":  "read_parquet('/s/d/*.[pP][aA][rR][qQ][uU][eE][tT]', union_by_name=true)",
+		"/s/o'brien.json": "read_json('/s/o''brien.json', format='auto')",
+	}
+	for path, want := range cases {
+		got, err := fileReader(path, CSVOptions{})
+		if err != nil {
+			t.Errorf("fileReader(%q) error: %v", path, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("fileReader(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if _, err := fileReader("/s/d/*.xlsx", CSVOptions{}); err == nil {
+		t.Error("expected error for Excel glob")
+	}
+}
+
 func TestDuckDBAdapter_ExecWithoutConnect(t *testing.T) {
 	ctx := context.Background()
 	adapter := NewDuckDBAdapter()
@@ -392,3 +634,32 @@ func TestDuckDBAdapter_ComplexQuery(t *testing.T) {
 		t.Errorf("Bob total: got %.2f, want 200.00", results["Bob"])
 	}
 }
+
+func TestFloat64(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewDuckDBAdapter()
+
+	if err := adapter.Connect(ctx, Config{Path: ":memory:"}); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer adapter.Close()
+
+	rows, err := adapter.Query(ctx, "SELECT 12.50::DECIMAL(10, 2)")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer rows.Close()
+	var v any
+	if !rows.Next() || rows.Scan(&v) != nil {
+		t.Fatal("failed to read DECIMAL")
+	}
+
+	if f, ok := Float64(v); !ok || f != 12.5 {
+		t.Errorf("Float64(%T) = %v, %v; want 12.5, true", v, f, ok)
+	}
+	for _, v := range []any{nil, "12.5"} {
+		if _, ok := Float64(v); ok {
+			t.Errorf("Float64(%#v) should not be a number", v)
+		}
+	}
+}
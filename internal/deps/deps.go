@@ -0,0 +1,300 @@
+// Package deps implements git-based package management for macros and models.
+// Packages are declared in packages.yaml, resolved from semver git tags,
+// vendored into _vendor/<name> and pinned in packages.lock.
+package deps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default file and directory names, relative to the project root.
+const (
+	DefaultManifestFile = "packages.yaml"
+	DefaultLockFile     = "packages.lock"
+	DefaultVendorDir    = "_vendor"
+)
+
+// lockHeader is written at the top of the lockfile.
+const lockHeader = "# Generated by leapsql deps install. Do not edit.\n"
+
+// packageNamePattern matches valid package names. The name becomes the macro
+// namespace and the schema of the package's models, so it must be an identifier.
+var packageNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Package is a package declared in packages.yaml.
+type Package struct {
+	// Name is the namespace the package is loaded under
+	Name string `yaml:"name"`
+	// Git is the repository URL (any URL or path git can clone)
+	Git string `yaml:"git"`
+	// Version is a version constraint resolved against the repository's tags
+	Version string `yaml:"version"`
+}
+
+// Manifest is the content of packages.yaml.
+type Manifest struct {
+	Packages []Package `yaml:"packages"`
+}
+
+// LockedPackage pins a package to an exact tag and commit.
+type LockedPackage struct {
+	Name    string `yaml:"name"`
+	Git     string `yaml:"git"`
+	Version string `yaml:"version"`
+	Commit  string `yaml:"commit"`
+}
+
+// Lock is the content of packages.lock.
+type Lock struct {
+	Packages []LockedPackage `yaml:"packages"`
+}
+
+// Get returns the locked entry for a package, or nil.
+func (l *Lock) Get(name string) *LockedPackage {
+	for i := range l.Packages {
+		if l.Packages[i].Name == name {
+			return &l.Packages[i]
+		}
+	}
+	return nil
+}
+
+// LoadManifest reads and validates packages.yaml.
+func LoadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var manifest Manifest
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: invalid packages file: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(manifest.Packages))
+	for i, pkg := range manifest.Packages {
+		switch {
+		case pkg.Name == "":
+			return nil, fmt.Errorf("%s: packages[%d]: name is required", path, i)
+		case !packageNamePattern.MatchString(pkg.Name):
+			return nil, fmt.Errorf("%s: package name %q must be a valid identifier", path, pkg.Name)
+		case seen[pkg.Name]:
+			return nil, fmt.Errorf("%s: duplicate package %q", path, pkg.Name)
+		case pkg.Git == "":
+			return nil, fmt.Errorf("%s: package %q: git is required", path, pkg.Name)
+		}
+		if _, err := ParseConstraint(pkg.Version); err != nil {
+			return nil, fmt.Errorf("%s: package %q: %w", path, pkg.Name, err)
+		}
+		seen[pkg.Name] = true
+	}
+
+	return &manifest, nil
+}
+
+// LoadLock reads packages.lock. A missing lockfile yields an empty lock.
+func LoadLock(path string) (*Lock, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Lock{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid lockfile: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// Save writes the lockfile with packages sorted by name.
+func (l *Lock) Save(path string) error {
+	sort.Slice(l.Packages, func(i, j int) bool { return l.Packages[i].Name < l.Packages[j].Name })
+
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, append([]byte(lockHeader), content...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Installer resolves, vendors and locks packages.
+type Installer struct {
+	// ManifestPath is the path to packages.yaml
+	ManifestPath string
+	// LockPath is the path to packages.lock
+	LockPath string
+	// VendorDir is the directory packages are vendored into
+	VendorDir string
+}
+
+// NewInstaller creates an installer for a project directory using the
+// default file names.
+func NewInstaller(projectDir string) *Installer {
+	return &Installer{
+		ManifestPath: filepath.Join(projectDir, DefaultManifestFile),
+		LockPath:     filepath.Join(projectDir, DefaultLockFile),
+		VendorDir:    filepath.Join(projectDir, DefaultVendorDir),
+	}
+}
+
+// Install vendors every package in the manifest and writes the lockfile.
+// A locked version is reused as long as it still satisfies the manifest;
+// otherwise the highest matching tag is selected. Vendored packages that are
+// no longer declared are removed.
+func (i *Installer) Install(ctx context.Context) (*Lock, error) {
+	manifest, err := LoadManifest(i.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := LoadLock(i.LockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(i.VendorDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create vendor directory: %w", err)
+	}
+
+	lock := &Lock{}
+	for _, pkg := range manifest.Packages {
+		locked, err := i.resolve(ctx, pkg, prev.Get(pkg.Name))
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", pkg.Name, err)
+		}
+		if err := i.vendor(ctx, locked); err != nil {
+			return nil, fmt.Errorf("package %s: %w", pkg.Name, err)
+		}
+		lock.Packages = append(lock.Packages, *locked)
+	}
+
+	if err := i.prune(manifest); err != nil {
+		return nil, err
+	}
+
+	if err := lock.Save(i.LockPath); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// resolve picks the tag to install for a package.
+func (i *Installer) resolve(ctx context.Context, pkg Package, locked *LockedPackage) (*LockedPackage, error) {
+	constraint, err := ParseConstraint(pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if locked != nil && locked.Git == pkg.Git {
+		if v, err := ParseVersion(locked.Version); err == nil && constraint.Check(v) {
+			return &LockedPackage{Name: pkg.Name, Git: pkg.Git, Version: locked.Version, Commit: locked.Commit}, nil
+		}
+	}
+
+	tags, err := listTags(ctx, pkg.Git)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Version
+	var commit string
+	for _, tag := range tags {
+		v, err := ParseVersion(tag.Name)
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+		if best == nil || v.Compare(*best) > 0 {
+			best = &v
+			commit = tag.Commit
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no tag in %s matches version %q", pkg.Git, pkg.Version)
+	}
+
+	return &LockedPackage{Name: pkg.Name, Git: pkg.Git, Version: best.Tag, Commit: commit}, nil
+}
+
+// vendor clones a locked package into the vendor directory, replacing any
+// previous copy, and verifies the tag still points at the locked commit.
+func (i *Installer) vendor(ctx context.Context, pkg *LockedPackage) error {
+	dest := filepath.Join(i.VendorDir, pkg.Name)
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	commit, err := cloneTag(ctx, pkg.Git, pkg.Version, tmp)
+	if err != nil {
+		return err
+	}
+	if commit != pkg.Commit {
+		return fmt.Errorf("tag %s now points to %s but packages.lock pins %s", pkg.Version, commit, pkg.Commit)
+	}
+
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// prune removes vendored packages that are not in the manifest.
+func (i *Installer) prune(manifest *Manifest) error {
+	declared := make(map[string]bool, len(manifest.Packages))
+	for _, pkg := range manifest.Packages {
+		declared[pkg.Name] = true
+	}
+
+	entries, err := os.ReadDir(i.VendorDir)
+	if err != nil {
+		return fmt.Errorf("failed to read vendor directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !declared[entry.Name()] {
+			if err := os.RemoveAll(filepath.Join(i.VendorDir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Installed lists the packages vendored in a directory, sorted by name.
+// A missing directory yields no packages.
+func Installed(vendorDir string) ([]string, error) {
+	entries, err := os.ReadDir(vendorDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read vendor directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && packageNamePattern.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,223 @@
+package deps
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitCmd runs git in dir with a fixed identity, failing the test on error.
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "init.defaultBranch=main"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newBareRepo creates a bare git repository with one commit per tag. Each
+// release writes macros/strings.star returning its version. Tags starting
+// with "a:" are annotated.
+func newBareRepo(t *testing.T, tags ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	if err := os.MkdirAll(filepath.Join(work, "macros"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, work, "init", "--quiet")
+
+	for _, tag := range tags {
+		name, annotated := strings.CutPrefix(tag, "a:")
+		macro := "def version():\n    return \"" + name + "\"\n"
+		if err := os.WriteFile(filepath.Join(work, "macros", "strings.star"), []byte(macro), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitCmd(t, work, "add", "-A")
+		gitCmd(t, work, "commit", "--quiet", "-m", name)
+		if annotated {
+			gitCmd(t, work, "tag", "-a", name, "-m", name)
+		} else {
+			gitCmd(t, work, "tag", name)
+		}
+	}
+
+	bare := filepath.Join(root, "pkg.git")
+	gitCmd(t, root, "clone", "--quiet", "--bare", work, bare)
+	return bare
+}
+
+// writeManifest writes packages.yaml into dir.
+func writeManifest(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, DefaultManifestFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstaller_Install(t *testing.T) {
+	repo := newBareRepo(t, "v1.0.0", "a:v1.1.0", "v1.2.0-rc.1", "v2.0.0")
+	project := t.TempDir()
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: "+repo+"\n    version: \"^1.0.0\"\n")
+
+	installer := NewInstaller(project)
+	lock, err := installer.Install(context.Background())
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+
+	// Highest matching release wins; annotated tags resolve to their commit
+	locked := lock.Get("utils")
+	if locked == nil || locked.Version != "v1.1.0" {
+		t.Fatalf("locked = %+v, want v1.1.0", locked)
+	}
+	if want := gitCmd(t, repo, "rev-parse", "v1.1.0^{commit}"); locked.Commit != want {
+		t.Errorf("commit = %s, want %s", locked.Commit, want)
+	}
+
+	vendored := filepath.Join(project, DefaultVendorDir, "utils")
+	content, err := os.ReadFile(filepath.Join(vendored, "macros", "strings.star"))
+	if err != nil || !strings.Contains(string(content), "v1.1.0") {
+		t.Errorf("vendored macros = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(vendored, ".git")); !os.IsNotExist(err) {
+		t.Error("vendored package should not contain .git")
+	}
+
+	saved, err := LoadLock(installer.LockPath)
+	if err != nil {
+		t.Fatalf("LoadLock() failed: %v", err)
+	}
+	if got := saved.Get("utils"); got == nil || *got != *locked {
+		t.Errorf("saved lock = %+v, want %+v", got, locked)
+	}
+}
+
+func TestInstaller_Install_RespectsLock(t *testing.T) {
+	repo := newBareRepo(t, "v1.0.0", "v1.1.0")
+	project := t.TempDir()
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: "+repo+"\n    version: \"^1.0.0\"\n")
+
+	installer := NewInstaller(project)
+	lock := &Lock{Packages: []LockedPackage{{
+		Name:    "utils",
+		Git:     repo,
+		Version: "v1.0.0",
+		Commit:  gitCmd(t, repo, "rev-parse", "v1.0.0"),
+	}}}
+	if err := lock.Save(installer.LockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := installer.Install(context.Background())
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if v := got.Get("utils").Version; v != "v1.0.0" {
+		t.Errorf("locked version %s was not kept, got %s", "v1.0.0", v)
+	}
+
+	// A constraint the lock no longer satisfies re-resolves
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: "+repo+"\n    version: \"~1.1.0\"\n")
+	got, err = installer.Install(context.Background())
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if v := got.Get("utils").Version; v != "v1.1.0" {
+		t.Errorf("version = %s, want v1.1.0", v)
+	}
+
+	// A moved tag is detected
+	lock = &Lock{Packages: []LockedPackage{{Name: "utils", Git: repo, Version: "v1.1.0", Commit: "0000"}}}
+	if err := lock.Save(installer.LockPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installer.Install(context.Background()); err == nil || !strings.Contains(err.Error(), "packages.lock pins") {
+		t.Errorf("expected moved tag error, got %v", err)
+	}
+}
+
+func TestInstaller_Install_NoMatchAndPrune(t *testing.T) {
+	repo := newBareRepo(t, "v1.0.0")
+	project := t.TempDir()
+	installer := NewInstaller(project)
+
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: "+repo+"\n    version: \"^2.0.0\"\n")
+	if _, err := installer.Install(context.Background()); err == nil || !strings.Contains(err.Error(), "no tag") {
+		t.Errorf("expected no matching tag error, got %v", err)
+	}
+
+	stale := filepath.Join(installer.VendorDir, "old_pkg")
+	if err := os.MkdirAll(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: "+repo+"\n")
+	if _, err := installer.Install(context.Background()); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("undeclared vendored package should be removed")
+	}
+
+	installed, err := Installed(installer.VendorDir)
+	if err != nil || len(installed) != 1 || installed[0] != "utils" {
+		t.Errorf("Installed() = %v, %v; want [utils]", installed, err)
+	}
+}
+
+func TestInstaller_Install_OptionLikeURL(t *testing.T) {
+	newBareRepo(t)
+	project := t.TempDir()
+	marker := filepath.Join(project, "injected")
+	writeManifest(t, project, "packages:\n  - name: utils\n    git: \"--upload-pack=touch "+marker+"\"\n")
+
+	_, err := NewInstaller(project).Install(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for a URL starting with -")
+	}
+	if !strings.Contains(err.Error(), "git ls-remote: ") {
+		t.Errorf("error should name the git subcommand: %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("the URL was read as a git option")
+	}
+}
+
+func TestSubcommand(t *testing.T) {
+	args := []string{"-c", "advice.detachedHead=false", "clone", "--quiet", "--", "url", "dest"}
+	if got := subcommand(args); got != "clone" {
+		t.Errorf("subcommand() = %q, want clone", got)
+	}
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"missing name":  "packages:\n  - git: x\n",
+		"bad name":      "packages:\n  - name: my-pkg\n    git: x\n",
+		"missing git":   "packages:\n  - name: utils\n",
+		"duplicate":     "packages:\n  - name: a\n    git: x\n  - name: a\n    git: y\n",
+		"bad version":   "packages:\n  - name: a\n    git: x\n    version: main\n",
+		"unknown field": "packages:\n  - name: a\n    git: x\n    tag: v1\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeManifest(t, dir, content)
+			if _, err := LoadManifest(filepath.Join(dir, DefaultManifestFile)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
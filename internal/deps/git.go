@@ -0,0 +1,93 @@
+package deps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// remoteTag is a tag advertised by a git remote.
+type remoteTag struct {
+	Name   string
+	Commit string
+}
+
+// runGit runs a git command and returns its standard output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", subcommand(args), msg)
+	}
+	return stdout.String(), nil
+}
+
+// subcommand returns the git subcommand in args, after global options such
+// as -c name=value.
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// listTags lists the tags of a remote repository with the commits they point
+// to. Annotated tags are resolved to their commit.
+func listTags(ctx context.Context, url string) ([]remoteTag, error) {
+	out, err := runGit(ctx, "", "ls-remote", "--tags", "--", url)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string]string)
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		sha, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		if peeled, isPeeled := strings.CutSuffix(name, "^{}"); isPeeled {
+			// The peeled entry is the commit an annotated tag points to
+			commits[peeled] = sha
+			continue
+		}
+		if _, seen := commits[name]; !seen {
+			names = append(names, name)
+			commits[name] = sha
+		}
+	}
+
+	tags := make([]remoteTag, len(names))
+	for i, name := range names {
+		tags[i] = remoteTag{Name: name, Commit: commits[name]}
+	}
+	return tags, nil
+}
+
+// cloneTag makes a shallow clone of a single tag into dest and returns the
+// checked out commit.
+func cloneTag(ctx context.Context, url, tag, dest string) (string, error) {
+	if _, err := runGit(ctx, "", "-c", "advice.detachedHead=false", "clone", "--quiet", "--depth", "1", "--branch", tag, "--", url, dest); err != nil {
+		return "", err
+	}
+	out, err := runGit(ctx, dest, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,165 @@
+package deps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version parsed from a git tag such as "v1.2.3".
+type Version struct {
+	Major, Minor, Patch int
+	// Prerelease is the part after "-", e.g. "rc.1" (empty for releases)
+	Prerelease string
+	// Tag is the original tag name
+	Tag string
+}
+
+// ParseVersion parses a semantic version with an optional "v" prefix.
+// Missing minor and patch components default to zero ("v1" is "v1.0.0").
+func ParseVersion(tag string) (Version, error) {
+	v := Version{Tag: tag}
+	s := strings.TrimPrefix(tag, "v")
+	s, _, _ = strings.Cut(s, "+") // build metadata is ignored
+	s, v.Prerelease, _ = strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return Version{}, fmt.Errorf("invalid version %q", tag)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", tag)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or higher than o.
+// A prerelease is lower than the release with the same number.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	case v.Prerelease < o.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Constraint restricts the versions a package may resolve to.
+type Constraint struct {
+	raw    string
+	checks []versionCheck
+}
+
+// versionCheck compares a version with a bound using an operator.
+type versionCheck struct {
+	op    string
+	bound Version
+}
+
+// ParseConstraint parses a version constraint. Supported forms:
+//
+//	""  "*"  "latest"     any release
+//	"v1.2.3"  "=1.2.3"   exactly that version
+//	"^1.2.3"             compatible: >=1.2.3 <2.0.0 (>=0.2.3 <0.3.0 below 1.0)
+//	"~1.2.3"             patch updates: >=1.2.3 <1.3.0
+//	">=1.0.0, <2.0.0"    comparisons (>, >=, <, <=), all must hold
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" || s == "latest" {
+		return c, nil
+	}
+
+	for _, term := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		op := ""
+		for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(term, prefix) {
+				op = prefix
+				break
+			}
+		}
+
+		v, err := ParseVersion(strings.TrimPrefix(term, op))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+
+		switch op {
+		case "", "=":
+			c.checks = append(c.checks, versionCheck{"=", v})
+		case "^":
+			upper := Version{Major: v.Major + 1}
+			if v.Major == 0 {
+				upper = Version{Minor: v.Minor + 1}
+			}
+			c.checks = append(c.checks, versionCheck{">=", v}, versionCheck{"<", upper})
+		case "~":
+			c.checks = append(c.checks, versionCheck{">=", v}, versionCheck{"<", Version{Major: v.Major, Minor: v.Minor + 1}})
+		default:
+			c.checks = append(c.checks, versionCheck{op, v})
+		}
+	}
+
+	return c, nil
+}
+
+// Check reports whether a version satisfies the constraint. Prereleases only
+// match constraints that name them exactly.
+func (c *Constraint) Check(v Version) bool {
+	if v.Prerelease != "" {
+		return len(c.checks) == 1 && c.checks[0].op == "=" && v.Compare(c.checks[0].bound) == 0
+	}
+
+	for _, check := range c.checks {
+		cmp := v.Compare(check.bound)
+		var ok bool
+		switch check.op {
+		case "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,80 @@
+package deps
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]Version{
+		"v1.2.3":       {Major: 1, Minor: 2, Patch: 3},
+		"1.2.3":        {Major: 1, Minor: 2, Patch: 3},
+		"v2":           {Major: 2},
+		"v1.0.0-rc.1":  {Major: 1, Prerelease: "rc.1"},
+		"v1.0.0+build": {Major: 1},
+	}
+	for tag, want := range cases {
+		got, err := ParseVersion(tag)
+		if err != nil {
+			t.Errorf("ParseVersion(%q) error: %v", tag, err)
+			continue
+		}
+		want.Tag = tag
+		if got != want {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tag, got, want)
+		}
+	}
+
+	for _, tag := range []string{"", "main", "v1.2.3.4", "v1.x", "release-1"} {
+		if _, err := ParseVersion(tag); err == nil {
+			t.Errorf("ParseVersion(%q) should fail", tag)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{"v0.9.0", "v1.0.0-alpha", "v1.0.0-beta", "v1.0.0", "v1.0.1", "v1.2.0", "v10.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i-1])
+		b, _ := ParseVersion(ordered[i])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+}
+
+func TestConstraint_Check(t *testing.T) {
+	cases := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"", []string{"v0.1.0", "v3.0.0"}, []string{"v1.0.0-rc.1"}},
+		{"v1.0.0", []string{"v1.0.0"}, []string{"v1.0.1"}},
+		{"^1.2.0", []string{"v1.2.0", "v1.9.9"}, []string{"v1.1.9", "v2.0.0"}},
+		{"^0.2.1", []string{"v0.2.5"}, []string{"v0.3.0"}},
+		{"~1.2.0", []string{"v1.2.9"}, []string{"v1.3.0"}},
+		{">=1.0.0, <2.0.0", []string{"v1.5.0"}, []string{"v2.0.0", "v0.9.0"}},
+		{"v1.0.0-rc.1", []string{"v1.0.0-rc.1"}, []string{"v1.0.0"}},
+	}
+
+	for _, tc := range cases {
+		c, err := ParseConstraint(tc.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) error: %v", tc.constraint, err)
+		}
+		for _, tag := range tc.matches {
+			v, _ := ParseVersion(tag)
+			if !c.Check(v) {
+				t.Errorf("%q should match %s", tc.constraint, tag)
+			}
+		}
+		for _, tag := range tc.rejects {
+			v, _ := ParseVersion(tag)
+			if c.Check(v) {
+				t.Errorf("%q should not match %s", tc.constraint, tag)
+			}
+		}
+	}
+
+	if _, err := ParseConstraint("^main"); err == nil {
+		t.Error("expected error for invalid constraint")
+	}
+}
@@ -29,11 +29,14 @@ type SourceRef struct {
 
 // ColumnDoc represents column lineage information for documentation.
 type ColumnDoc struct {
-	Name          string      `json:"name"`
-	Index         int         `json:"index"`
-	TransformType string      `json:"transform_type,omitempty"` // "" (direct) or "EXPR"
-	Function      string      `json:"function,omitempty"`       // "sum", "count", etc.
-	Sources       []SourceRef `json:"sources"`                  // where this column comes from
+	Name          string         `json:"name"`
+	Index         int            `json:"index"`
+	TransformType string         `json:"transform_type,omitempty"` // "" (direct) or "EXPR"
+	Function      string         `json:"function,omitempty"`       // "sum", "count", etc.
+	Sources       []SourceRef    `json:"sources"`                  // where this column comes from
+	Description   string         `json:"description,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Meta          map[string]any `json:"meta,omitempty"`
 }
 
 // ModelDoc represents a model for documentation purposes.
@@ -50,6 +53,7 @@ type ModelDoc struct {
 	Dependents   []string    `json:"dependents"`
 	Columns      []ColumnDoc `json:"columns"`
 	Description  string      `json:"description,omitempty"`
+	Warnings     []string    `json:"warnings,omitempty"`
 	UpdatedAt    time.Time   `json:"updated_at"`
 }
 
@@ -85,9 +89,14 @@ type ColumnLineageDoc struct {
 }
 
 // SourceDoc represents an external data source (not a model).
+// Seeds are listed as sources with Seed set, and files read with table
+// functions like read_parquet with File set.
 type SourceDoc struct {
 	Name         string   `json:"name"`
 	ReferencedBy []string `json:"referenced_by"` // models that use this source
+	Seed         bool     `json:"seed,omitempty"`
+	File         bool     `json:"file,omitempty"`
+	FilePath     string   `json:"file_path,omitempty"` // seed CSV file or read file
 }
 
 // Catalog represents the full documentation catalog.
@@ -104,6 +113,7 @@ type Catalog struct {
 type Generator struct {
 	registry    *registry.ModelRegistry
 	models      []*parser.ModelConfig
+	seeds       []*parser.ModelConfig
 	projectName string
 }
 
@@ -133,6 +143,39 @@ func (g *Generator) LoadModels(modelsDir string) error {
 	return nil
 }
 
+// LoadSeeds loads seeds from a directory so they are marked as seeds in the
+// catalog's sources.
+func (g *Generator) LoadSeeds(seedsDir string) error {
+	seeds, err := parser.ScanSeeds(seedsDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan seeds: %w", err)
+	}
+	g.seeds = seeds
+	return nil
+}
+
+// findSeed returns the seed a source name refers to, if any.
+func (g *Generator) findSeed(name string) *parser.ModelConfig {
+	for _, s := range g.seeds {
+		if s.Path == name || s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// isFile reports whether a source is a file path read by some model.
+func (g *Generator) isFile(name string) bool {
+	for _, m := range g.models {
+		for _, f := range m.Files {
+			if f == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GenerateCatalog generates the documentation catalog.
 func (g *Generator) GenerateCatalog() *Catalog {
 	catalog := &Catalog{
@@ -167,11 +210,15 @@ func (g *Generator) GenerateCatalog() *Catalog {
 			Dependencies: deps,
 			Dependents:   []string{},
 			Columns:      convertColumns(model.Columns),
+			Warnings:     model.Warnings,
 			UpdatedAt:    time.Now().UTC(),
 		}
 
-		// Extract description from SQL comments
-		doc.Description = extractDescription(model.RawContent)
+		// Prefer the frontmatter description, fall back to SQL comments
+		doc.Description = model.Description
+		if doc.Description == "" {
+			doc.Description = extractDescription(model.RawContent)
+		}
 
 		modelDocs[model.Path] = doc
 		catalog.Models = append(catalog.Models, doc)
@@ -209,10 +256,18 @@ func (g *Generator) GenerateCatalog() *Catalog {
 
 	// Build Sources list
 	for srcName, refs := range sourceRefs {
-		catalog.Sources = append(catalog.Sources, SourceDoc{
+		src := SourceDoc{
 			Name:         srcName,
 			ReferencedBy: refs,
-		})
+		}
+		if seed := g.findSeed(srcName); seed != nil {
+			src.Seed = true
+			src.FilePath = seed.FilePath
+		} else if g.isFile(srcName) {
+			src.File = true
+			src.FilePath = srcName
+		}
+		catalog.Sources = append(catalog.Sources, src)
 	}
 
 	// Build lineage graph (now includes sources)
@@ -286,6 +341,9 @@ func convertColumns(columns []parser.ColumnInfo) []ColumnDoc {
 			TransformType: col.TransformType,
 			Function:      col.Function,
 			Sources:       sources,
+			Description:   col.Description,
+			Tags:          col.Tags,
+			Meta:          col.Meta,
 		})
 	}
 	return result
@@ -425,6 +425,7 @@ function renderModelPage(modelPath) {
           <thead>
             <tr>
               <th>Name</th>
+              <th>Description</th>
               <th>Transform</th>
               <th>Sources</th>
             </tr>
@@ -433,6 +434,14 @@ function renderModelPage(modelPath) {
             ${model.columns.map(col => `
               <tr>
                 <td><code class="column-name">${escapeHtml(col.name)}</code></td>
+                <td>
+                  ${col.description ? `<span class="column-description">${escapeHtml(col.description)}</span>` : '<span class="no-sources">-</span>'}
+                  ${col.tags && col.tags.length > 0 ? `
+                    <div class="dep-list">
+                      ${col.tags.map(tag => `<span class="dep-tag">${escapeHtml(tag)}</span>`).join('')}
+                    </div>
+                  ` : ''}
+                </td>
                 <td>
                   ${col.transform_type === 'EXPR' ? `
                     <span class="transform-badge expr">${col.function || 'expression'}</span>
@@ -504,10 +513,10 @@ function renderSourcePage(sourceName) {
   return `
     <div class="model-header">
       <div>
-        <div class="source-badge-header">SOURCE</div>
+        <div class="source-badge-header">${source.seed ? 'SEED' : source.file ? 'FILE' : 'SOURCE'}</div>
         <h1 class="model-title">${source.name}</h1>
         <p style="margin-top: 1rem; color: var(--text-secondary);">
-          External data source referenced by ${source.referenced_by.length} model${source.referenced_by.length !== 1 ? 's' : ''}.
+          ${source.seed ? 'Seed loaded from <code>' + escapeHtml(source.file_path) + '</code>,' : source.file ? 'File read with a table function,' : 'External data source'} referenced by ${source.referenced_by.length} model${source.referenced_by.length !== 1 ? 's' : ''}.
         </p>
       </div>
     </div>
@@ -18,8 +18,11 @@ import (
 	"github.com/leapstack-labs/leapsql/internal/parser"
 	"github.com/leapstack-labs/leapsql/internal/registry"
 	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
 	"github.com/leapstack-labs/leapsql/internal/state"
 	"github.com/leapstack-labs/leapsql/internal/template"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+	"go.starlark.net/starlark"
 )
 
 // Engine orchestrates the execution of SQL models.
@@ -29,10 +32,20 @@ type Engine struct {
 	modelsDir     string
 	seedsDir      string
 	macrosDir     string
+	testsDir      string
+	vendorDir     string
 	environment   string
 	target        *starctx.TargetInfo
+	dialect       *lineage.Dialect
+	clock         stdlib.Clock
+	introspection *stdlib.Introspection
+	vars          map[string]any
+	starlarkVars  starlark.StringDict
+	onRunStart    []string
+	onRunEnd      []string
 	graph         *dag.Graph
 	models        map[string]*parser.ModelConfig
+	seeds         map[string]*parser.ModelConfig
 	registry      *registry.ModelRegistry
 	macroRegistry *macro.Registry
 }
@@ -45,14 +58,27 @@ type Config struct {
 	SeedsDir string
 	// MacrosDir is the path to the macros directory (optional)
 	MacrosDir string
+	// TestsDir is the path to the singular tests directory (optional)
+	TestsDir string
+	// VendorDir is the directory of installed packages (optional, see leapsql deps)
+	VendorDir string
 	// DatabasePath is the path to the DuckDB database (empty for in-memory)
 	DatabasePath string
 	// StatePath is the path to the SQLite state database
 	StatePath string
 	// Environment is the current environment (dev, staging, prod)
 	Environment string
-	// Target contains adapter/database configuration
+	// Target contains adapter/database configuration. Its type selects the
+	// SQL dialect models are parsed with (duckdb, postgres, snowflake, bigquery).
 	Target *starctx.TargetInfo
+	// Clock is the time source for datetime.now() in templates (default time.Now)
+	Clock stdlib.Clock
+	// Vars are the project variables read by var() and recorded on each run
+	Vars map[string]any
+	// OnRunStart are templated SQL hooks run at the start of each run
+	OnRunStart []string
+	// OnRunEnd are templated SQL hooks run at the end of each run
+	OnRunEnd []string
 }
 
 // New creates a new engine with the given configuration.
@@ -82,7 +108,7 @@ func New(cfg Config) (*Engine, error) {
 	var macroRegistry *macro.Registry
 	if cfg.MacrosDir != "" {
 		var err error
-		macroRegistry, err = macro.LoadAndRegister(cfg.MacrosDir)
+		macroRegistry, err = macro.LoadAndRegister(cfg.MacrosDir, macro.WithVendorDir(cfg.VendorDir))
 		if err != nil {
 			// Log warning but don't fail - macros are optional
 			// In a real implementation, we might want to check if directory exists first
@@ -97,6 +123,15 @@ func New(cfg Config) (*Engine, error) {
 		macroRegistry = macro.NewRegistry()
 	}
 
+	// Vendored packages add their macros under the package namespace
+	if cfg.VendorDir != "" {
+		if err := loadPackageMacros(macroRegistry, cfg.VendorDir); err != nil {
+			db.Close()
+			store.Close()
+			return nil, err
+		}
+	}
+
 	// Set default environment
 	env := cfg.Environment
 	if env == "" {
@@ -113,16 +148,44 @@ func New(cfg Config) (*Engine, error) {
 		}
 	}
 
+	dialect, ok := lineage.GetDialect(target.Type)
+	if !ok {
+		db.Close()
+		store.Close()
+		return nil, fmt.Errorf("unknown target type %q (supported: %s)", target.Type, strings.Join(lineage.ListDialects(), ", "))
+	}
+
+	clock := cfg.Clock
+	if clock == nil {
+		clock = stdlib.DefaultClock
+	}
+
+	starlarkVars, err := starctx.VarsToStarlark(cfg.Vars)
+	if err != nil {
+		db.Close()
+		store.Close()
+		return nil, fmt.Errorf("invalid vars: %w", err)
+	}
+
 	return &Engine{
 		db:            db,
 		store:         store,
 		modelsDir:     cfg.ModelsDir,
 		seedsDir:      cfg.SeedsDir,
 		macrosDir:     cfg.MacrosDir,
+		testsDir:      cfg.TestsDir,
+		vendorDir:     cfg.VendorDir,
 		environment:   env,
 		target:        target,
+		dialect:       dialect,
+		clock:         clock,
+		vars:          cfg.Vars,
+		starlarkVars:  starlarkVars,
+		onRunStart:    cfg.OnRunStart,
+		onRunEnd:      cfg.OnRunEnd,
 		graph:         dag.NewGraph(),
 		models:        make(map[string]*parser.ModelConfig),
+		seeds:         make(map[string]*parser.ModelConfig),
 		registry:      registry.NewModelRegistry(),
 		macroRegistry: macroRegistry,
 	}, nil
@@ -147,57 +210,166 @@ func (e *Engine) Close() error {
 	return nil
 }
 
-// LoadSeeds loads all CSV files from the seeds directory into the database.
+// LoadSeeds loads all seed files (CSV, Parquet, JSON, NDJSON, Excel) from the
+// seeds directory into the database. Each seed may have a YAML config next to
+// it (raw_customers.yml) controlling its target schema, column types and CSV
+// parsing options. Seeds whose files and config are unchanged since the last
+// load are skipped.
 func (e *Engine) LoadSeeds(ctx context.Context) error {
 	if e.seedsDir == "" {
 		return nil
 	}
 
-	entries, err := os.ReadDir(e.seedsDir)
+	seeds, err := parser.ScanSeeds(e.seedsDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No seeds directory is OK
+		return err
+	}
+
+	for _, s := range seeds {
+		if _, err := e.loadSeed(ctx, s); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to read seeds directory: %w", err)
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
-			continue
+	return nil
+}
+
+// loadSeed loads a single seed unless its content hash matches the last load
+// and its table still exists. It reports whether the table was (re)loaded.
+func (e *Engine) loadSeed(ctx context.Context, s *parser.ModelConfig) (bool, error) {
+	hash, err := seedHash(s.FilePath)
+	if err != nil {
+		return false, err
+	}
+
+	prev, err := e.store.GetSeed(s.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to get seed %s: %w", s.Path, err)
+	}
+	if prev != nil && prev.ContentHash == hash {
+		if _, err := e.db.GetTableMetadata(ctx, pathToTableName(s.Path)); err == nil {
+			return false, nil
 		}
+	}
+
+	if err := e.db.LoadFile(ctx, pathToTableName(s.Path), s.FilePath, seedCSVOptions(s.Seed)); err != nil {
+		return false, fmt.Errorf("failed to load seed %s: %w", s.Path, err)
+	}
+
+	if err := e.store.RegisterSeed(&state.Seed{
+		Path:        s.Path,
+		FilePath:    s.FilePath,
+		ContentHash: hash,
+	}); err != nil {
+		return true, fmt.Errorf("failed to register seed %s: %w", s.Path, err)
+	}
+
+	return true, nil
+}
 
-		tableName := strings.TrimSuffix(entry.Name(), ".csv")
-		csvPath := filepath.Join(e.seedsDir, entry.Name())
+// seedHash hashes a seed's data files together with its config file, if any,
+// so a change to either triggers a reload. Multi-file seeds hash every file
+// matching their glob.
+func seedHash(seedPath string) (string, error) {
+	files, err := filepath.Glob(seedPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid seed path %s: %w", seedPath, err)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no seed files match %s", seedPath)
+	}
+	if cfgPath := parser.SeedConfigPath(seedPath); cfgPath != "" {
+		files = append(files, cfgPath)
+	}
 
-		if err := e.db.LoadCSV(ctx, tableName, csvPath); err != nil {
-			return fmt.Errorf("failed to load seed %s: %w", entry.Name(), err)
+	var content []byte
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read seed %s: %w", file, err)
 		}
+		content = append(content, filepath.Base(file)...)
+		content = append(content, 0)
+		content = append(content, data...)
 	}
+	return hashContent(string(content)), nil
+}
 
-	return nil
+// seedCSVOptions converts a seed config into adapter CSV options.
+func seedCSVOptions(cfg *parser.SeedConfig) adapter.CSVOptions {
+	return adapter.CSVOptions{
+		Delimiter:       cfg.Delimiter,
+		Quote:           cfg.Quote,
+		Header:          cfg.Header,
+		NullStrings:     cfg.NullStrings,
+		DateFormat:      cfg.DateFormat,
+		TimestampFormat: cfg.TimestampFormat,
+		ColumnTypes:     cfg.ColumnTypes,
+		FullRefresh:     cfg.FullRefresh,
+	}
 }
 
 // Discover scans the models directory and builds the dependency graph.
 // It uses the registry to resolve auto-detected table sources to model dependencies.
+// Seeds are registered as graph nodes too, so models that read a seed depend on it.
 func (e *Engine) Discover() error {
-	scanner := parser.NewScanner(e.modelsDir)
+	scanner := parser.NewScannerWithDialect(e.modelsDir, e.dialect)
 	models, err := scanner.ScanDir(e.modelsDir)
 	if err != nil {
 		return fmt.Errorf("failed to scan models: %w", err)
 	}
 
+	if e.vendorDir != "" {
+		pkgModels, err := scanPackageModels(e.vendorDir, e.dialect)
+		if err != nil {
+			return err
+		}
+		projectPaths := make(map[string]string, len(models))
+		for _, m := range models {
+			projectPaths[m.Path] = m.FilePath
+		}
+		for _, m := range pkgModels {
+			if other, ok := projectPaths[m.Path]; ok {
+				return fmt.Errorf("model %s from package %s conflicts with %s", m.Path, m.Package, other)
+			}
+		}
+		models = append(models, pkgModels...)
+	}
+
+	var seeds []*parser.ModelConfig
+	if e.seedsDir != "" {
+		seeds, err = parser.ScanSeeds(e.seedsDir)
+		if err != nil {
+			return fmt.Errorf("failed to scan seeds: %w", err)
+		}
+	}
+
 	// Clear existing state
 	e.graph = dag.NewGraph()
 	e.models = make(map[string]*parser.ModelConfig)
+	e.seeds = make(map[string]*parser.ModelConfig)
 	e.registry = registry.NewModelRegistry()
 
-	// Phase 1: Register all models in the registry
+	// Phase 1: Register all seeds and models in the registry.
+	// Seeds go first so a model with the same name takes precedence.
+	for _, s := range seeds {
+		e.registry.Register(s)
+		e.seeds[s.Path] = s
+	}
 	for _, m := range models {
 		e.registry.Register(m)
 		e.models[m.Path] = m
 	}
 
-	// Phase 2: Add all models as nodes in the graph
+	// Capture relation() calls, including names built dynamically in Starlark
+	for _, m := range models {
+		e.captureRelations(m)
+	}
+
+	// Phase 2: Add all seeds and models as nodes in the graph
+	for _, s := range seeds {
+		e.graph.AddNode(s.Path, s)
+	}
 	for _, m := range models {
 		e.graph.AddNode(m.Path, m)
 	}
@@ -212,6 +384,11 @@ func (e *Engine) Discover() error {
 			tableSources = m.Imports
 		}
 
+		// Hooks run with the model, so relations they read come first too
+		if len(m.HookSources) > 0 {
+			tableSources = append(append([]string{}, tableSources...), m.HookSources...)
+		}
+
 		// Resolve table names to model dependencies
 		dependencies, _ := e.registry.ResolveDependencies(tableSources)
 
@@ -242,6 +419,7 @@ func (e *Engine) Discover() error {
 			Materialized: m.Materialized,
 			UniqueKey:    m.UniqueKey,
 			ContentHash:  hashContent(m.RawContent),
+			Description:  m.Description,
 		}
 		if err := e.store.RegisterModel(model); err != nil {
 			return fmt.Errorf("failed to register model %s: %w", m.Path, err)
@@ -266,6 +444,7 @@ func (e *Engine) Discover() error {
 					TransformType: col.TransformType,
 					Function:      col.Function,
 					Sources:       sources,
+					Description:   col.Description,
 				})
 			}
 
@@ -282,8 +461,10 @@ func (e *Engine) Discover() error {
 
 // Run executes all models in topological order.
 func (e *Engine) Run(ctx context.Context, env string) (*state.Run, error) {
+	e.introspection = stdlib.NewIntrospection(ctx, e.db)
+
 	// Create a new run
-	run, err := e.store.CreateRun(env)
+	run, err := e.store.CreateRunWithVars(env, e.vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create run: %w", err)
 	}
@@ -295,11 +476,22 @@ func (e *Engine) Run(ctx context.Context, env string) (*state.Run, error) {
 		return run, err
 	}
 
-	// Execute each model
-	var runErr error
+	// Execute each model, after the on_run_start hooks
+	runErr := e.runProjectHooks(ctx, hookOnRunStart, e.onRunStart)
 	for _, node := range sorted {
+		if runErr != nil {
+			break
+		}
 		m := node.Data.(*parser.ModelConfig)
 
+		if m.Materialized == "seed" {
+			if _, err := e.loadSeed(ctx, m); err != nil {
+				runErr = err
+				break
+			}
+			continue
+		}
+
 		// Get model from state store
 		model, err := e.store.GetModelByPath(m.Path)
 		if err != nil {
@@ -332,11 +524,8 @@ func (e *Engine) Run(ctx context.Context, env string) (*state.Run, error) {
 		}
 
 		_ = executionMS // Note: execution time tracked but not stored in current schema
-
-		if runErr != nil {
-			break
-		}
 	}
+	runErr = e.finishRun(ctx, runErr)
 
 	// Complete the run
 	if runErr != nil {
@@ -351,8 +540,11 @@ func (e *Engine) Run(ctx context.Context, env string) (*state.Run, error) {
 }
 
 // RunSelected executes only the specified models and their downstream dependents.
-// Upstream dependencies must already exist in the database.
+// Upstream model dependencies must already exist in the database; upstream
+// seeds are loaded automatically if they changed or are missing.
 func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []string, includeDownstream bool) (*state.Run, error) {
+	e.introspection = stdlib.NewIntrospection(ctx, e.db)
+
 	var affected []string
 	if includeDownstream {
 		// Get affected nodes (selected + downstream)
@@ -362,11 +554,25 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		affected = modelPaths
 	}
 
+	// Include the seeds the affected models read from
+	selected := make(map[string]bool, len(affected))
+	for _, path := range affected {
+		selected[path] = true
+	}
+	for _, path := range append([]string(nil), affected...) {
+		for _, up := range e.graph.GetUpstreamNodes(path) {
+			if _, isSeed := e.seeds[up]; isSeed && !selected[up] {
+				selected[up] = true
+				affected = append(affected, up)
+			}
+		}
+	}
+
 	// Create subgraph with affected nodes
 	subgraph := e.graph.Subgraph(affected)
 
 	// Create a new run
-	run, err := e.store.CreateRun(env)
+	run, err := e.store.CreateRunWithVars(env, e.vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create run: %w", err)
 	}
@@ -378,8 +584,19 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		return run, err
 	}
 
-	var runErr error
+	runErr := e.runProjectHooks(ctx, hookOnRunStart, e.onRunStart)
 	for _, node := range sorted {
+		if runErr != nil {
+			break
+		}
+		if s := e.seeds[node.ID]; s != nil {
+			if _, err := e.loadSeed(ctx, s); err != nil {
+				runErr = err
+				break
+			}
+			continue
+		}
+
 		m := e.models[node.ID]
 		if m == nil {
 			continue
@@ -411,11 +628,8 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		} else {
 			e.store.UpdateModelRun(modelRun.ID, state.ModelRunStatusSuccess, rowsAffected, "")
 		}
-
-		if runErr != nil {
-			break
-		}
 	}
+	runErr = e.finishRun(ctx, runErr)
 
 	if runErr != nil {
 		e.store.CompleteRun(run.ID, state.RunStatusFailed, runErr.Error())
@@ -427,10 +641,36 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 	return run, runErr
 }
 
-// executeModel executes a single model and returns rows affected.
+// executeModel executes a single model and its pre/post hooks and returns
+// rows affected. A failing hook fails the model.
 func (e *Engine) executeModel(ctx context.Context, m *parser.ModelConfig, model *state.Model) (int64, error) {
-	sql := e.buildSQL(m, model)
+	sql, err := e.modelSQL(m, model, e.createExecutionContext(m))
+	if err != nil {
+		return 0, err
+	}
+
+	var hookCtx *starctx.ExecutionContext
+	if len(m.PreHooks) > 0 || len(m.PostHooks) > 0 {
+		hookCtx = e.createExecutionContext(m)
+	}
+
+	if err := e.runHooks(ctx, hookPre, m.PreHooks, m.FilePath, hookCtx); err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := e.materialize(ctx, m, model, sql)
+	if err != nil {
+		return 0, err
+	}
 
+	if err := e.runHooks(ctx, hookPost, m.PostHooks, m.FilePath, hookCtx); err != nil {
+		return rowsAffected, err
+	}
+	return rowsAffected, nil
+}
+
+// materialize builds a model's relation from its rendered SQL.
+func (e *Engine) materialize(ctx context.Context, m *parser.ModelConfig, model *state.Model, sql string) (int64, error) {
 	switch m.Materialized {
 	case "table":
 		return e.executeTable(ctx, m.Path, sql)
@@ -439,15 +679,39 @@ func (e *Engine) executeModel(ctx context.Context, m *parser.ModelConfig, model
 	case "incremental":
 		return e.executeIncremental(ctx, m, model, sql)
 	default:
+		return e.executeCustom(ctx, m, sql)
+	}
+}
+
+// executeCustom runs a materialization defined in Starlark, resolved through
+// the macro registry. The function is called as fn(sql, this, adapter) and
+// returns the number of rows affected, or None.
+func (e *Engine) executeCustom(ctx context.Context, m *parser.ModelConfig, sql string) (int64, error) {
+	fn, ok := e.macroRegistry.Materialization(m.Materialized)
+	if !ok {
 		return 0, fmt.Errorf("unknown materialization: %s", m.Materialized)
 	}
+
+	tctx := e.createExecutionContext(m)
+	args := starlark.Tuple{starlark.String(sql), tctx.This.ToStarlark(), stdlib.NewAdapterHandle(ctx, e.db)}
+	result, err := tctx.Call(m.FilePath, fn, args)
+	if err != nil {
+		return 0, fmt.Errorf("materialization %s failed: %w", m.Materialized, err)
+	}
+
+	switch v := result.(type) {
+	case starlark.NoneType:
+		return 0, nil
+	case starlark.Int:
+		if n, ok := v.Int64(); ok {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("materialization %s must return the number of rows affected, got %s", m.Materialized, result.Type())
 }
 
 // buildSQL prepares the SQL for execution using template rendering.
-func (e *Engine) buildSQL(m *parser.ModelConfig, model *state.Model) string {
-	// Create execution context for this model
-	ctx := e.createExecutionContext(m)
-
+func (e *Engine) buildSQL(m *parser.ModelConfig, model *state.Model, ctx *starctx.ExecutionContext) string {
 	// Render the template
 	rendered, err := template.RenderString(m.SQL, m.FilePath, ctx)
 	if err != nil {
@@ -459,6 +723,38 @@ func (e *Engine) buildSQL(m *parser.ModelConfig, model *state.Model) string {
 	return rendered
 }
 
+// RenderedModel is the SQL of a model after template rendering.
+type RenderedModel struct {
+	SQL       string
+	model     *parser.ModelConfig
+	sourceMap *template.SourceMap
+}
+
+// FilePosition maps a position in the rendered SQL, such as a lineage
+// ParseError or the span of an AST node, back to the line and column in the
+// model file.
+func (r *RenderedModel) FilePosition(pos lineage.Position) (int, int) {
+	src := r.sourceMap.Position(pos.Offset)
+	return r.model.FilePosition(src.Line, src.Column)
+}
+
+// RenderModel renders the template of a discovered SQL model.
+func (e *Engine) RenderModel(path string) (*RenderedModel, error) {
+	m, ok := e.models[path]
+	if !ok {
+		return nil, fmt.Errorf("model %s not found", path)
+	}
+	if m.Language == parser.LanguageStarlark {
+		return nil, fmt.Errorf("model %s is a Starlark model", path)
+	}
+
+	sql, sourceMap, err := template.RenderStringWithSourceMap(m.SQL, m.FilePath, e.createExecutionContext(m))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render model %s: %w", path, err)
+	}
+	return &RenderedModel{SQL: sql, model: m, sourceMap: sourceMap}, nil
+}
+
 // buildSQLLegacy provides backward compatibility with simple string replacement.
 func (e *Engine) buildSQLLegacy(m *parser.ModelConfig, model *state.Model) string {
 	sql := m.SQL
@@ -478,6 +774,12 @@ func (e *Engine) buildSQLLegacy(m *parser.ModelConfig, model *state.Model) strin
 
 // createExecutionContext builds a Starlark execution context for template rendering.
 func (e *Engine) createExecutionContext(m *parser.ModelConfig) *starctx.ExecutionContext {
+	return e.newExecutionContext(m, e.introspection)
+}
+
+// newExecutionContext builds a Starlark execution context whose run_query()
+// and adapter use introspection; with nil, they are unavailable.
+func (e *Engine) newExecutionContext(m *parser.ModelConfig, introspection *stdlib.Introspection) *starctx.ExecutionContext {
 	// Build config dict from model config
 	config := starctx.BuildConfigDict(
 		m.Name,
@@ -491,8 +793,9 @@ func (e *Engine) createExecutionContext(m *parser.ModelConfig) *starctx.Executio
 
 	// Build this info
 	thisInfo := &starctx.ThisInfo{
-		Name:   m.Name,
-		Schema: e.getModelSchema(m),
+		Name:     m.Name,
+		Schema:   e.getModelSchema(m),
+		Relation: pathToTableName(m.Path),
 	}
 
 	// Create context with macros
@@ -502,11 +805,51 @@ func (e *Engine) createExecutionContext(m *parser.ModelConfig) *starctx.Executio
 		e.target,
 		thisInfo,
 		starctx.WithMacroRegistry(e.macroRegistry),
+		starctx.WithClock(e.clock),
+		starctx.WithIntrospection(introspection),
+		starctx.WithRelations(stdlib.NewRelations(e.relationName)),
+		starctx.WithVars(e.starlarkVars),
 	)
 
 	return ctx
 }
 
+// relationName returns the table name emitted by relation(name): the table of
+// the model or seed the name resolves to, or the name itself for sources.
+func (e *Engine) relationName(name string) string {
+	if path, ok := e.registry.Resolve(name); ok {
+		return pathToTableName(path)
+	}
+	return name
+}
+
+// captureRelations renders a templated model to record the relations it
+// requests with relation(), and adds them to its Sources. Models that can't
+// be rendered before running (e.g. they use run_query) keep the sources the
+// parser found statically.
+func (e *Engine) captureRelations(m *parser.ModelConfig) {
+	if !strings.Contains(m.SQL, "{{") && !strings.Contains(m.SQL, "{*") {
+		return
+	}
+
+	ctx := e.createExecutionContext(m)
+	ctx.Introspection = nil
+	if _, err := template.RenderString(m.SQL, m.FilePath, ctx); err != nil {
+		return
+	}
+
+	known := make(map[string]bool, len(m.Sources))
+	for _, src := range m.Sources {
+		known[src] = true
+	}
+	for _, name := range ctx.Relations.Names() {
+		if !known[name] {
+			known[name] = true
+			m.Sources = append(m.Sources, name)
+		}
+	}
+}
+
 // getModelSchema extracts the schema from a model path.
 func (e *Engine) getModelSchema(m *parser.ModelConfig) string {
 	// If schema is explicitly set, use it
@@ -664,11 +1007,21 @@ func (e *Engine) GetGraph() *dag.Graph {
 	return e.graph
 }
 
+// Dialect returns the SQL dialect of the target.
+func (e *Engine) Dialect() *lineage.Dialect {
+	return e.dialect
+}
+
 // GetModels returns all discovered models.
 func (e *Engine) GetModels() map[string]*parser.ModelConfig {
 	return e.models
 }
 
+// GetSeeds returns all discovered seeds.
+func (e *Engine) GetSeeds() map[string]*parser.ModelConfig {
+	return e.seeds
+}
+
 // pathToTableName converts a model path to a SQL table name.
 // e.g., "staging.customers" -> "staging.customers"
 func pathToTableName(path string) string {
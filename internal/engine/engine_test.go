@@ -3,13 +3,20 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/internal/parser"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
 	"github.com/leapstack-labs/leapsql/internal/state"
+	"github.com/leapstack-labs/leapsql/internal/template"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
 )
 
 // testdataDir returns the path to the testdata directory.
@@ -66,6 +73,56 @@ func TestNew_InvalidStatePath(t *testing.T) {
 	}
 }
 
+func TestNew_UnknownTargetType(t *testing.T) {
+	_, err := New(Config{
+		StatePath: filepath.Join(t.TempDir(), "state.db"),
+		Target:    &starctx.TargetInfo{Type: "oracle"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown target type "oracle"`) {
+		t.Fatalf("expected unknown target type error, got %v", err)
+	}
+}
+
+func TestDiscover_TargetDialect(t *testing.T) {
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sql := "SELECT id, countif(status = \"done\") AS done FROM `raw.orders` GROUP BY id\n"
+	if err := os.WriteFile(filepath.Join(modelsDir, "done.sql"), []byte(sql), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backtick paths only parse with the BigQuery dialect
+	for _, tt := range []struct {
+		target  string
+		sources []string
+	}{
+		{"duckdb", nil},
+		{"bigquery", []string{"raw.orders"}},
+	} {
+		t.Run(tt.target, func(t *testing.T) {
+			engine, err := New(Config{
+				ModelsDir: modelsDir,
+				StatePath: filepath.Join(t.TempDir(), "state.db"),
+				Target:    &starctx.TargetInfo{Type: tt.target},
+			})
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			defer engine.Close()
+
+			if err := engine.Discover(); err != nil {
+				t.Fatalf("Discover() failed: %v", err)
+			}
+			if got := engine.GetModels()["done"].Sources; !reflect.DeepEqual(got, tt.sources) {
+				t.Errorf("sources = %v, want %v", got, tt.sources)
+			}
+		})
+	}
+}
+
 func TestLoadSeeds(t *testing.T) {
 	tmpDir := t.TempDir()
 	statePath := filepath.Join(tmpDir, "state.db")
@@ -122,6 +179,55 @@ func TestLoadSeeds(t *testing.T) {
 	}
 }
 
+func TestLoadSeeds_WithSeedConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	seedsDir := filepath.Join(tmpDir, "seeds")
+	if err := os.MkdirAll(seedsDir, 0755); err != nil {
+		t.Fatalf("Failed to create seeds dir: %v", err)
+	}
+
+	files := map[string]string{
+		"zips.csv": "id|zip\n007|02134\n",
+		"zips.yml": "schema: ref\ndelimiter: \"|\"\ncolumn_types:\n  id: VARCHAR\n  zip: VARCHAR\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(seedsDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := New(Config{
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	ctx := context.Background()
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+
+	rows, err := engine.db.Query(ctx, "SELECT id, zip FROM ref.zips")
+	if err != nil {
+		t.Fatalf("Query ref.zips failed: %v", err)
+	}
+	defer rows.Close()
+
+	var id, zip string
+	if !rows.Next() {
+		t.Fatal("expected one row in ref.zips")
+	}
+	if err := rows.Scan(&id, &zip); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if id != "007" || zip != "02134" {
+		t.Errorf("got id=%q zip=%q, want leading zeros preserved", id, zip)
+	}
+}
+
 func TestLoadSeeds_EmptySeedsDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	statePath := filepath.Join(tmpDir, "state.db")
@@ -214,10 +320,11 @@ func TestDiscover(t *testing.T) {
 		}
 	}
 
-	// Check DAG was built
+	// Check DAG was built (seeds are nodes too)
 	graph := engine.GetGraph()
-	if graph.NodeCount() != len(expectedModels) {
-		t.Errorf("Graph has %d nodes, want %d", graph.NodeCount(), len(expectedModels))
+	want := len(expectedModels) + len(engine.GetSeeds())
+	if graph.NodeCount() != want {
+		t.Errorf("Graph has %d nodes, want %d", graph.NodeCount(), want)
 	}
 }
 
@@ -462,7 +569,7 @@ func TestBuildSQL(t *testing.T) {
 		Path: "marts.summary",
 	}
 
-	sql := engine.buildSQL(modelCfg, model)
+	sql := engine.buildSQL(modelCfg, model, engine.createExecutionContext(modelCfg))
 
 	// Check that {{ this }} was replaced
 	if strings.Contains(sql, "{{ this }}") {
@@ -1049,3 +1156,417 @@ SELECT id, name FROM users
 		t.Errorf("active_users has %d rows, want 2", count)
 	}
 }
+
+// countRows returns the number of rows in a table.
+func countRows(t *testing.T, engine *Engine, table string) int {
+	t.Helper()
+	rows, err := engine.db.Query(context.Background(), "SELECT COUNT(*) FROM "+table)
+	if err != nil {
+		t.Fatalf("Query %s failed: %v", table, err)
+	}
+	defer rows.Close()
+	var count int
+	if rows.Next() {
+		rows.Scan(&count)
+	}
+	return count
+}
+
+func TestDiscover_SeedsAreGraphNodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.db")
+
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(testdataDir(), "models"),
+		SeedsDir:  filepath.Join(testdataDir(), "seeds"),
+		MacrosDir: filepath.Join(testdataDir(), "macros"),
+		StatePath: statePath,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+
+	seed, ok := engine.GetSeeds()["raw_customers"]
+	if !ok || seed.Materialized != "seed" {
+		t.Fatalf("seed raw_customers not discovered: %+v", seed)
+	}
+	if _, isModel := engine.GetModels()["raw_customers"]; isModel {
+		t.Error("seed should not be listed as a model")
+	}
+
+	// Templated model reading the seed depends on it
+	parents := engine.GetGraph().GetParents("staging.stg_customers")
+	if len(parents) != 1 || parents[0] != "raw_customers" {
+		t.Errorf("stg_customers parents = %v, want [raw_customers]", parents)
+	}
+}
+
+func TestLoadSeeds_ReloadsOnlyWhenChanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	seedsDir := filepath.Join(tmpDir, "seeds")
+	if err := os.MkdirAll(seedsDir, 0755); err != nil {
+		t.Fatalf("Failed to create seeds dir: %v", err)
+	}
+	csvPath := filepath.Join(seedsDir, "codes.csv")
+	if err := os.WriteFile(csvPath, []byte("code\na\nb\n"), 0644); err != nil {
+		t.Fatalf("Failed to write seed: %v", err)
+	}
+
+	engine, err := New(Config{
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	ctx := context.Background()
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+
+	// Modify the loaded table; an unchanged seed must not overwrite it
+	if err := engine.db.Exec(ctx, "INSERT INTO codes VALUES ('manual')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if n := countRows(t, engine, "codes"); n != 3 {
+		t.Errorf("unchanged seed was reloaded: %d rows, want 3", n)
+	}
+
+	// Changing the CSV triggers a reload
+	if err := os.WriteFile(csvPath, []byte("code\na\n"), 0644); err != nil {
+		t.Fatalf("Failed to write seed: %v", err)
+	}
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if n := countRows(t, engine, "codes"); n != 1 {
+		t.Errorf("changed seed was not reloaded: %d rows, want 1", n)
+	}
+
+	// A missing table is reloaded even if the hash is unchanged
+	if err := engine.db.Exec(ctx, "DROP TABLE codes"); err != nil {
+		t.Fatalf("drop failed: %v", err)
+	}
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if n := countRows(t, engine, "codes"); n != 1 {
+		t.Errorf("dropped seed was not reloaded: %d rows, want 1", n)
+	}
+}
+
+func TestRunSelected_LoadsUpstreamSeeds(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(testdataDir(), "models"),
+		SeedsDir:  filepath.Join(testdataDir(), "seeds"),
+		MacrosDir: filepath.Join(testdataDir(), "macros"),
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+
+	// No explicit LoadSeeds: the run loads the seed the model needs
+	ctx := context.Background()
+	run, err := engine.RunSelected(ctx, "test", []string{"staging.stg_customers"}, false)
+	if err != nil {
+		t.Fatalf("RunSelected() failed: %v", err)
+	}
+	if run.Status != state.RunStatusCompleted {
+		t.Errorf("Run status = %q, want completed. Error: %s", run.Status, run.Error)
+	}
+	if n := countRows(t, engine, "staging.stg_customers"); n == 0 {
+		t.Error("staging.stg_customers has 0 rows")
+	}
+
+	// Only the needed seed was loaded
+	if _, err := engine.db.GetTableMetadata(ctx, "raw_products"); err == nil {
+		t.Error("raw_products should not have been loaded")
+	}
+}
+
+func TestLoadSeeds_MultiFileParquetSeed(t *testing.T) {
+	tmpDir := t.TempDir()
+	seedsDir := filepath.Join(tmpDir, "seeds")
+	partsDir := filepath.Join(seedsDir, "rates")
+	if err := os.MkdirAll(partsDir, 0755); err != nil {
+		t.Fatalf("Failed to create seeds dir: %v", err)
+	}
+
+	engine, err := New(Config{
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	ctx := context.Background()
+	writePart := func(name, code string) {
+		path := filepath.Join(partsDir, name)
+		query := "COPY (SELECT '" + code + "' AS code) TO '" + path + "' (FORMAT PARQUET)"
+		if err := engine.db.Exec(ctx, query); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	writePart("a.parquet", "EUR")
+	writePart("b.parquet", "GBP")
+	if err := os.WriteFile(filepath.Join(seedsDir, "codes.json"), []byte(`[{"code": "x"}]`), 0644); err != nil {
+		t.Fatalf("Failed to write seed: %v", err)
+	}
+
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if n := countRows(t, engine, "rates"); n != 2 {
+		t.Errorf("rates has %d rows, want 2", n)
+	}
+	if n := countRows(t, engine, "codes"); n != 1 {
+		t.Errorf("codes has %d rows, want 1", n)
+	}
+
+	// Adding a file to a multi-file seed changes its hash
+	writePart("c.parquet", "USD")
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if n := countRows(t, engine, "rates"); n != 3 {
+		t.Errorf("rates has %d rows after adding a file, want 3", n)
+	}
+}
+
+func TestDiscover_VendoredPackages(t *testing.T) {
+	engine := setupTestProjectWithConfig(t, map[string]string{
+		"_vendor/utils/macros/strings.star": "def shout(col):\n    return \"upper(\" + col + \")\"\n",
+		"_vendor/utils/models/calendar.sql": "SELECT 1 AS day_id, 'mon' AS day_name",
+		"models/days.sql":                   "SELECT day_id, {{ utils.shout(\"day_name\") }} AS day_name FROM utils.calendar",
+	}, func(cfg *Config, dir string) {
+		cfg.VendorDir = filepath.Join(dir, "_vendor")
+	})
+
+	m, ok := engine.GetModels()["utils.calendar"]
+	if !ok || m.Package != "utils" {
+		t.Fatalf("package model utils.calendar not discovered: %+v", m)
+	}
+	if parents := engine.GetGraph().GetParents("days"); len(parents) != 1 || parents[0] != "utils.calendar" {
+		t.Errorf("days parents = %v, want [utils.calendar]", parents)
+	}
+
+	rows, err := engine.db.Query(context.Background(), "SELECT day_name FROM days")
+	if err != nil {
+		t.Fatalf("Query days failed: %v", err)
+	}
+	defer rows.Close()
+	var name string
+	if !rows.Next() || rows.Scan(&name) != nil || name != "MON" {
+		t.Errorf("day_name = %q, want MON (package macro applied)", name)
+	}
+}
+
+func TestRun_IntrospectiveMacros(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"seeds/raw_orders.csv": "id,status\n1,placed\n2,shipped\n3,shipped\n",
+		"macros/pivot.star": `
+def counts(relation, column):
+    if column not in [c.name for c in adapter.get_columns(relation)]:
+        fail("unknown column " + column)
+    values = run_query("SELECT DISTINCT %s FROM %s ORDER BY 1" % (column, relation)).rows
+    return ", ".join(["count(*) FILTER (WHERE %s = '%s') AS %s" % (column, v[0], v[0]) for v in values])
+`,
+		"models/status_counts.sql": `SELECT {{ pivot.counts("raw_orders", "status") }} FROM raw_orders`,
+	})
+
+	rows, err := engine.db.Query(context.Background(), "SELECT placed, shipped FROM status_counts")
+	if err != nil {
+		t.Fatalf("Query status_counts failed: %v", err)
+	}
+	defer rows.Close()
+	var placed, shipped int
+	if !rows.Next() || rows.Scan(&placed, &shipped) != nil || placed != 1 || shipped != 2 {
+		t.Errorf("placed, shipped = %d, %d; want 1, 2", placed, shipped)
+	}
+
+	// Outside a run (e.g. unit tests) introspection is disabled
+	engine.introspection = nil
+	m := engine.GetModels()["status_counts"]
+	_, err = template.RenderString(m.SQL, m.FilePath, engine.createExecutionContext(m))
+	if err == nil || !strings.Contains(err.Error(), "no database connection") {
+		t.Errorf("render without introspection: error = %v, want no database connection", err)
+	}
+}
+
+func TestDiscover_RelationDependencies(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"macros/utils.star":        "def union(names):\n    return \" UNION ALL \".join([\"SELECT id FROM \" + relation(\"staging.stg_\" + n) for n in names])\n",
+		"models/staging/stg_a.sql": "SELECT 1 AS id",
+		"models/staging/stg_b.sql": "SELECT 2 AS id",
+		"models/staging/stg_c.sql": "SELECT 3 AS id",
+		"models/marts/unioned.sql": `{{ utils.union(["a", "b"]) }} UNION ALL SELECT id FROM {{ relation("stg_c") }}`,
+	})
+
+	parents := engine.GetGraph().GetParents("marts.unioned")
+	sort.Strings(parents)
+	want := []string{"staging.stg_a", "staging.stg_b", "staging.stg_c"}
+	if !reflect.DeepEqual(parents, want) {
+		t.Errorf("marts.unioned parents = %v, want %v", parents, want)
+	}
+
+	// Unqualified names are emitted as the model's table
+	if n := countRows(t, engine, "marts.unioned"); n != 3 {
+		t.Errorf("marts.unioned has %d rows, want 3", n)
+	}
+}
+
+func TestDiscover_HookDependencies(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"models/staging/stg_runs.sql": "SELECT 1 AS id",
+		"models/marts/orders.sql": `/*---
+post_hook: "CREATE OR REPLACE TABLE audit AS SELECT r.id FROM {{ this.relation }} JOIN staging.stg_runs r ON true"
+---*/
+SELECT 1 AS id`,
+	})
+
+	parents := engine.GetGraph().GetParents("marts.orders")
+	if !reflect.DeepEqual(parents, []string{"staging.stg_runs"}) {
+		t.Errorf("marts.orders parents = %v, want [staging.stg_runs]", parents)
+	}
+	// The hook read staging.stg_runs after it was built
+	if n := countRows(t, engine, "audit"); n != 1 {
+		t.Errorf("audit has %d rows, want 1", n)
+	}
+	// Hook sources order the model but are not inputs
+	if m := engine.GetModels()["marts.orders"]; len(m.Sources) != 0 {
+		t.Errorf("marts.orders sources = %v, want none", m.Sources)
+	}
+}
+
+func TestRenderModel_FilePosition(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"models/staging/stg_runs.sql": "SELECT 1 AS id",
+		"models/marts/runs.sql": `/*---
+materialized: view
+---*/
+SELECT r.id,
+  r.id + {{ 1 + 1 }} AS two
+FROM staging.stg_runs r`,
+	})
+
+	rendered, err := engine.RenderModel("marts.runs")
+	if err != nil {
+		t.Fatalf("RenderModel() failed: %v", err)
+	}
+	stmt, err := lineage.Parse(rendered.SQL)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	core := stmt.Body.Left
+	sum := core.Columns[1].Expr.(*lineage.BinaryExpr)
+
+	tests := []struct {
+		name         string
+		pos          lineage.Position
+		line, column int
+	}{
+		{"column", sum.Start, 5, 3},
+		{"expression output", sum.Right.NodeSpan().Start, 5, 10},
+		{"table", core.From.Source.NodeSpan().Start, 6, 6},
+	}
+	for _, tt := range tests {
+		line, column := rendered.FilePosition(tt.pos)
+		if line != tt.line || column != tt.column {
+			t.Errorf("%s maps to %d:%d, want %d:%d", tt.name, line, column, tt.line, tt.column)
+		}
+	}
+
+	if _, err := engine.RenderModel("marts.missing"); err == nil {
+		t.Error("expected error for unknown model")
+	}
+}
+
+func TestRenderModel_ParseErrors(t *testing.T) {
+	// Discovered but not run, as the model doesn't parse
+	engine := newUnitTestEngine(t, map[string]string{
+		"models/marts/runs.sql": `/*---
+materialized: view
+---*/
+SELECT r.id,
+  r.id + {{ 1 + 1 }} + AS two,
+  (r.id * ) AS three
+FROM staging.stg_runs r`,
+	})
+
+	rendered, err := engine.RenderModel("marts.runs")
+	if err != nil {
+		t.Fatalf("RenderModel() failed: %v", err)
+	}
+	_, err = lineage.Parse(rendered.SQL)
+	var errs lineage.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() error = %v, want ParseErrors", err)
+	}
+
+	// Each error maps past the frontmatter, and past the template block
+	// before it on its line
+	want := []string{"5:24", "6:11"}
+	var got []string
+	for _, e := range errs {
+		line, column := rendered.FilePosition(e.Pos)
+		got = append(got, fmt.Sprintf("%d:%d", line, column))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors at %v, want %v (%v)", got, want, err)
+	}
+}
+
+func TestRun_CustomMaterialization(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"macros/materializations.star": `
+def append_only(sql, this, adapter):
+    if adapter.relation_exists(this.relation):
+        adapter.exec("INSERT INTO %s %s" % (this.relation, sql))
+    else:
+        adapter.exec("CREATE TABLE %s AS %s" % (this.relation, sql))
+    return adapter.query("SELECT count(*) FROM " + this.relation).rows[0][0]
+`,
+		"models/events.sql": `/*---
+materialized: append_only
+---*/
+SELECT 1 AS id UNION ALL SELECT 2`,
+	})
+
+	ctx := context.Background()
+	run, err := engine.Run(ctx, "dev")
+	if err != nil {
+		t.Fatalf("second Run() failed: %v", err)
+	}
+	modelRuns, err := engine.store.GetModelRunsForRun(run.ID)
+	if err != nil || len(modelRuns) != 1 {
+		t.Fatalf("GetModelRunsForRun() = %v, %v", modelRuns, err)
+	}
+	if modelRuns[0].RowsAffected != 4 {
+		t.Errorf("rows affected = %d, want 4 after appending twice", modelRuns[0].RowsAffected)
+	}
+
+	_, err = engine.executeCustom(ctx, &parser.ModelConfig{Path: "missing", Materialized: "snapshot"}, "SELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "unknown materialization: snapshot") {
+		t.Errorf("expected unknown materialization error, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/template"
+)
+
+// Hook kinds, used to identify a failing hook in errors.
+const (
+	hookPre        = "pre_hook"
+	hookPost       = "post_hook"
+	hookOnRunStart = "on_run_start"
+	hookOnRunEnd   = "on_run_end"
+)
+
+// runHooks renders each hook with the given context and executes it in order.
+// Rendering errors are not papered over: a hook that fails to render fails.
+func (e *Engine) runHooks(ctx context.Context, kind string, hooks []string, file string, tctx *starctx.ExecutionContext) error {
+	for i, hook := range hooks {
+		sql, err := template.RenderString(hook, file, tctx)
+		if err != nil {
+			return fmt.Errorf("%s %d: %w", kind, i+1, err)
+		}
+		if err := e.db.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("%s %d failed: %w", kind, i+1, err)
+		}
+	}
+	return nil
+}
+
+// runProjectHooks runs the on_run_start or on_run_end hooks. They are
+// rendered without a model: config is empty and this is not set.
+func (e *Engine) runProjectHooks(ctx context.Context, kind string, hooks []string) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	tctx := starctx.NewContext(
+		starctx.BuildConfigDict("", "", "", "", "", nil, nil),
+		e.environment,
+		e.target,
+		nil,
+		starctx.WithMacroRegistry(e.macroRegistry),
+		starctx.WithClock(e.clock),
+		starctx.WithIntrospection(e.introspection),
+		starctx.WithVars(e.starlarkVars),
+	)
+	return e.runHooks(ctx, kind, hooks, kind, tctx)
+}
+
+// finishRun runs the on_run_end hooks and returns the error the run ends
+// with: the first failure, or the hooks' own failure if the run succeeded.
+// on_run_end runs even when a model failed, so audit hooks see every run.
+func (e *Engine) finishRun(ctx context.Context, runErr error) error {
+	if err := e.runProjectHooks(ctx, hookOnRunEnd, e.onRunEnd); err != nil && runErr == nil {
+		return err
+	}
+	return runErr
+}
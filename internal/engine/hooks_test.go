@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/state"
+)
+
+func TestRun_Hooks(t *testing.T) {
+	engine := setupTestProjectWithConfig(t, map[string]string{
+		"models/orders.sql": `/*---
+pre_hook: "INSERT INTO audit VALUES ('pre', '{{ this.name }}', NULL)"
+post_hook:
+  - "INSERT INTO audit SELECT 'post', '{{ this.name }}', count(*) FROM {{ this.schema }}.{{ this.name }}"
+---*/
+SELECT 1 AS id UNION ALL SELECT 2`,
+	}, func(cfg *Config, dir string) {
+		cfg.OnRunStart = []string{"CREATE TABLE IF NOT EXISTS audit (event VARCHAR, subject VARCHAR, n BIGINT)"}
+		cfg.OnRunEnd = []string{"INSERT INTO audit VALUES ('end', '{{ env }}', NULL)"}
+	})
+
+	rows, err := engine.db.Query(context.Background(),
+		"SELECT string_agg(event || ':' || subject || ':' || coalesce(n::VARCHAR, ''), ',' ORDER BY rowid) FROM audit")
+	if err != nil {
+		t.Fatalf("Query audit failed: %v", err)
+	}
+	defer rows.Close()
+	var got string
+	if !rows.Next() || rows.Scan(&got) != nil {
+		t.Fatal("audit has no rows")
+	}
+	if want := "pre:orders:,post:orders:2,end:dev:"; got != want {
+		t.Errorf("audit = %q, want %q", got, want)
+	}
+}
+
+func TestRun_HookFailureRecordedOnModelRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	model := "/*---\npost_hook: INSERT INTO missing_table VALUES (1)\n---*/\nSELECT 1 AS id"
+	if err := os.WriteFile(filepath.Join(modelsDir, "orders.sql"), []byte(model), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		OnRunEnd:  []string{"CREATE TABLE run_end AS SELECT 1 AS x"},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	run, err := engine.Run(ctx, "dev")
+	if err == nil || run.Status != state.RunStatusFailed {
+		t.Fatalf("Run() = %v, %v; want a failed run", run.Status, err)
+	}
+
+	modelRuns, err := engine.store.GetModelRunsForRun(run.ID)
+	if err != nil || len(modelRuns) != 1 {
+		t.Fatalf("GetModelRunsForRun() = %v, %v", modelRuns, err)
+	}
+	if modelRuns[0].Status != state.ModelRunStatusFailed || !strings.Contains(modelRuns[0].Error, "post_hook 1 failed") {
+		t.Errorf("model run = %s %q, want failed post_hook", modelRuns[0].Status, modelRuns[0].Error)
+	}
+
+	// on_run_end still runs after a failure
+	if _, err := engine.db.GetTableMetadata(ctx, "run_end"); err != nil {
+		t.Errorf("on_run_end hook did not run: %v", err)
+	}
+}
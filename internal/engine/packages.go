@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/leapstack-labs/leapsql/internal/deps"
+	"github.com/leapstack-labs/leapsql/internal/macro"
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+// Directories of a vendored package, relative to _vendor/<name>.
+const (
+	packageMacrosDir = "macros"
+	packageModelsDir = "models"
+)
+
+// loadPackageMacros registers the macros of every vendored package under the
+// package name (e.g. {{ utils.slugify(col) }}).
+func loadPackageMacros(registry *macro.Registry, vendorDir string) error {
+	names, err := deps.Installed(vendorDir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		module, err := macro.LoadPackage(name, filepath.Join(vendorDir, name, packageMacrosDir))
+		if err != nil {
+			return fmt.Errorf("failed to load macros of package %s: %w", name, err)
+		}
+		if module == nil {
+			continue
+		}
+		if err := registry.Register(module); err != nil {
+			return fmt.Errorf("failed to register package %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// scanPackageModels returns the models of every vendored package, with paths
+// namespaced by the package name.
+func scanPackageModels(vendorDir string, dialect *lineage.Dialect) ([]*parser.ModelConfig, error) {
+	names, err := deps.Installed(vendorDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*parser.ModelConfig
+	for _, name := range names {
+		pkgModels, err := parser.ScanPackageWithDialect(name, filepath.Join(vendorDir, name, packageModelsDir), dialect)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan models: %w", err)
+		}
+		models = append(models, pkgModels...)
+	}
+
+	return models, nil
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
+	"github.com/leapstack-labs/leapsql/internal/state"
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// modelSQL returns the SQL a model is materialized from: the rendered
+// template of a .sql model, or the result of a .star model's model(ctx),
+// evaluated in tctx.
+func (e *Engine) modelSQL(m *parser.ModelConfig, model *state.Model, tctx *starctx.ExecutionContext) (string, error) {
+	if m.Language == parser.LanguageStarlark {
+		return e.starlarkModelSQL(m, tctx)
+	}
+	return e.buildSQL(m, model, tctx), nil
+}
+
+// starlarkModelSQL runs a .star model. model(ctx) returns SQL text, which is
+// used as is, or a list of row dicts, which becomes a VALUES query so that
+// every materialization works for both.
+func (e *Engine) starlarkModelSQL(m *parser.ModelConfig, tctx *starctx.ExecutionContext) (string, error) {
+	globals, err := tctx.ExecFile(m.FilePath, m.RawContent)
+	if err != nil {
+		return "", fmt.Errorf("model %s: %w", m.Path, err)
+	}
+	fn, ok := globals[parser.StarlarkModelFunc].(starlark.Callable)
+	if !ok {
+		return "", fmt.Errorf("model %s: %s is not a function", m.Path, parser.StarlarkModelFunc)
+	}
+
+	result, err := tctx.Call(m.FilePath, fn, starlark.Tuple{modelContext(tctx)})
+	if err != nil {
+		return "", fmt.Errorf("model %s: %w", m.Path, err)
+	}
+
+	switch v := result.(type) {
+	case starlark.String:
+		return string(v), nil
+	case *starlark.List:
+		sql, err := rowsSQL(v)
+		if err != nil {
+			return "", fmt.Errorf("model %s: %w", m.Path, err)
+		}
+		return sql, nil
+	default:
+		return "", fmt.Errorf("model %s: %s must return SQL or a list of row dicts, got %s", m.Path, parser.StarlarkModelFunc, result.Type())
+	}
+}
+
+// modelContext builds the ctx argument of model(ctx). ctx.ref(name) returns
+// the table of a model, seed or source like relation(); literal names are
+// the model's dependencies.
+func modelContext(tctx *starctx.ExecutionContext) starlark.Value {
+	ref := starlark.NewBuiltin("ctx.ref", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return stdlib.Relation.CallInternal(thread, args, kwargs)
+	})
+
+	this := starlark.Value(starlark.None)
+	if tctx.This != nil {
+		this = tctx.This.ToStarlark()
+	}
+	return starlarkstruct.FromStringDict(starlark.String("ctx"), starlark.StringDict{
+		"ref":    ref,
+		"config": tctx.Config,
+		"env":    starlark.String(tctx.Env),
+		"target": tctx.Target.ToStarlark(),
+		"this":   this,
+	})
+}
+
+// rowsSQL converts the rows returned by a Starlark model to a query. Columns
+// are taken in order of first appearance; a row without a column gives NULL.
+func rowsSQL(list *starlark.List) (string, error) {
+	if list.Len() == 0 {
+		return "", fmt.Errorf("returned no rows, so its columns are unknown")
+	}
+
+	var cols []string
+	index := make(map[string]int)
+	rows := make([][]string, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		dict, ok := list.Index(i).(*starlark.Dict)
+		if !ok {
+			return "", fmt.Errorf("row %d: expected dict, got %s", i, list.Index(i).Type())
+		}
+		row := make([]string, len(cols), len(cols)+dict.Len())
+		for j := range row {
+			row[j] = "NULL"
+		}
+		for _, item := range dict.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return "", fmt.Errorf("row %d: column names must be strings, got %s", i, item[0].Type())
+			}
+			lit, err := rowLiteral(item[1])
+			if err != nil {
+				return "", fmt.Errorf("row %d, column %s: %w", i, key, err)
+			}
+			j, ok := index[string(key)]
+			if !ok {
+				j = len(cols)
+				index[string(key)] = j
+				cols = append(cols, string(key))
+			}
+			for len(row) <= j {
+				row = append(row, "NULL")
+			}
+			row[j] = lit
+		}
+		rows[i] = row
+	}
+
+	tuples := make([]string, len(rows))
+	for i, row := range rows {
+		for len(row) < len(cols) {
+			row = append(row, "NULL")
+		}
+		tuples[i] = "(" + strings.Join(row, ", ") + ")"
+	}
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = quoteIdent(col)
+	}
+
+	return fmt.Sprintf("SELECT * FROM (VALUES %s) AS t(%s)",
+		strings.Join(tuples, ", "), strings.Join(quoted, ", ")), nil
+}
+
+// quoteIdent quotes a column name for DuckDB.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// rowLiteral renders a row value as a SQL literal. Times at midnight become
+// DATEs, other times TIMESTAMPs.
+func rowLiteral(v starlark.Value) (string, error) {
+	switch val := v.(type) {
+	case starlarktime.Time:
+		t := time.Time(val)
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			return "DATE '" + formatValue(t) + "'", nil
+		}
+		return "TIMESTAMP '" + formatValue(t) + "'", nil
+	case starlark.NoneType, starlark.Bool, starlark.Int, starlark.Float, starlark.String:
+		gv, err := starctx.StarlarkToGo(val)
+		if err != nil {
+			return "", err
+		}
+		return sqlLiteral(gv), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %s", v.Type())
+	}
+}
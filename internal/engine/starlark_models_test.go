@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestRun_StarlarkModels(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"models/staging/orders.sql": `SELECT 1 AS id, 10 AS amount UNION ALL SELECT 2, 20`,
+		"models/marts/calendar.star": `"""---
+materialized: table
+---"""
+def model(ctx):
+    start = datetime.date(2024, 1, 30)
+    return [{"day": datetime.add(start, days=i), "n": i, "label": "d%d" % i} for i in range(3)]
+`,
+		"models/marts/order_totals.star": `
+def model(ctx):
+    orders = ctx.ref("staging.orders")
+    return "SELECT sum(amount) AS total FROM %s WHERE '%s' = 'dev'" % (orders, ctx.env)
+`,
+	})
+
+	if deps := engine.graph.GetParents("marts.order_totals"); !reflect.DeepEqual(deps, []string{"staging.orders"}) {
+		t.Errorf("order_totals dependencies = %v, want [staging.orders]", deps)
+	}
+
+	ctx := context.Background()
+	rows, err := engine.db.Query(ctx, "SELECT typeof(day), string_agg(strftime(day, '%m-%d') || '/' || n || '/' || label, ',' ORDER BY n) FROM marts.calendar GROUP BY 1")
+	if err != nil {
+		t.Fatalf("Query calendar failed: %v", err)
+	}
+	defer rows.Close()
+	var typ, got string
+	if !rows.Next() || rows.Scan(&typ, &got) != nil {
+		t.Fatal("calendar has no rows")
+	}
+	if typ != "DATE" || got != "01-30/0/d0,01-31/1/d1,02-01/2/d2" {
+		t.Errorf("calendar = %s %q", typ, got)
+	}
+
+	totals, err := engine.db.Query(ctx, "SELECT total FROM marts.order_totals")
+	if err != nil {
+		t.Fatalf("Query order_totals failed: %v", err)
+	}
+	defer totals.Close()
+	var total int64
+	if !totals.Next() || totals.Scan(&total) != nil || total != 30 {
+		t.Errorf("order_totals.total = %d, want 30", total)
+	}
+}
+
+func TestRowsSQL_DifferentColumns(t *testing.T) {
+	row := func(kv ...any) *starlark.Dict {
+		d := starlark.NewDict(len(kv) / 2)
+		for i := 0; i < len(kv); i += 2 {
+			d.SetKey(starlark.String(kv[i].(string)), starlark.MakeInt(kv[i+1].(int)))
+		}
+		return d
+	}
+	rows := starlark.NewList([]starlark.Value{row("a", 1, "b", 2), row("b", 3), row("c", 4)})
+
+	got, err := rowsSQL(rows)
+	if err != nil {
+		t.Fatalf("rowsSQL() error = %v", err)
+	}
+	want := `SELECT * FROM (VALUES (1, 2, NULL), (NULL, 3, NULL), (NULL, NULL, 4)) AS t("a", "b", "c")`
+	if got != want {
+		t.Errorf("rowsSQL() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,369 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
+	"github.com/leapstack-labs/leapsql/internal/state"
+	"github.com/leapstack-labs/leapsql/internal/template"
+	"go.starlark.net/starlark"
+)
+
+// testSampleLimit is the maximum number of failing rows stored per test.
+const testSampleLimit = 5
+
+// genericTestPrefix is the function name prefix for generic test macros.
+// A test named "relationships" is implemented by a macro "test_relationships".
+const genericTestPrefix = "test_"
+
+// dataTest is a single executable test: a query returning failing rows.
+type dataTest struct {
+	name      string
+	modelPath string
+	severity  string
+	sql       string
+	err       error // set when the test could not be compiled
+}
+
+// Test runs data tests for all models (or only modelPaths when non-empty)
+// and all singular tests in the tests directory. Results are recorded in the
+// state store under a new run. The run fails if any test with severity
+// "error" fails.
+func (e *Engine) Test(ctx context.Context, env string, modelPaths []string) (*state.Run, []*state.TestResult, error) {
+	e.introspection = stdlib.NewIntrospection(ctx, e.db)
+
+	run, err := e.store.CreateRunWithVars(env, e.vars)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	tests, err := e.collectTests(modelPaths)
+	if err != nil {
+		e.store.CompleteRun(run.ID, state.RunStatusFailed, err.Error())
+		return run, nil, err
+	}
+
+	var results []*state.TestResult
+	failed := 0
+	for _, t := range tests {
+		result := e.executeTest(ctx, t)
+		result.RunID = run.ID
+		if err := e.store.RecordTestResult(result); err != nil {
+			e.store.CompleteRun(run.ID, state.RunStatusFailed, err.Error())
+			return run, results, err
+		}
+		results = append(results, result)
+
+		if result.Status == state.TestStatusFail || result.Status == state.TestStatusError {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		msg := fmt.Sprintf("%d of %d tests failed", failed, len(results))
+		e.store.CompleteRun(run.ID, state.RunStatusFailed, msg)
+		run.Status = state.RunStatusFailed
+		run.Error = msg
+	} else {
+		e.store.CompleteRun(run.ID, state.RunStatusCompleted, "")
+		run.Status = state.RunStatusCompleted
+	}
+
+	return run, results, nil
+}
+
+// collectTests builds the list of tests to run in a deterministic order.
+func (e *Engine) collectTests(modelPaths []string) ([]*dataTest, error) {
+	paths := modelPaths
+	if len(paths) == 0 {
+		for path := range e.models {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+
+	var tests []*dataTest
+	for _, path := range paths {
+		m, ok := e.models[path]
+		if !ok {
+			return nil, fmt.Errorf("model not found: %s", path)
+		}
+		for _, tc := range m.Tests {
+			tests = append(tests, e.modelTests(m, tc)...)
+		}
+	}
+
+	// Singular tests are not tied to a model, so they only run unfiltered
+	if len(modelPaths) == 0 && e.testsDir != "" {
+		singular, err := parser.ScanTests(e.testsDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tests: %w", err)
+		}
+		for _, st := range singular {
+			tests = append(tests, e.singularTest(st))
+		}
+	}
+
+	return tests, nil
+}
+
+// modelTests expands one frontmatter test entry into executable tests.
+func (e *Engine) modelTests(m *parser.ModelConfig, tc parser.TestConfig) []*dataTest {
+	tableName := pathToTableName(m.Path)
+	severity := tc.Severity
+	if severity == "" {
+		severity = parser.SeverityError
+	}
+
+	var tests []*dataTest
+	add := func(name, sql string, err error) {
+		tests = append(tests, &dataTest{
+			name:      name,
+			modelPath: m.Path,
+			severity:  severity,
+			sql:       sql,
+			err:       err,
+		})
+	}
+
+	for _, col := range tc.Unique {
+		add(fmt.Sprintf("unique(%s)", col), fmt.Sprintf(
+			"SELECT %s, COUNT(*) AS n_records FROM %s WHERE %s IS NOT NULL GROUP BY %s HAVING COUNT(*) > 1",
+			col, tableName, col, col), nil)
+	}
+
+	for _, col := range tc.NotNull {
+		add(fmt.Sprintf("not_null(%s)", col), fmt.Sprintf(
+			"SELECT * FROM %s WHERE %s IS NULL", tableName, col), nil)
+	}
+
+	if av := tc.AcceptedValues; av != nil {
+		quoted := make([]string, len(av.Values))
+		for i, v := range av.Values {
+			quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		add(fmt.Sprintf("accepted_values(%s)", av.Column), fmt.Sprintf(
+			"SELECT %s, COUNT(*) AS n_records FROM %s WHERE CAST(%s AS VARCHAR) NOT IN (%s) GROUP BY %s",
+			av.Column, tableName, av.Column, strings.Join(quoted, ", "), av.Column), nil)
+	}
+
+	if g := tc.Generic; g != nil {
+		sql, err := e.genericTestSQL(tableName, g)
+		add(genericTestName(g), sql, err)
+	}
+
+	return tests
+}
+
+// genericTestName formats a generic test name with its column argument, if any.
+func genericTestName(g *parser.GenericTestConfig) string {
+	if col, ok := g.Args["column"].(string); ok {
+		return fmt.Sprintf("%s(%s)", g.Name, col)
+	}
+	return g.Name
+}
+
+// genericTestSQL calls the macro implementing a generic test and returns the
+// query it generates. The macro receives the tested relation as "model" and
+// the frontmatter arguments as keyword arguments.
+func (e *Engine) genericTestSQL(tableName string, g *parser.GenericTestConfig) (string, error) {
+	fn, err := e.lookupGenericTest(g.Name)
+	if err != nil {
+		return "", err
+	}
+
+	argNames := make([]string, 0, len(g.Args))
+	for name := range g.Args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+
+	kwargs := []starlark.Tuple{{starlark.String("model"), starlark.String(tableName)}}
+	for _, name := range argNames {
+		v, err := starctx.GoToStarlark(g.Args[name])
+		if err != nil {
+			return "", fmt.Errorf("test %s: argument %q: %w", g.Name, name, err)
+		}
+		kwargs = append(kwargs, starlark.Tuple{starlark.String(name), v})
+	}
+
+	thread := &starlark.Thread{Name: "test:" + g.Name}
+	stdlib.SetClock(thread, e.clock)
+	stdlib.SetIntrospection(thread, e.introspection)
+	stdlib.SetVars(thread, e.starlarkVars)
+	result, err := starlark.Call(thread, fn, nil, kwargs)
+	if err != nil {
+		return "", fmt.Errorf("test %s: %w", g.Name, err)
+	}
+
+	sql, ok := result.(starlark.String)
+	if !ok {
+		return "", fmt.Errorf("test %s: macro must return a SQL string, got %s", g.Name, result.Type())
+	}
+	return string(sql), nil
+}
+
+// lookupGenericTest finds the macro implementing a generic test. Names may be
+// qualified with a macro namespace ("utils.relationships"); unqualified names
+// are searched across all namespaces and must be unambiguous.
+func (e *Engine) lookupGenericTest(name string) (starlark.Callable, error) {
+	if ns, fn, ok := strings.Cut(name, "."); ok {
+		module := e.macroRegistry.Get(ns)
+		if module == nil {
+			return nil, fmt.Errorf("unknown test %q: macro namespace %q not found", name, ns)
+		}
+		if c, ok := module.Exports[genericTestPrefix+fn].(starlark.Callable); ok {
+			return c, nil
+		}
+		return nil, fmt.Errorf("unknown test %q: %s has no function %s%s", name, module.Path, genericTestPrefix, fn)
+	}
+
+	var found starlark.Callable
+	var foundIn []string
+	for _, ns := range e.macroRegistry.Namespaces() {
+		if c, ok := e.macroRegistry.Get(ns).Exports[genericTestPrefix+name].(starlark.Callable); ok {
+			found = c
+			foundIn = append(foundIn, ns)
+		}
+	}
+
+	switch len(foundIn) {
+	case 0:
+		return nil, fmt.Errorf("unknown test %q: no macro defines %s%s", name, genericTestPrefix, name)
+	case 1:
+		return found, nil
+	default:
+		return nil, fmt.Errorf("ambiguous test %q: defined in namespaces %s", name, strings.Join(foundIn, ", "))
+	}
+}
+
+// singularTest renders a singular test file into an executable test.
+func (e *Engine) singularTest(st *parser.SingularTest) *dataTest {
+	ctx := starctx.NewContext(
+		starctx.BuildConfigDict(st.Name, "", "", "", "", nil, nil),
+		e.environment,
+		e.target,
+		nil,
+		starctx.WithMacroRegistry(e.macroRegistry),
+		starctx.WithClock(e.clock),
+		starctx.WithIntrospection(e.introspection),
+		starctx.WithVars(e.starlarkVars),
+	)
+
+	sql, err := template.RenderString(st.SQL, st.FilePath, ctx)
+	return &dataTest{
+		name:     st.Name,
+		severity: st.Severity,
+		sql:      sql,
+		err:      err,
+	}
+}
+
+// executeTest runs a test query, counting failing rows and capturing a sample.
+func (e *Engine) executeTest(ctx context.Context, t *dataTest) *state.TestResult {
+	result := &state.TestResult{
+		TestName:   t.name,
+		ModelPath:  t.modelPath,
+		Severity:   t.severity,
+		ExecutedAt: time.Now().UTC(),
+	}
+	defer func() {
+		result.ExecutionMS = time.Since(result.ExecutedAt).Milliseconds()
+	}()
+
+	if t.err != nil {
+		result.Status = state.TestStatusError
+		result.Error = t.err.Error()
+		return result
+	}
+
+	query := strings.TrimRight(strings.TrimSpace(t.sql), ";")
+
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS failures", query))
+	if err != nil {
+		result.Status = state.TestStatusError
+		result.Error = err.Error()
+		return result
+	}
+	if rows.Next() {
+		err = rows.Scan(&result.Failures)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		result.Status = state.TestStatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	if result.Failures == 0 {
+		result.Status = state.TestStatusPass
+		return result
+	}
+
+	if t.severity == parser.SeverityWarn {
+		result.Status = state.TestStatusWarn
+	} else {
+		result.Status = state.TestStatusFail
+	}
+
+	sample, err := e.querySample(ctx, fmt.Sprintf("SELECT * FROM (%s) AS failures LIMIT %d", query, testSampleLimit))
+	if err == nil {
+		result.Sample = sample
+	}
+
+	return result
+}
+
+// querySample runs a query and returns its rows as column-name keyed maps.
+func (e *Engine) querySample(ctx context.Context, query string) ([]map[string]any, error) {
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var sample []map[string]any
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			row[col] = sampleValue(values[i])
+		}
+		sample = append(sample, row)
+	}
+
+	return sample, rows.Err()
+}
+
+// sampleValue converts a scanned value into one that serializes cleanly to JSON.
+func sampleValue(v any) any {
+	switch val := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return val
+	default:
+		return formatValue(val)
+	}
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/state"
+)
+
+// setupTestProject writes a small project with models, seeds, macros and
+// singular tests, then returns an engine that has run all models.
+func setupTestProject(t *testing.T, files map[string]string) *Engine {
+	t.Helper()
+	return setupTestProjectWithConfig(t, files, nil)
+}
+
+// writeTestFiles writes files, keyed by relative path, to a temporary
+// directory and returns it.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return tmpDir
+}
+
+// setupTestProjectWithConfig is like setupTestProject but lets the caller
+// adjust the engine config before the engine is created.
+func setupTestProjectWithConfig(t *testing.T, files map[string]string, configure func(cfg *Config, dir string)) *Engine {
+	t.Helper()
+	tmpDir := writeTestFiles(t, files)
+
+	cfg := Config{
+		ModelsDir: filepath.Join(tmpDir, "models"),
+		SeedsDir:  filepath.Join(tmpDir, "seeds"),
+		MacrosDir: filepath.Join(tmpDir, "macros"),
+		TestsDir:  filepath.Join(tmpDir, "tests"),
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	}
+	if configure != nil {
+		configure(&cfg, tmpDir)
+	}
+
+	engine, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+
+	ctx := context.Background()
+	if err := engine.LoadSeeds(ctx); err != nil {
+		t.Fatalf("LoadSeeds() failed: %v", err)
+	}
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	run, err := engine.Run(ctx, "dev")
+	if err != nil || run.Status != state.RunStatusCompleted {
+		t.Fatalf("Run() failed: %v (%s)", err, run.Error)
+	}
+
+	return engine
+}
+
+func resultsByName(results []*state.TestResult) map[string]*state.TestResult {
+	byName := make(map[string]*state.TestResult, len(results))
+	for _, r := range results {
+		byName[r.TestName] = r
+	}
+	return byName
+}
+
+func TestEngine_Test_BuiltinTests(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"seeds/raw_customers.csv": "id,status\n1,active\n2,churned\n2,active\n3,\n",
+		"models/customers.sql": `/*---
+tests:
+  - unique: [id]
+  - not_null: [status]
+    severity: warn
+  - accepted_values:
+      column: status
+      values: [active, churned]
+---*/
+SELECT id, status FROM raw_customers`,
+	})
+
+	run, results, err := engine.Test(context.Background(), "dev", nil)
+	if err != nil {
+		t.Fatalf("Test() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	byName := resultsByName(results)
+
+	unique := byName["unique(id)"]
+	if unique == nil || unique.Status != state.TestStatusFail || unique.Failures != 1 {
+		t.Errorf("unique(id) = %+v, want fail with 1 failure", unique)
+	}
+	if unique != nil && len(unique.Sample) != 1 {
+		t.Errorf("unique(id) sample = %v, want 1 row", unique.Sample)
+	}
+
+	notNull := byName["not_null(status)"]
+	if notNull == nil || notNull.Status != state.TestStatusWarn || notNull.Failures != 1 {
+		t.Errorf("not_null(status) = %+v, want warn with 1 failure", notNull)
+	}
+
+	accepted := byName["accepted_values(status)"]
+	if accepted == nil || accepted.Status != state.TestStatusPass {
+		t.Errorf("accepted_values(status) = %+v, want pass", accepted)
+	}
+
+	if run.Status != state.RunStatusFailed {
+		t.Errorf("run status = %q, want failed", run.Status)
+	}
+
+	// Results and samples are persisted
+	stored, err := engine.store.GetTestResultsForRun(run.ID)
+	if err != nil {
+		t.Fatalf("GetTestResultsForRun() failed: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Errorf("expected 3 stored results, got %d", len(stored))
+	}
+	if s := resultsByName(stored)["unique(id)"]; s == nil || len(s.Sample) != 1 {
+		t.Errorf("stored unique(id) sample missing: %+v", s)
+	}
+}
+
+func TestEngine_Test_GenericAndSingular(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"seeds/raw_customers.csv": "id\n1\n2\n",
+		"seeds/raw_orders.csv":    "order_id,customer_id\n10,1\n11,2\n12,9\n",
+		"macros/schema_tests.star": `
+def test_relationships(model, column, to, field):
+    return "SELECT {c} FROM {m} WHERE {c} IS NOT NULL AND {c} NOT IN (SELECT {f} FROM {t})".format(
+        c = column, m = model, f = field, t = to)
+`,
+		"models/staging/stg_customers.sql": "SELECT id AS customer_id FROM raw_customers",
+		"models/staging/stg_orders.sql": `/*---
+tests:
+  - relationships: {column: customer_id, to: staging.stg_customers, field: customer_id}
+    severity: warn
+---*/
+SELECT order_id, customer_id FROM raw_orders`,
+		"tests/no_negative_ids.sql": "SELECT * FROM staging.stg_orders WHERE order_id < 0",
+		"tests/orders_exist.sql": `/*---
+severity: error
+---*/
+SELECT 1 AS missing WHERE (SELECT COUNT(*) FROM staging.stg_orders) = {{ 0 }}`,
+	})
+
+	run, results, err := engine.Test(context.Background(), "dev", nil)
+	if err != nil {
+		t.Fatalf("Test() failed: %v", err)
+	}
+
+	byName := resultsByName(results)
+
+	rel := byName["relationships(customer_id)"]
+	if rel == nil {
+		t.Fatalf("missing relationships result, got %v", results)
+	}
+	if rel.Status != state.TestStatusWarn || rel.Failures != 1 {
+		t.Errorf("relationships = %+v, want warn with 1 failure", rel)
+	}
+	if rel.ModelPath != "staging.stg_orders" {
+		t.Errorf("relationships model path = %q", rel.ModelPath)
+	}
+
+	for _, name := range []string{"no_negative_ids", "orders_exist"} {
+		r := byName[name]
+		if r == nil || r.Status != state.TestStatusPass {
+			t.Errorf("%s = %+v, want pass", name, r)
+		}
+	}
+
+	if run.Status != state.RunStatusCompleted {
+		t.Errorf("run status = %q, want completed (only warnings). Error: %s", run.Status, run.Error)
+	}
+}
+
+func TestEngine_Test_UnknownGenericTest(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"seeds/raw.csv": "id\n1\n",
+		"models/m.sql": `/*---
+tests:
+  - does_not_exist: id
+---*/
+SELECT id FROM raw`,
+	})
+
+	run, results, err := engine.Test(context.Background(), "dev", []string{"m"})
+	if err != nil {
+		t.Fatalf("Test() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != state.TestStatusError {
+		t.Fatalf("expected a single error result, got %+v", results)
+	}
+	if run.Status != state.RunStatusFailed {
+		t.Errorf("run status = %q, want failed", run.Status)
+	}
+}
@@ -0,0 +1,364 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/adapter"
+	"github.com/leapstack-labs/leapsql/internal/parser"
+)
+
+// UnitTestResult is the outcome of a single model unit test.
+type UnitTestResult struct {
+	ModelPath string
+	Name      string
+	Passed    bool
+	// Missing contains expected rows that the model did not produce
+	Missing []map[string]string
+	// Unexpected contains produced rows that were not expected
+	Unexpected []map[string]string
+	// Error is set when the test could not be executed
+	Error string
+}
+
+// UnitTest runs the unit tests declared next to each model (or only
+// modelPaths when non-empty). Each test case runs in its own in-memory
+// DuckDB: the model's inputs are replaced with fixture tables and its
+// rendered SQL is executed and compared with the expected rows. Models are
+// rendered without run_query() and adapter access.
+func (e *Engine) UnitTest(ctx context.Context, modelPaths []string) ([]*UnitTestResult, error) {
+	paths := modelPaths
+	if len(paths) == 0 {
+		for path := range e.models {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+
+	var results []*UnitTestResult
+	for _, path := range paths {
+		m, ok := e.models[path]
+		if !ok {
+			return nil, fmt.Errorf("model not found: %s", path)
+		}
+
+		cases, err := parser.LoadUnitTests(m.FilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tc := range cases {
+			result := &UnitTestResult{ModelPath: m.Path, Name: tc.Name}
+			if err := e.runUnitTest(ctx, m, tc, result); err != nil {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// runUnitTest executes one unit test case and fills in the result diff.
+func (e *Engine) runUnitTest(ctx context.Context, m *parser.ModelConfig, tc parser.UnitTestCase, result *UnitTestResult) error {
+	fixtures, err := e.bindFixtures(m, tc)
+	if err != nil {
+		return err
+	}
+
+	db := adapter.NewDuckDBAdapter()
+	if err := db.Connect(ctx, adapter.Config{Path: ":memory:"}); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for table, key := range fixtures {
+		if err := createFixtureTable(ctx, db, table, tc.Given[key], tc.Columns[key]); err != nil {
+			return fmt.Errorf("fixture %s: %w", table, err)
+		}
+	}
+
+	sql, err := e.modelSQL(m, nil, e.newExecutionContext(m, nil))
+	if err != nil {
+		return err
+	}
+	query := strings.TrimRight(strings.TrimSpace(sql), ";")
+	rows, err := db.Query(ctx, fmt.Sprintf("SELECT * FROM (%s) AS actual", query))
+	if err != nil {
+		return fmt.Errorf("failed to execute model: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var actual []map[string]any
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			row[col] = values[i]
+		}
+		actual = append(actual, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	result.Missing, result.Unexpected = diffRows(tc.Expect, actual)
+	result.Passed = len(result.Missing) == 0 && len(result.Unexpected) == 0
+	return nil
+}
+
+// bindFixtures maps each table referenced by the model to its fixture key.
+// A fixture key matches a reference when it is written the same way or when
+// both resolve to the same model through the registry. Every input must have
+// exactly one fixture so unit tests never read real data.
+func (e *Engine) bindFixtures(m *parser.ModelConfig, tc parser.UnitTestCase) (map[string]string, error) {
+	fixtures := make(map[string]string, len(m.Sources))
+	used := make(map[string]bool, len(tc.Given))
+
+	for _, ref := range m.Sources {
+		refPath, refIsModel := e.registry.Resolve(ref)
+
+		var matches []string
+		for key := range tc.Given {
+			if strings.EqualFold(key, ref) {
+				matches = append(matches, key)
+			} else if keyPath, ok := e.registry.Resolve(key); ok && refIsModel && keyPath == refPath {
+				matches = append(matches, key)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no fixture for input %q", ref)
+		case 1:
+		default:
+			sort.Strings(matches)
+			return nil, fmt.Errorf("fixtures %s all match input %q; give it one", strings.Join(matches, ", "), ref)
+		}
+		matched := matches[0]
+
+		fixtures[ref] = matched
+		used[matched] = true
+	}
+
+	for key := range tc.Given {
+		if !used[key] {
+			return nil, fmt.Errorf("fixture %q does not match any input of %s", key, m.Path)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// createFixtureTable creates a table from fixture rows. With declared
+// columns, the table has those columns and types and the rows are inserted
+// into it; otherwise it is built from a VALUES list whose columns are the
+// union of all row keys. Missing values are NULL.
+func createFixtureTable(ctx context.Context, db adapter.Adapter, table string, rows []map[string]any, types map[string]string) error {
+	declared := len(types) > 0
+	var cols []string
+	seen := make(map[string]bool)
+	for name := range types {
+		seen[name] = true
+		cols = append(cols, name)
+	}
+	sort.Strings(cols)
+	for _, row := range rows {
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if seen[k] {
+				continue
+			}
+			if declared {
+				return fmt.Errorf("column %q is not declared under columns", k)
+			}
+			seen[k] = true
+			cols = append(cols, k)
+		}
+	}
+
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = quoteIdent(col)
+	}
+
+	tuples := make([]string, len(rows))
+	for i, row := range rows {
+		values := make([]string, len(cols))
+		for j, col := range cols {
+			values[j] = sqlLiteral(row[col])
+		}
+		tuples[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+
+	if schema, _, ok := strings.Cut(table, "."); ok {
+		if err := db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)); err != nil {
+			return err
+		}
+	}
+
+	if !declared {
+		return db.Exec(ctx, fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM (VALUES %s) AS t(%s)",
+			table, strings.Join(tuples, ", "), strings.Join(quoted, ", ")))
+	}
+
+	defs := make([]string, len(cols))
+	for i, col := range cols {
+		defs[i] = quoted[i] + " " + types[col]
+	}
+	if err := db.Exec(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		table, strings.Join(quoted, ", "), strings.Join(tuples, ", ")))
+}
+
+// sqlLiteral renders a fixture value as a SQL literal.
+func sqlLiteral(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int64, uint64, float64:
+		return fmt.Sprint(val)
+	case time.Time:
+		return "'" + formatValue(val) + "'"
+	default:
+		return "'" + strings.ReplaceAll(fmt.Sprint(val), "'", "''") + "'"
+	}
+}
+
+// diffRows compares expected and actual rows as multisets, projecting actual
+// rows onto the columns named in the expected rows.
+func diffRows(expected, actual []map[string]any) (missing, unexpected []map[string]string) {
+	var cols []string
+	seen := make(map[string]bool)
+	for _, row := range expected {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				cols = append(cols, k)
+			}
+		}
+	}
+	sort.Strings(cols)
+
+	project := func(row map[string]any) map[string]string {
+		out := make(map[string]string, len(cols))
+		for _, col := range cols {
+			out[col] = formatValue(row[col])
+		}
+		return out
+	}
+	key := func(row map[string]string) string {
+		parts := make([]string, len(cols))
+		for i, col := range cols {
+			parts[i] = col + "=" + row[col]
+		}
+		return strings.Join(parts, "\x00")
+	}
+
+	remaining := make(map[string][]map[string]string)
+	for _, row := range actual {
+		p := project(row)
+		k := key(p)
+		remaining[k] = append(remaining[k], p)
+	}
+
+	for _, row := range expected {
+		p := project(row)
+		k := key(p)
+		if len(remaining[k]) > 0 {
+			remaining[k] = remaining[k][1:]
+			continue
+		}
+		missing = append(missing, p)
+	}
+
+	keys := make([]string, 0, len(remaining))
+	for k := range remaining {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		unexpected = append(unexpected, remaining[k]...)
+	}
+
+	return missing, unexpected
+}
+
+// formatValue normalizes a value for comparison so that, for example, a YAML
+// integer 10 matches a DuckDB DOUBLE 10.0 or DECIMAL 10.00.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.FormatInt(int64(val), 10)
+	case int8:
+		return strconv.FormatInt(int64(val), 10)
+	case int16:
+		return strconv.FormatInt(int64(val), 10)
+	case int32:
+		return strconv.FormatInt(int64(val), 10)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint8:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 64)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case *big.Int:
+		return val.String()
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02")
+		}
+		return val.Format("2006-01-02 15:04:05")
+	default:
+		if f, ok := adapter.Float64(val); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newUnitTestEngine writes a project and returns an engine that has
+// discovered its models without running them.
+func newUnitTestEngine(t *testing.T, files map[string]string) *Engine {
+	t.Helper()
+	tmpDir := writeTestFiles(t, files)
+
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(tmpDir, "models"),
+		StatePath: filepath.Join(tmpDir, "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	return engine
+}
+
+func TestEngine_UnitTest_Testdata(t *testing.T) {
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(testdataDir(), "models"),
+		MacrosDir: filepath.Join(testdataDir(), "macros"),
+		StatePath: filepath.Join(t.TempDir(), "state.db"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer engine.Close()
+
+	if err := engine.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+
+	results, err := engine.UnitTest(context.Background(), []string{"marts.customer_summary"})
+	if err != nil {
+		t.Fatalf("UnitTest() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if r := results[0]; !r.Passed {
+		t.Errorf("expected pass, got error=%q missing=%v unexpected=%v", r.Error, r.Missing, r.Unexpected)
+	}
+}
+
+func TestEngine_UnitTest_Diff(t *testing.T) {
+	engine := newUnitTestEngine(t, map[string]string{
+		"models/staging/stg_orders.sql": "SELECT id AS order_id, amount * 2 AS doubled FROM raw_orders WHERE amount > 0",
+		"models/staging/stg_orders.unit.yaml": `tests:
+  - name: doubles_positive_amounts
+    given:
+      raw_orders:
+        - {id: 1, amount: 5}
+        - {id: 2, amount: -1}
+    expect:
+      - {order_id: 1, doubled: 10}
+  - name: wrong_expectation
+    given:
+      raw_orders:
+        - {id: 1, amount: 5}
+    expect:
+      - {order_id: 1, doubled: 11}
+  - name: missing_fixture
+    given:
+      other_table:
+        - {id: 1}
+    expect: []
+`,
+	})
+
+	results, err := engine.UnitTest(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("UnitTest() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	byName := make(map[string]*UnitTestResult)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	if r := byName["doubles_positive_amounts"]; !r.Passed {
+		t.Errorf("doubles_positive_amounts should pass: error=%q missing=%v unexpected=%v", r.Error, r.Missing, r.Unexpected)
+	}
+
+	wrong := byName["wrong_expectation"]
+	if wrong.Passed || wrong.Error != "" {
+		t.Fatalf("wrong_expectation should fail with a diff, got %+v", wrong)
+	}
+	if len(wrong.Missing) != 1 || wrong.Missing[0]["doubled"] != "11" {
+		t.Errorf("unexpected missing rows: %v", wrong.Missing)
+	}
+	if len(wrong.Unexpected) != 1 || wrong.Unexpected[0]["doubled"] != "10" {
+		t.Errorf("unexpected extra rows: %v", wrong.Unexpected)
+	}
+
+	if r := byName["missing_fixture"]; r.Error == "" {
+		t.Error("missing_fixture should report an error")
+	}
+}
+
+func TestEngine_UnitTest_EmptyFixture(t *testing.T) {
+	engine := newUnitTestEngine(t, map[string]string{
+		"models/staging/stg_orders.sql": "SELECT count(*) AS orders, coalesce(sum(amount), 0) AS total FROM raw_orders WHERE upper(status) = 'PAID'",
+		"models/staging/stg_orders.unit.yaml": `tests:
+  - name: no_orders
+    given:
+      raw_orders: []
+    columns:
+      raw_orders: {amount: "DECIMAL(10, 2)", status: VARCHAR}
+    expect:
+      - {orders: 0, total: 0}
+  - name: typed_rows
+    given:
+      raw_orders:
+        - {amount: 5, status: paid}
+        - {amount: 7}
+    columns:
+      raw_orders: {amount: "DECIMAL(10, 2)", status: VARCHAR}
+    expect:
+      - {orders: 1, total: 5}
+`,
+	})
+
+	results, err := engine.UnitTest(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("UnitTest() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.Passed {
+			t.Errorf("%s should pass: error=%q missing=%v unexpected=%v", r.Name, r.Error, r.Missing, r.Unexpected)
+		}
+	}
+}
+
+func TestEngine_UnitTest_Fixtures(t *testing.T) {
+	engine := newUnitTestEngine(t, map[string]string{
+		"models/staging/stg_orders.sql": "SELECT 1 AS id",
+		"models/marts/report.sql":       `SELECT "order id", "select" FROM staging.stg_orders`,
+		"models/marts/report.unit.yaml": `tests:
+  - name: quoted_columns
+    given:
+      staging.stg_orders:
+        - {"order id": 1, select: a}
+    expect:
+      - {"order id": 1, select: a}
+  - name: quoted_declared_columns
+    given:
+      staging.stg_orders: []
+    columns:
+      staging.stg_orders: {"order id": INTEGER, select: VARCHAR}
+    expect: []
+  - name: ambiguous_fixtures
+    given:
+      staging.stg_orders:
+        - {"order id": 1, select: a}
+      stg_orders:
+        - {"order id": 2, select: b}
+    expect: []
+`,
+	})
+
+	results, err := engine.UnitTest(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("UnitTest() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results[:2] {
+		if !r.Passed {
+			t.Errorf("%s should pass: error=%q missing=%v unexpected=%v", r.Name, r.Error, r.Missing, r.Unexpected)
+		}
+	}
+	if r := results[2]; !strings.Contains(r.Error, "staging.stg_orders, stg_orders") {
+		t.Errorf("ambiguous_fixtures error = %q, want both fixtures named", r.Error)
+	}
+}
+
+func TestEngine_UnitTest_KeepsIntrospection(t *testing.T) {
+	engine := setupTestProject(t, map[string]string{
+		"models/staging/stg_orders.sql": "SELECT 1 AS id",
+	})
+	introspection := engine.introspection
+	if introspection == nil {
+		t.Fatal("Run() should set up introspection")
+	}
+	if _, err := engine.UnitTest(context.Background(), nil); err != nil {
+		t.Fatalf("UnitTest() failed: %v", err)
+	}
+	if engine.introspection != introspection {
+		t.Error("UnitTest() changed the introspection later runs use")
+	}
+}
+
+func TestDiffRows(t *testing.T) {
+	expected := []map[string]any{{"id": 1}, {"id": 1}, {"id": 2}}
+	actual := []map[string]any{{"id": int32(1), "x": "ignored"}, {"id": 2.0}, {"id": int64(3)}}
+
+	missing, unexpected := diffRows(expected, actual)
+	if len(missing) != 1 || missing[0]["id"] != "1" {
+		t.Errorf("missing = %v, want one row with id 1", missing)
+	}
+	if len(unexpected) != 1 || unexpected[0]["id"] != "3" {
+		t.Errorf("unexpected = %v, want one row with id 3", unexpected)
+	}
+}
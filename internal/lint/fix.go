@@ -0,0 +1,38 @@
+package lint
+
+import "sort"
+
+// ApplyFixes applies the fixes of findings to the content of the file they
+// were found in, and returns the new content and the findings left unfixed.
+// A fix whose edits overlap those of an earlier fix is skipped; it is found
+// again on the next run.
+func ApplyFixes(content string, findings []Finding) (string, []Finding) {
+	var edits []Edit
+	var unfixed []Finding
+	for _, f := range findings {
+		if f.Fix == nil || overlaps(edits, f.Fix.Edits) {
+			unfixed = append(unfixed, f)
+			continue
+		}
+		edits = append(edits, f.Fix.Edits...)
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Start > edits[j].Start })
+	for _, e := range edits {
+		content = content[:e.Start] + e.Text + content[e.End:]
+	}
+	return content, unfixed
+}
+
+// overlaps reports whether any of the new edits touch the same bytes as
+// the existing ones.
+func overlaps(existing, edits []Edit) bool {
+	for _, a := range existing {
+		for _, b := range edits {
+			if a.Start < b.End && b.Start < a.End || a.Start == b.Start {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+// Package lint checks the SQL of models against configurable rules.
+//
+// Rules inspect the lineage AST of a model's rendered SQL and report
+// findings, some with a fix. Findings point at lines of the model file, and
+// fixes edit the file only where it holds the same text as the rendered SQL,
+// so SQL produced by templates is never rewritten.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+// Severity is how a finding is reported; findings of rules that are off
+// aren't reported at all.
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// valid reports whether s is a known severity.
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return true
+	}
+	return false
+}
+
+// Rule is a check run on the SQL of each model.
+type Rule struct {
+	Name        string
+	Description string
+	// Severity applies unless the configuration sets another
+	Severity Severity
+	Check    func(c *Context)
+}
+
+// Finding is a problem a rule found in a model.
+type Finding struct {
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+	Model     string   `json:"model"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndLine   int      `json:"end_line"`
+	EndColumn int      `json:"end_column"`
+	Fix       *Fix     `json:"fix,omitempty"`
+}
+
+// String formats a finding as file:line:column: severity: message [rule].
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", f.File, f.Line, f.Column, f.Severity, f.Message, f.Rule)
+}
+
+// Fix is a change to the model file that resolves a finding.
+type Fix struct {
+	Description string `json:"description"`
+	Edits       []Edit `json:"edits"`
+}
+
+// Edit replaces the bytes Start to End of a file with Text.
+type Edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// Config enables rules and sets their severity, for all models and per
+// directory of the models directory.
+//
+//	lint:
+//	  rules:
+//	    union-distinct: off
+//	  directories:
+//	    marts:
+//	      select-star: error
+type Config struct {
+	Rules map[string]Severity `yaml:"rules"`
+	// Directories override Rules for the models below them; the longest
+	// matching directory wins
+	Directories map[string]map[string]Severity `yaml:"directories"`
+}
+
+// Validate checks that the configuration names known rules and severities.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	check := func(where string, rules map[string]Severity) error {
+		for name, severity := range rules {
+			if findRule(name) == nil {
+				return fmt.Errorf("unknown lint rule %q in %s", name, where)
+			}
+			if !severity.valid() {
+				return fmt.Errorf("invalid severity %q for lint rule %s in %s: expected off, info, warning or error", severity, name, where)
+			}
+		}
+		return nil
+	}
+	if err := check("lint.rules", c.Rules); err != nil {
+		return err
+	}
+	for dir, rules := range c.Directories {
+		if err := check("lint.directories."+dir, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Severity returns the severity of a rule for a model path such as
+// "marts.finance.revenue".
+func (c *Config) Severity(rule *Rule, modelPath string) Severity {
+	severity := rule.Severity
+	if c == nil {
+		return severity
+	}
+	if s, ok := c.Rules[rule.Name]; ok {
+		severity = s
+	}
+
+	dir := ""
+	if i := strings.LastIndexByte(modelPath, '.'); i >= 0 {
+		dir = strings.ReplaceAll(modelPath[:i], ".", "/")
+	}
+	best := -1
+	for d, rules := range c.Directories {
+		d = strings.Trim(d, "/")
+		if d != dir && !strings.HasPrefix(dir, d+"/") || len(d) <= best {
+			continue
+		}
+		if s, ok := rules[rule.Name]; ok {
+			severity, best = s, len(d)
+		}
+	}
+	return severity
+}
+
+// Linter checks models with the rules enabled by a configuration.
+type Linter struct {
+	config  *Config
+	dialect *lineage.Dialect
+}
+
+// New creates a linter that parses SQL in the given dialect.
+func New(config *Config, dialect *lineage.Dialect) *Linter {
+	if dialect == nil {
+		dialect = lineage.DefaultDialect()
+	}
+	return &Linter{config: config, dialect: dialect}
+}
+
+// syntaxRule reports SQL the parser can't read. It isn't in Rules: it
+// always reports errors, and no other rule can check such a model.
+var syntaxRule = &Rule{
+	Name:        "syntax-error",
+	Description: "SQL that doesn't parse",
+	Severity:    SeverityError,
+}
+
+// Lint checks the rendered SQL of a model. position maps a position in the
+// rendered SQL to a line and column of the model file. SQL that doesn't
+// parse is reported as a syntax-error finding for each error.
+func (l *Linter) Lint(m *parser.ModelConfig, sql string, position func(lineage.Position) (int, int)) ([]Finding, error) {
+	stmt, err := lineage.ParseWithDialect(sql, l.dialect)
+	if err != nil {
+		var errs lineage.ParseErrors
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+		var findings []Finding
+		c := &Context{
+			Model:    m,
+			SQL:      sql,
+			Dialect:  l.dialect,
+			rule:     syntaxRule,
+			severity: syntaxRule.Severity,
+			position: position,
+			findings: &findings,
+		}
+		for _, e := range errs {
+			c.Report(lineage.Span{Start: e.Pos, End: e.Pos}, e.Message)
+		}
+		return findings, nil
+	}
+
+	var findings []Finding
+	for _, rule := range Rules {
+		severity := l.config.Severity(rule, m.Path)
+		if severity == SeverityOff {
+			continue
+		}
+		rule.Check(&Context{
+			Model:    m,
+			SQL:      sql,
+			Stmt:     stmt,
+			Dialect:  l.dialect,
+			rule:     rule,
+			severity: severity,
+			position: position,
+			findings: &findings,
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings, nil
+}
+
+// Context is what a rule checks, and where it reports findings.
+type Context struct {
+	Model   *parser.ModelConfig
+	SQL     string // rendered SQL of the model
+	Stmt    *lineage.SelectStmt
+	Dialect *lineage.Dialect
+
+	rule     *Rule
+	severity Severity
+	position func(lineage.Position) (int, int)
+	findings *[]Finding
+}
+
+// Report records a finding at a span of the rendered SQL.
+func (c *Context) Report(span lineage.Span, message string) {
+	c.ReportFix(span, message, "")
+}
+
+// ReportFix records a finding with a fix that replaces spans of the rendered
+// SQL. The fix is dropped if any span doesn't map to the same text in the
+// model file.
+func (c *Context) ReportFix(span lineage.Span, message, description string, edits ...RenderedEdit) {
+	f := Finding{
+		Rule:     c.rule.Name,
+		Severity: c.severity,
+		Message:  message,
+		Model:    c.Model.Path,
+		File:     c.Model.FilePath,
+	}
+	f.Line, f.Column = c.filePosition(span.Start)
+	f.EndLine, f.EndColumn = c.filePosition(span.End)
+
+	if len(edits) > 0 {
+		fix := &Fix{Description: description}
+		for _, e := range edits {
+			edit, ok := c.fileEdit(e)
+			if !ok {
+				fix = nil
+				break
+			}
+			fix.Edits = append(fix.Edits, edit)
+		}
+		f.Fix = fix
+	}
+	*c.findings = append(*c.findings, f)
+}
+
+// RenderedEdit replaces the bytes Start to End of the rendered SQL with Text.
+type RenderedEdit struct {
+	Start, End int
+	Text       string
+}
+
+// filePosition maps a position of the rendered SQL to the model file.
+func (c *Context) filePosition(pos lineage.Position) (int, int) {
+	if c.position == nil {
+		return pos.Line, pos.Column
+	}
+	return c.position(pos)
+}
+
+// fileEdit maps an edit of the rendered SQL to the model file.
+func (c *Context) fileEdit(e RenderedEdit) (Edit, bool) {
+	content := c.Model.RawContent
+	start := c.fileOffset(content, c.offsetPosition(e.Start))
+	end := c.fileOffset(content, c.offsetPosition(e.End))
+	if start < 0 || end < start || content[start:end] != c.SQL[e.Start:e.End] {
+		return Edit{}, false
+	}
+	return Edit{Start: start, End: end, Text: e.Text}, true
+}
+
+// offsetPosition returns the position of an offset in the rendered SQL.
+func (c *Context) offsetPosition(offset int) lineage.Position {
+	before := c.SQL[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndexByte(before, '\n')
+	return lineage.Position{Line: line, Column: column, Offset: offset}
+}
+
+// fileOffset returns the offset of a rendered position in the model file,
+// or -1 if it's past the end.
+func (c *Context) fileOffset(content string, pos lineage.Position) int {
+	line, column := c.filePosition(pos)
+	offset := 0
+	for ; line > 1; line-- {
+		i := strings.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	if offset+column-1 > len(content) {
+		return -1
+	}
+	return offset + column - 1
+}
+
+// findRule returns the rule with a name, or nil. The syntax-error rule
+// isn't configurable, so it isn't found.
+func findRule(name string) *Rule {
+	for _, rule := range Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+// lintSQL lints SQL as the content of a model file.
+func lintSQL(t *testing.T, config *Config, m *parser.ModelConfig, sql string) []Finding {
+	t.Helper()
+	if m.Path == "" {
+		m.Path = "staging.model"
+	}
+	m.FilePath = "models/model.sql"
+	m.RawContent = sql
+	findings, err := New(config, nil).Lint(m, sql, nil)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	return findings
+}
+
+// rules returns the rules of findings.
+func rules(findings []Finding) []string {
+	var names []string
+	for _, f := range findings {
+		names = append(names, f.Rule)
+	}
+	return names
+}
+
+func TestLint_Rules(t *testing.T) {
+	all := &Config{Rules: map[string]Severity{"select-star": SeverityWarning}}
+	tests := []struct {
+		name         string
+		sql          string
+		materialized string
+		want         []string
+	}{
+		{"select star", "SELECT * FROM t", "", []string{"select-star"}},
+		{"select star in CTE", "WITH c AS (SELECT * FROM t) SELECT a FROM c", "", nil},
+		{"unqualified column", "SELECT o.id, amount FROM o JOIN c ON o.cid = c.id", "", []string{"unqualified-column"}},
+		{"using column", "SELECT id, o.amount FROM o JOIN c USING (id)", "", nil},
+		{"alias reference", "SELECT o.id AS k FROM o JOIN c ON o.cid = c.id ORDER BY k", "", nil},
+		{"comma join", "SELECT a.x, b.y FROM a, b", "", []string{"implicit-cross-join"}},
+		{"union", "SELECT a FROM t UNION SELECT a FROM u", "", []string{"union-distinct"}},
+		{"union all", "SELECT a FROM t UNION ALL SELECT a FROM u", "", nil},
+		{"union distinct", "SELECT a FROM t UNION DISTINCT SELECT a FROM u", "", nil},
+		{"unused CTE", "WITH a AS (SELECT 1 AS x), b AS (SELECT x FROM a) SELECT x FROM a", "", []string{"unused-cte"}},
+		{"CTE used by CTE", "WITH a AS (SELECT 1 AS x), b AS (SELECT x FROM a) SELECT x FROM b", "", nil},
+		{"generator in incremental", "SELECT id, now() AS loaded_at, CURRENT_DATE AS d FROM t", "incremental", []string{"non-deterministic-incremental", "non-deterministic-incremental"}},
+		{"generator in table", "SELECT id, now() AS loaded_at FROM t", "table", nil},
+		{"duplicate output column", "SELECT a, b AS a FROM t", "", []string{"ambiguous-alias"}},
+		{"alias shadows column", "SELECT upper(name) AS name FROM t GROUP BY name", "", []string{"ambiguous-alias"}},
+		{"alias of same column", "SELECT name AS name FROM t GROUP BY name", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(lintSQL(t, all, &parser.ModelConfig{Materialized: tt.materialized}, tt.sql))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Severity(t *testing.T) {
+	config := &Config{
+		Rules: map[string]Severity{"union-distinct": SeverityOff},
+		Directories: map[string]map[string]Severity{
+			"marts":         {"select-star": SeverityWarning, "union-distinct": SeverityWarning},
+			"marts/finance": {"select-star": SeverityError},
+		},
+	}
+	star, union := findRule("select-star"), findRule("union-distinct")
+	tests := []struct {
+		rule *Rule
+		path string
+		want Severity
+	}{
+		{star, "staging.orders", SeverityOff},
+		{star, "marts.orders", SeverityWarning},
+		{star, "marts.finance.revenue", SeverityError},
+		{star, "marts_old.orders", SeverityOff},
+		{union, "staging.orders", SeverityOff},
+		{union, "marts.finance.revenue", SeverityWarning},
+	}
+	for _, tt := range tests {
+		if got := config.Severity(tt.rule, tt.path); got != tt.want {
+			t.Errorf("Severity(%s, %s) = %s, want %s", tt.rule.Name, tt.path, got, tt.want)
+		}
+	}
+
+	if err := (&Config{Rules: map[string]Severity{"no-such-rule": SeverityError}}).Validate(); err == nil {
+		t.Error("Validate() should reject an unknown rule")
+	}
+	if err := (&Config{Directories: map[string]map[string]Severity{"marts": {"select-star": "fatal"}}}).Validate(); err == nil {
+		t.Error("Validate() should reject an unknown severity")
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT a.x, b.y FROM a, b",
+			"SELECT a.x, b.y FROM a CROSS JOIN b",
+		},
+		{
+			"WITH unused AS (SELECT 1)\nSELECT x FROM t",
+			"SELECT x FROM t",
+		},
+		{
+			"WITH a AS (SELECT 1 AS x), b AS (SELECT 2 AS x), c AS (SELECT 3 AS x) SELECT x FROM b",
+			"WITH b AS (SELECT 2 AS x) SELECT x FROM b",
+		},
+	}
+	for _, tt := range tests {
+		findings := lintSQL(t, nil, &parser.ModelConfig{}, tt.sql)
+		got, unfixed := ApplyFixes(tt.sql, findings)
+		if got != tt.want {
+			t.Errorf("ApplyFixes(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+		if len(unfixed) != 0 {
+			t.Errorf("ApplyFixes(%q) left %v unfixed", tt.sql, rules(unfixed))
+		}
+	}
+
+	// SQL a template generated can't be fixed in the file
+	m := &parser.ModelConfig{Path: "staging.model", FilePath: "model.sql", RawContent: "SELECT a.x, b.y FROM {{ tables }}"}
+	findings, err := New(nil, nil).Lint(m, "SELECT a.x, b.y FROM a, b", nil)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Fix != nil {
+		t.Errorf("expected a finding without a fix, got %+v", findings)
+	}
+}
+
+func TestLint_SyntaxErrors(t *testing.T) {
+	m := &parser.ModelConfig{Path: "staging.model", FilePath: "models/model.sql"}
+	sql := "SELECT a,\n  (b * ) AS c,\n  d + AS e\nFROM t"
+	// The SQL starts on line 4 of the file, after frontmatter
+	position := func(pos lineage.Position) (int, int) { return pos.Line + 3, pos.Column }
+	findings, err := New(nil, nil).Lint(m, sql, position)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%s %s %d:%d", f.Rule, f.Severity, f.Line, f.Column))
+	}
+	want := []string{"syntax-error error 5:8", "syntax-error error 6:7"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, FormatSARIF, findings); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var log sarif
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if results := log.Runs[0].Results; len(results) != 2 || results[0].Level != "error" {
+		t.Errorf("unexpected SARIF results: %+v", results)
+	}
+	if rules := log.Runs[0].Tool.Driver.Rules; len(rules) != 1 || rules[0].ID != "syntax-error" {
+		t.Errorf("unexpected SARIF rules: %+v", rules)
+	}
+
+	if err := (&Config{Rules: map[string]Severity{"syntax-error": SeverityOff}}).Validate(); err == nil {
+		t.Error("Validate() should reject configuring syntax-error")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	findings := lintSQL(t, nil, &parser.ModelConfig{}, "SELECT a.x, b.y FROM a, b")
+
+	var text bytes.Buffer
+	if err := Write(&text, FormatText, findings); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := "models/model.sql:1:23: warning: "; !strings.HasPrefix(text.String(), want) {
+		t.Errorf("text output = %q, want prefix %q", text.String(), want)
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, FormatSARIF, findings); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var log sarif
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 1 || results[0].RuleID != "implicit-cross-join" || results[0].Level != "warning" ||
+		results[0].Locations[0].PhysicalLocation.Region.StartLine != 1 {
+		t.Errorf("unexpected SARIF results: %+v", results)
+	}
+	if rules := log.Runs[0].Tool.Driver.Rules; len(rules) != 1 || rules[0].ID != "implicit-cross-join" {
+		t.Errorf("unexpected SARIF rules: %+v", rules)
+	}
+
+	if err := Write(&out, "xml", findings); err == nil {
+		t.Error("Write() should reject an unknown format")
+	}
+}
@@ -0,0 +1,351 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+// Rules are the lint rules, in the order they run.
+var Rules = []*Rule{
+	{
+		Name:        "select-star",
+		Description: "SELECT * in the final query of a model, which ties its columns to those of its inputs",
+		Severity:    SeverityOff,
+		Check:       checkSelectStar,
+	},
+	{
+		Name:        "unqualified-column",
+		Description: "Column without a table qualifier in a query that joins tables",
+		Severity:    SeverityWarning,
+		Check:       checkUnqualifiedColumn,
+	},
+	{
+		Name:        "implicit-cross-join",
+		Description: "Tables joined with a comma rather than JOIN",
+		Severity:    SeverityWarning,
+		Check:       checkImplicitCrossJoin,
+	},
+	{
+		Name:        "union-distinct",
+		Description: "UNION, which removes duplicate rows, where UNION ALL may be meant",
+		Severity:    SeverityInfo,
+		Check:       checkUnionDistinct,
+	},
+	{
+		Name:        "unused-cte",
+		Description: "Common table expression that is never read",
+		Severity:    SeverityWarning,
+		Check:       checkUnusedCTE,
+	},
+	{
+		Name:        "non-deterministic-incremental",
+		Description: "Generator function such as NOW() or RANDOM() in an incremental model",
+		Severity:    SeverityWarning,
+		Check:       checkNonDeterministic,
+	},
+	{
+		Name:        "ambiguous-alias",
+		Description: "Column alias that repeats another output column or names a different input column",
+		Severity:    SeverityWarning,
+		Check:       checkAmbiguousAlias,
+	},
+}
+
+// checkSelectStar reports * in the SELECT lists of the final query.
+func checkSelectStar(c *Context) {
+	for body := c.Stmt.Body; body != nil; body = body.Right {
+		core := body.Left
+		if core.From != nil && isPivotStatement(core.From.Source) {
+			continue
+		}
+		for _, item := range core.Columns {
+			if item.Star || item.TableStar != "" {
+				c.Report(item.Span, "SELECT * makes the model's columns change with its inputs; list the columns")
+			}
+		}
+	}
+}
+
+// isPivotStatement reports whether a table is a PIVOT or UNPIVOT statement,
+// which the parser reads as SELECT * FROM (PIVOT ...).
+func isPivotStatement(ref lineage.TableRef) bool {
+	switch t := ref.(type) {
+	case *lineage.PivotTable:
+		return t.Statement
+	case *lineage.UnpivotTable:
+		return t.Statement
+	}
+	return false
+}
+
+// checkUnqualifiedColumn reports columns without a table in queries with
+// joins, apart from USING columns and references to output aliases.
+func checkUnqualifiedColumn(c *Context) {
+	resolver := lineage.NewColumnResolver(nil, c.Dialect)
+	w := &walker{core: func(core *lineage.SelectCore) {
+		if core.From == nil || len(core.From.Joins) == 0 {
+			return
+		}
+		known := make(map[string]bool)
+		for _, j := range core.From.Joins {
+			if j.Natural {
+				// Any column may be a merged one
+				return
+			}
+			for _, col := range j.Using {
+				known[c.Dialect.NormalizeName(col)] = true
+			}
+		}
+		for _, item := range core.Columns {
+			if item.Alias != "" {
+				known[c.Dialect.NormalizeName(item.Alias)] = true
+			}
+		}
+
+		for _, e := range coreExprs(core) {
+			for _, ref := range resolver.CollectColumns(e) {
+				if ref.Table != "" || len(ref.Path) > 0 || known[c.Dialect.NormalizeName(ref.Column)] || isNiladic(c.Dialect, ref.Column) {
+					continue
+				}
+				c.Report(ref.NodeSpan(), fmt.Sprintf("column %s is not qualified in a query that joins tables; write table.%s", ref.Column, ref.Column))
+			}
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
+
+// coreExprs returns the expressions of a SELECT, outside of its subqueries.
+func coreExprs(core *lineage.SelectCore) []lineage.Expr {
+	var exprs []lineage.Expr
+	for _, item := range core.Columns {
+		exprs = append(exprs, item.Expr)
+	}
+	if core.From != nil {
+		for _, j := range core.From.Joins {
+			exprs = append(exprs, j.Condition)
+		}
+	}
+	exprs = append(exprs, core.Where)
+	exprs = append(exprs, core.GroupBy...)
+	exprs = append(exprs, core.Having, core.Qualify)
+	for _, item := range core.OrderBy {
+		exprs = append(exprs, item.Expr)
+	}
+	return exprs
+}
+
+// isNiladic reports whether an unqualified name is a generator written
+// without parentheses, such as CURRENT_TIMESTAMP.
+func isNiladic(dialect *lineage.Dialect, name string) bool {
+	upper := strings.ToUpper(name)
+	return (strings.HasPrefix(upper, "CURRENT_") || strings.HasPrefix(upper, "LOCALTIME")) && dialect.IsGenerator(name)
+}
+
+// checkImplicitCrossJoin reports comma joins, with a fix that writes them as
+// CROSS JOIN.
+func checkImplicitCrossJoin(c *Context) {
+	w := &walker{core: func(core *lineage.SelectCore) {
+		if core.From == nil {
+			return
+		}
+		prev := core.From.Source.NodeSpan().End.Offset
+		for _, j := range core.From.Joins {
+			right := j.Right.NodeSpan()
+			if j.Type == lineage.JoinComma {
+				message := "tables joined with a comma; write CROSS JOIN, or JOIN ... ON with the join condition"
+				if strings.TrimSpace(c.SQL[prev:right.Start.Offset]) == "," {
+					c.ReportFix(j.Span, message, "Replace the comma with CROSS JOIN",
+						RenderedEdit{Start: prev, End: right.Start.Offset, Text: " CROSS JOIN "})
+				} else {
+					c.Report(j.Span, message)
+				}
+			}
+			prev = right.End.Offset
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
+
+// checkUnionDistinct reports UNION without ALL. An explicit UNION DISTINCT
+// is taken as intended.
+func checkUnionDistinct(c *Context) {
+	var tokens []lineage.Token
+	l := lineage.NewLexerWithDialect(c.SQL, c.Dialect)
+	for tok := l.NextToken(); tok.Type != lineage.TOKEN_EOF; tok = l.NextToken() {
+		tokens = append(tokens, tok)
+	}
+
+	w := &walker{stmt: func(s *lineage.SelectStmt) {
+		for body := s.Body; body != nil; body = body.Right {
+			if body.Op != lineage.SetOpUnion || body.All || body.Right == nil {
+				continue
+			}
+			for i, tok := range tokens {
+				if tok.Pos.Offset < body.Left.Span.End.Offset || tok.Type != lineage.TOKEN_UNION {
+					continue
+				}
+				if i+1 < len(tokens) && tokens[i+1].Type == lineage.TOKEN_DISTINCT {
+					break
+				}
+				c.Report(lineage.Span{Start: tok.Pos, End: tok.End}, "UNION removes duplicate rows; write UNION ALL unless that is intended, or UNION DISTINCT if it is")
+				break
+			}
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
+
+// checkUnusedCTE reports CTEs that neither the query nor a CTE it reads
+// refers to, with a fix that removes them.
+func checkUnusedCTE(c *Context) {
+	w := &walker{stmt: func(s *lineage.SelectStmt) {
+		if s.With == nil || s.With.Recursive {
+			return
+		}
+		ctes := s.With.CTEs
+		index := make(map[string]int)
+		for i, cte := range ctes {
+			index[c.Dialect.NormalizeName(cte.Name)] = i
+		}
+
+		// Follow references from the query through the CTEs it reads
+		used := make([]bool, len(ctes))
+		var queue []int
+		refs := &walker{table: func(t *lineage.TableName) {
+			if t.Schema != "" || t.Catalog != "" {
+				return
+			}
+			if i, ok := index[c.Dialect.NormalizeName(t.Name)]; ok && !used[i] {
+				used[i] = true
+				queue = append(queue, i)
+			}
+		}}
+		for body := s.Body; body != nil; body = body.Right {
+			refs.selectCore(body.Left)
+		}
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			refs.selectStmt(ctes[i].Select)
+		}
+
+		unused := 0
+		for _, u := range used {
+			if !u {
+				unused++
+			}
+		}
+		for i, cte := range ctes {
+			if used[i] {
+				continue
+			}
+			message := fmt.Sprintf("CTE %s is never used", cte.Name)
+			description := fmt.Sprintf("Remove CTE %s", cte.Name)
+			switch {
+			case len(ctes) == 1:
+				c.ReportFix(cte.Span, message, description,
+					RenderedEdit{Start: s.With.Span.Start.Offset, End: s.Body.Span.Start.Offset})
+			case unused == len(ctes):
+				// Removing the CTEs one at a time would leave an empty WITH
+				c.Report(cte.Span, message)
+			case i < len(ctes)-1:
+				c.ReportFix(cte.Span, message, description,
+					RenderedEdit{Start: cte.Span.Start.Offset, End: ctes[i+1].Span.Start.Offset})
+			default:
+				c.ReportFix(cte.Span, message, description,
+					RenderedEdit{Start: ctes[i-1].Span.End.Offset, End: cte.Span.End.Offset})
+			}
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
+
+// checkNonDeterministic reports generator functions in incremental models,
+// where rows keep the value computed by the run that inserted them.
+func checkNonDeterministic(c *Context) {
+	if c.Model.Materialized != "incremental" {
+		return
+	}
+	report := func(e lineage.Expr, name string) {
+		c.Report(e.NodeSpan(), fmt.Sprintf("%s has no input columns and may differ between runs; rows of an incremental model keep the value of the run that inserted them", strings.ToUpper(name)))
+	}
+	w := &walker{value: func(e lineage.Expr) {
+		switch x := e.(type) {
+		case *lineage.FuncCall:
+			if c.Dialect.IsGenerator(x.Name) {
+				report(x, x.Name)
+			}
+		case *lineage.ColumnRef:
+			if x.Table == "" && len(x.Path) == 0 && isNiladic(c.Dialect, x.Column) {
+				report(x, x.Column)
+			}
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
+
+// checkAmbiguousAlias reports output columns with the same name, and aliases
+// that name an input column the query also refers to, as in
+// SELECT upper(name) AS name ... GROUP BY name.
+func checkAmbiguousAlias(c *Context) {
+	resolver := lineage.NewColumnResolver(nil, c.Dialect)
+	w := &walker{core: func(core *lineage.SelectCore) {
+		// Unqualified columns outside the SELECT list
+		referenced := make(map[string]bool)
+		var clauses []lineage.Expr
+		clauses = append(clauses, core.Where, core.Having, core.Qualify)
+		clauses = append(clauses, core.GroupBy...)
+		for _, item := range core.OrderBy {
+			clauses = append(clauses, item.Expr)
+		}
+		for _, e := range clauses {
+			for _, ref := range resolver.CollectColumns(e) {
+				if ref.Table == "" && len(ref.Path) == 0 {
+					referenced[c.Dialect.NormalizeName(ref.Column)] = true
+				}
+			}
+		}
+
+		// Names known to be input columns, read where aliases aren't visible
+		inputs := make(map[string]bool)
+		var reads []lineage.Expr
+		for _, item := range core.Columns {
+			reads = append(reads, item.Expr)
+		}
+		if core.From != nil {
+			for _, j := range core.From.Joins {
+				reads = append(reads, j.Condition)
+			}
+		}
+		for _, e := range append(reads, core.Where) {
+			for _, ref := range resolver.CollectColumns(e) {
+				if len(ref.Path) == 0 {
+					inputs[c.Dialect.NormalizeName(ref.Column)] = true
+				}
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, item := range core.Columns {
+			name := item.Alias
+			ref, isRef := item.Expr.(*lineage.ColumnRef)
+			if name == "" && isRef {
+				name = ref.Column
+			}
+			if name == "" {
+				continue
+			}
+			key := c.Dialect.NormalizeName(name)
+			switch {
+			case seen[key]:
+				c.Report(item.Span, fmt.Sprintf("output column %s appears more than once", name))
+			case item.Alias != "" && referenced[key] && inputs[key] && (!isRef || c.Dialect.NormalizeName(ref.Column) != key):
+				c.Report(item.Span, fmt.Sprintf("alias %s is also the name of an input column the query refers to, which may mean either", name))
+			}
+			seen[key] = true
+		}
+	}}
+	w.selectStmt(c.Stmt)
+}
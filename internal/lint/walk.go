@@ -0,0 +1,44 @@
+package lint
+
+import "github.com/leapstack-labs/leapsql/pkg/lineage"
+
+// walker visits the queries, table names and expressions of a statement,
+// including those in CTEs, derived tables and subqueries.
+type walker struct {
+	stmt  func(*lineage.SelectStmt)
+	core  func(*lineage.SelectCore)
+	table func(*lineage.TableName)
+	value func(lineage.Expr)
+}
+
+func (w *walker) selectStmt(s *lineage.SelectStmt) {
+	w.walk(s)
+}
+
+func (w *walker) selectCore(c *lineage.SelectCore) {
+	w.walk(c)
+}
+
+func (w *walker) walk(node lineage.Node) {
+	lineage.Inspect(node, func(n lineage.Node) bool {
+		switch x := n.(type) {
+		case *lineage.SelectStmt:
+			if w.stmt != nil {
+				w.stmt(x)
+			}
+		case *lineage.SelectCore:
+			if w.core != nil {
+				w.core(x)
+			}
+		case *lineage.TableName:
+			if w.table != nil {
+				w.table(x)
+			}
+		case lineage.Expr:
+			if w.value != nil {
+				w.value(x)
+			}
+		}
+		return true
+	})
+}
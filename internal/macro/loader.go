@@ -3,22 +3,72 @@
 package macro
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
 	"go.starlark.net/starlark"
 )
 
 // Loader scans a directory for .star files and loads them as Starlark modules.
+//
+// Files may share code with load(). A module is one of:
+//
+//	load("//macros/common.star", "fn")        relative to the project root
+//	load("@utils//macros/strings.star", "fn") inside a vendored package
+//	load("helpers/dates.star", "fn")          relative to the loading file
+//
+// Each file is executed at most once per Loader; later loads reuse its globals.
 type Loader struct {
-	dir string
+	dir       string
+	root      string
+	vendorDir string
+
+	// modules caches executed files by absolute path
+	modules map[string]*loadEntry
+	// loading is the stack of files being executed, for cycle detection
+	loading []string
+}
+
+// loadEntry is the cached result of executing a .star file.
+type loadEntry struct {
+	globals starlark.StringDict
+	err     error
+}
+
+// LoaderOption is a functional option for configuring a Loader.
+type LoaderOption func(*Loader)
+
+// WithRoot sets the directory "//" loads are resolved against.
+// Defaults to the parent of the macros directory.
+func WithRoot(root string) LoaderOption {
+	return func(l *Loader) {
+		l.root = root
+	}
+}
+
+// WithVendorDir sets the directory of installed packages used to resolve
+// package-qualified loads ("@name//path").
+func WithVendorDir(vendorDir string) LoaderOption {
+	return func(l *Loader) {
+		l.vendorDir = vendorDir
+	}
 }
 
 // NewLoader creates a new macro loader for the specified directory.
-func NewLoader(dir string) *Loader {
-	return &Loader{dir: dir}
+func NewLoader(dir string, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		dir:     dir,
+		root:    filepath.Dir(dir),
+		modules: make(map[string]*loadEntry),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // LoadedModule represents a parsed Starlark macro file.
@@ -70,17 +120,51 @@ func (l *Loader) Load() ([]*LoadedModule, error) {
 	return modules, nil
 }
 
-// loadFile loads a single .star file and extracts its exports.
-func (l *Loader) loadFile(path string) (*LoadedModule, error) {
-	// Read file content
-	content, err := os.ReadFile(path)
+// LoadPackage loads the .star files of a vendored package's macros directory
+// into a single module namespaced by the package name, so that
+// _vendor/utils/macros/strings.star exports utils.slugify. Returns nil if the
+// package has no macros. Two files exporting the same name is an error.
+//
+// Within a package, "//" loads resolve against the package root and
+// "@name//" loads against the other installed packages.
+func LoadPackage(namespace, dir string) (*LoadedModule, error) {
+	root := filepath.Dir(dir)
+	modules, err := NewLoader(dir, WithRoot(root), WithVendorDir(filepath.Dir(root))).Load()
 	if err != nil {
-		return nil, &LoadError{
-			File:    path,
-			Message: fmt.Sprintf("failed to read file: %v", err),
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, nil
+	}
+
+	if err := validateNamespace(namespace); err != nil {
+		return nil, &LoadError{File: dir, Message: err.Error()}
+	}
+
+	exports := make(starlark.StringDict)
+	from := make(map[string]string)
+	for _, module := range modules {
+		for name, value := range module.Exports {
+			if other, ok := from[name]; ok {
+				return nil, &LoadError{
+					File:    module.Path,
+					Message: fmt.Sprintf("package %s: %q is also defined in %s", namespace, name, filepath.Base(other)),
+				}
+			}
+			exports[name] = value
+			from[name] = module.Path
 		}
 	}
 
+	return &LoadedModule{
+		Namespace: namespace,
+		Path:      dir,
+		Exports:   exports,
+	}, nil
+}
+
+// loadFile loads a single .star file and extracts its exports.
+func (l *Loader) loadFile(path string) (*LoadedModule, error) {
 	// Derive namespace from filename
 	base := filepath.Base(path)
 	namespace := strings.TrimSuffix(base, ".star")
@@ -93,17 +177,15 @@ func (l *Loader) loadFile(path string) (*LoadedModule, error) {
 		}
 	}
 
-	// Create a new Starlark thread for execution
-	thread := &starlark.Thread{
-		Name: fmt.Sprintf("load:%s", namespace),
-		Print: func(_ *starlark.Thread, msg string) {
-			// Ignore prints during macro loading
-		},
-	}
-
-	// Execute the Starlark file
-	globals, err := starlark.ExecFile(thread, path, content, nil)
+	globals, err := l.exec(path, l.root)
 	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) && pathErr.Path == path {
+			return nil, &LoadError{
+				File:    path,
+				Message: fmt.Sprintf("failed to read file: %v", err),
+			}
+		}
 		return nil, &LoadError{
 			File:    path,
 			Message: fmt.Sprintf("Starlark execution error: %v", err),
@@ -125,6 +207,97 @@ func (l *Loader) loadFile(path string) (*LoadedModule, error) {
 	}, nil
 }
 
+// exec executes a .star file with the standard library and returns its
+// globals. root is the directory its "//" loads resolve against: the project
+// root, or the package root for files of a vendored package. Results are
+// cached, and loading a file that is still executing reports the chain of
+// loads as a cycle.
+func (l *Loader) exec(path, root string) (starlark.StringDict, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := l.modules[abs]; ok {
+		return entry.globals, entry.err
+	}
+	for i, p := range l.loading {
+		if p == abs {
+			chain := make([]string, 0, len(l.loading)-i+1)
+			for _, q := range append(l.loading[i:], abs) {
+				chain = append(chain, l.displayPath(q))
+			}
+			return nil, fmt.Errorf("load cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{
+		Name: fmt.Sprintf("load:%s", strings.TrimSuffix(filepath.Base(path), ".star")),
+		Print: func(_ *starlark.Thread, msg string) {
+			// Ignore prints during macro loading
+		},
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			target, targetRoot, err := l.resolve(abs, root, module)
+			if err != nil {
+				return nil, err
+			}
+			return l.exec(target, targetRoot)
+		},
+	}
+
+	l.loading = append(l.loading, abs)
+	// Execute the Starlark file with the standard library available
+	globals, err := starlark.ExecFile(thread, path, content, stdlib.Globals())
+	l.loading = l.loading[:len(l.loading)-1]
+
+	l.modules[abs] = &loadEntry{globals: globals, err: err}
+	return globals, err
+}
+
+// resolve maps a load() module string to a file path and the root that
+// file's own "//" loads resolve against. from is the absolute path of the
+// loading file and root its root.
+func (l *Loader) resolve(from, root, module string) (string, string, error) {
+	if !strings.HasSuffix(module, ".star") {
+		return "", "", fmt.Errorf("load %q: module must be a .star file", module)
+	}
+
+	switch {
+	case strings.HasPrefix(module, "//"):
+		return filepath.Join(root, filepath.FromSlash(module[2:])), root, nil
+	case strings.HasPrefix(module, "@"):
+		pkg, rest, ok := strings.Cut(module[1:], "//")
+		if !ok || pkg == "" {
+			return "", "", fmt.Errorf("load %q: package loads have the form @name//path.star", module)
+		}
+		if l.vendorDir == "" {
+			return "", "", fmt.Errorf("load %q: no packages directory configured", module)
+		}
+		pkgRoot := filepath.Join(l.vendorDir, pkg)
+		if info, err := os.Stat(pkgRoot); err != nil || !info.IsDir() {
+			return "", "", fmt.Errorf("load %q: package %s is not installed (run leapsql deps install)", module, pkg)
+		}
+		return filepath.Join(pkgRoot, filepath.FromSlash(rest)), pkgRoot, nil
+	default:
+		return filepath.Join(filepath.Dir(from), filepath.FromSlash(module)), root, nil
+	}
+}
+
+// displayPath shortens an absolute path relative to the project root for
+// error messages.
+func (l *Loader) displayPath(path string) string {
+	if root, err := filepath.Abs(l.root); err == nil {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return "//" + filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
 // validateNamespace checks if a namespace name is valid.
 func validateNamespace(name string) error {
 	if name == "" {
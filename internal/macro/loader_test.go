@@ -3,6 +3,7 @@ package macro
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"go.starlark.net/starlark"
@@ -278,3 +279,176 @@ def double(x):
 		t.Errorf("expected 10, got %d", val)
 	}
 }
+
+func TestLoadPackage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"strings.star": "def slugify(col):\n    return \"lower(\" + col + \")\"\n\ndef _private():\n    pass\n",
+		"dates.star":   "def today():\n    return \"current_date\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	module, err := LoadPackage("utils", dir)
+	if err != nil {
+		t.Fatalf("LoadPackage() failed: %v", err)
+	}
+	if module.Namespace != "utils" {
+		t.Errorf("namespace = %q, want utils", module.Namespace)
+	}
+	for _, name := range []string{"slugify", "today"} {
+		if _, ok := module.Exports[name]; !ok {
+			t.Errorf("missing export %q", name)
+		}
+	}
+	if _, ok := module.Exports["_private"]; ok {
+		t.Error("private function should not be exported")
+	}
+
+	// Exports from two files may not collide
+	if err := os.WriteFile(filepath.Join(dir, "more.star"), []byte("def today():\n    return 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPackage("utils", dir); err == nil {
+		t.Error("expected error for duplicate export")
+	}
+
+	// A package without macros yields no module
+	module, err = LoadPackage("empty", filepath.Join(dir, "missing"))
+	if err != nil || module != nil {
+		t.Errorf("LoadPackage() on missing dir = %v, %v; want nil, nil", module, err)
+	}
+}
+
+// writeFiles writes files (relative path -> content) under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoader_Load_WithLoad(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"macros/common.star":              "def quote(s):\n    return '\"' + s + '\"'\n",
+		"macros/lib/dates.star":           "load(\"//macros/common.star\", \"quote\")\n\ndef col(name):\n    return quote(name) + \"::date\"\n",
+		"macros/utils.star":               "load(\"//macros/common.star\", \"quote\")\nload(\"lib/dates.star\", date_col = \"col\")\n\ndef cols(a, b):\n    return quote(a) + \", \" + date_col(b)\n",
+		"macros/other.star":               "load(\"common.star\", \"quote\")\n\ndef q(s):\n    return quote(s)\n",
+		"_vendor/pkg/macros/strings.star": "load(\"//macros/helpers.star\", \"up\")\n\ndef shout(s):\n    return up(s) + \"!\"\n",
+		"_vendor/pkg/macros/helpers.star": "def up(s):\n    return s.upper()\n",
+		"macros/shouty.star":              "load(\"@pkg//macros/strings.star\", \"shout\")\n\ndef hi():\n    return shout(\"hi\")\n",
+	})
+
+	loader := NewLoader(filepath.Join(root, "macros"), WithVendorDir(filepath.Join(root, "_vendor")))
+	modules, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	// Subdirectories are not namespaces; loaded names are not re-exported
+	byNamespace := make(map[string]*LoadedModule)
+	for _, m := range modules {
+		byNamespace[m.Namespace] = m
+	}
+	if len(byNamespace) != 4 {
+		t.Errorf("expected 4 namespaces, got %d", len(byNamespace))
+	}
+	if _, ok := byNamespace["utils"].Exports["quote"]; ok {
+		t.Error("loaded symbols should not be exported by the loading file")
+	}
+
+	call := func(namespace, fn string, args ...string) string {
+		t.Helper()
+		var tuple starlark.Tuple
+		for _, a := range args {
+			tuple = append(tuple, starlark.String(a))
+		}
+		v, err := starlark.Call(&starlark.Thread{}, byNamespace[namespace].Exports[fn], tuple, nil)
+		if err != nil {
+			t.Fatalf("%s.%s: %v", namespace, fn, err)
+		}
+		return string(v.(starlark.String))
+	}
+	if got := call("utils", "cols", "a", "b"); got != `"a", "b"::date` {
+		t.Errorf("utils.cols = %s", got)
+	}
+	if got := call("other", "q", "x"); got != `"x"` {
+		t.Errorf("other.q = %s", got)
+	}
+	if got := call("shouty", "hi"); got != "HI!" {
+		t.Errorf("shouty.hi = %s", got)
+	}
+
+	// common.star is executed once despite being loaded three times
+	if len(loader.modules) != 7 {
+		t.Errorf("expected 7 executed files, got %d", len(loader.modules))
+	}
+}
+
+func TestLoader_Load_LoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"macros/a.star":     "load(\"//macros/lib/b.star\", \"g\")\n\ndef f():\n    return g()\n",
+				"macros/lib/b.star": "load(\"//macros/a.star\", \"f\")\n\ndef g():\n    return f()\n",
+			},
+			wantErr: "load cycle: //macros/a.star -> //macros/lib/b.star -> //macros/a.star",
+		},
+		{
+			name:    "self load",
+			files:   map[string]string{"macros/a.star": "load(\"a.star\", \"f\")\n"},
+			wantErr: "load cycle: //macros/a.star -> //macros/a.star",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{"macros/a.star": "load(\"//macros/nope.star\", \"f\")\n"},
+			wantErr: "cannot load //macros/nope.star",
+		},
+		{
+			name:    "not a star file",
+			files:   map[string]string{"macros/a.star": "load(\"common\", \"f\")\n"},
+			wantErr: "module must be a .star file",
+		},
+		{
+			name:    "missing package",
+			files:   map[string]string{"macros/a.star": "load(\"@nope//macros/x.star\", \"f\")\n"},
+			wantErr: "package nope is not installed",
+		},
+		{
+			name: "missing symbol",
+			files: map[string]string{
+				"macros/a.star":     "load(\"//macros/lib/c.star\", \"nope\")\n",
+				"macros/lib/c.star": "def f():\n    pass\n",
+			},
+			wantErr: "load: name nope not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			loader := NewLoader(filepath.Join(root, "macros"), WithVendorDir(filepath.Join(root, "_vendor")))
+			_, err := loader.Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
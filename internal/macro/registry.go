@@ -4,10 +4,15 @@ package macro
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"go.starlark.net/starlark"
 )
 
+// MaterializationsNamespace is the macro namespace searched for custom
+// materializations named without a namespace.
+const MaterializationsNamespace = "materializations"
+
 // ReservedNamespaces are builtin globals that cannot be overridden by macros.
 var ReservedNamespaces = []string{"config", "env", "target", "this"}
 
@@ -64,6 +69,22 @@ func (r *Registry) Get(namespace string) *LoadedModule {
 	return r.modules[namespace]
 }
 
+// Materialization returns the function implementing a custom materialization.
+// "name" refers to materializations.name (macros/materializations.star);
+// "ns.name" to a function in any namespace, such as an installed package.
+func (r *Registry) Materialization(name string) (starlark.Callable, bool) {
+	namespace, fn := MaterializationsNamespace, name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		namespace, fn = name[:i], name[i+1:]
+	}
+	module := r.modules[namespace]
+	if module == nil {
+		return nil, false
+	}
+	callable, ok := module.Exports[fn].(starlark.Callable)
+	return callable, ok
+}
+
 // Has returns true if a namespace is registered.
 func (r *Registry) Has(namespace string) bool {
 	_, ok := r.modules[namespace]
@@ -150,8 +171,8 @@ func (e *RegistryError) Error() string {
 
 // LoadAndRegister is a convenience function that loads macros from a directory
 // and registers them in a new registry.
-func LoadAndRegister(macrosDir string) (*Registry, error) {
-	loader := NewLoader(macrosDir)
+func LoadAndRegister(macrosDir string, opts ...LoaderOption) (*Registry, error) {
+	loader := NewLoader(macrosDir, opts...)
 	modules, err := loader.Load()
 	if err != nil {
 		return nil, err
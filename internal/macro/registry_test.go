@@ -157,6 +157,32 @@ func TestRegistry_Get(t *testing.T) {
 	}
 }
 
+func TestRegistry_Materialization(t *testing.T) {
+	fn := starlark.NewBuiltin("fn", nil)
+	registry := NewRegistry()
+	registry.RegisterAll([]*LoadedModule{
+		{Namespace: MaterializationsNamespace, Exports: starlark.StringDict{"snapshot": fn, "limit": starlark.MakeInt(1)}},
+		{Namespace: "utils", Exports: starlark.StringDict{"append_only": fn}},
+	})
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"snapshot", true},
+		{"utils.append_only", true},
+		{"limit", false},
+		{"missing", false},
+		{"utils.missing", false},
+		{"other.snapshot", false},
+	}
+	for _, tt := range tests {
+		if _, ok := registry.Materialization(tt.name); ok != tt.want {
+			t.Errorf("Materialization(%q) found = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
 func TestRegistry_Namespaces(t *testing.T) {
 	registry := NewRegistry()
 
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+// placeholderPattern matches the placeholders FormatContent puts in for
+// template code, in any letter case.
+var placeholderPattern = regexp.MustCompile(`(?i)/\*__leapsql_stmt_(\d+)__\s*\*/|__leapsql_expr_(\d+)__`)
+
+// FormatContent formats the SQL of a model file. Frontmatter is kept as
+// written, and template code ({{ }} and {* *}) is kept in place: expressions
+// stand in for a name while the SQL is formatted, and statements for a
+// comment that stays between the same tokens.
+func FormatContent(content string, opts lineage.PrintOptions) (string, error) {
+	head, body := "", content
+	if loc := frontmatterPattern.FindStringIndex(content); loc != nil {
+		head, body = content[:loc[1]], content[loc[1]:]
+	}
+	if ifPattern.MatchString(body) {
+		return "", fmt.Errorf("can't format #if directives; use {* if *} template blocks instead")
+	}
+
+	// The blank lines between frontmatter and SQL are kept
+	trimmed := strings.TrimLeft(body, " \t\r\n")
+	if lead := body[:len(body)-len(trimmed)]; head != "" {
+		head += lead[:strings.LastIndexByte(lead, '\n')+1]
+	}
+	body = trimmed
+
+	// Placeholders span as many lines as the code they replace, and the
+	// frontmatter lines are padded, so errors point at lines of the file
+	var blocks []string
+	body = templateStmtPattern.ReplaceAllStringFunc(body, func(block string) string {
+		blocks = append(blocks, block)
+		return fmt.Sprintf("/*__leapsql_stmt_%d__%s*/", len(blocks)-1, strings.Repeat("\n", strings.Count(block, "\n")))
+	})
+	body = templateExprPattern.ReplaceAllStringFunc(body, func(block string) string {
+		blocks = append(blocks, block)
+		return fmt.Sprintf("__leapsql_expr_%d__", len(blocks)-1)
+	})
+	body = strings.Repeat("\n", strings.Count(head, "\n")) + body
+	restore := func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+			groups := placeholderPattern.FindStringSubmatch(m)
+			i, _ := strconv.Atoi(groups[1] + groups[2])
+			return blocks[i]
+		})
+	}
+
+	formatted, err := lineage.Format(body, lineage.FormatOptions{
+		PrintOptions: opts,
+		Anchored: func(comment string) bool {
+			return strings.HasPrefix(comment, "/*__leapsql_stmt_")
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s", restore(err.Error()))
+	}
+	return head + restore(formatted), nil
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+)
+
+func TestFormatContent(t *testing.T) {
+	content := `/*---
+name: stg_orders
+materialized: table
+---*/
+
+-- Orders, cleaned
+select {{ utils.safe_cast("id", "INTEGER") }} as order_id, lower(status) status
+from {{ ref("raw_orders") }}
+{* if target.type == "duckdb" *}
+where status != 'cancelled'
+{* endif *}
+`
+	got, err := FormatContent(content, lineage.PrintOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `/*---
+name: stg_orders
+materialized: table
+---*/
+
+-- Orders, cleaned
+SELECT
+    {{ utils.safe_cast("id", "INTEGER") }} AS order_id,
+    LOWER(status) AS status
+FROM {{ ref("raw_orders") }}
+{* if target.type == "duckdb" *}
+WHERE status != 'cancelled'
+{* endif *}
+`
+	if got != want {
+		t.Errorf("FormatContent() =\n%s\nwant:\n%s", got, want)
+	}
+
+	again, err := FormatContent(got, lineage.PrintOptions{})
+	if err != nil || again != got {
+		t.Errorf("formatting again changed the file: %q (err: %v)", again, err)
+	}
+}
+
+func TestFormatContent_Errors(t *testing.T) {
+	tests := map[string]string{
+		"if directives": "-- #if env == 'prod'\nSELECT 1\n-- #endif\n",
+		"syntax error":  "/*---\nname: x\n---*/\nSELECT {{ a }} FROM\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FormatContent(content, lineage.PrintOptions{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if strings.Contains(err.Error(), "__leapsql_") {
+				t.Errorf("error mentions a placeholder: %v", err)
+			}
+		})
+	}
+}
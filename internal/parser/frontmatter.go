@@ -13,20 +13,115 @@ import (
 // Unknown fields cause parse errors (use Meta for extensions).
 type FrontmatterConfig struct {
 	Name         string         `yaml:"name"`
+	Description  string         `yaml:"description"`
 	Materialized string         `yaml:"materialized"` // table, view, incremental
 	UniqueKey    string         `yaml:"unique_key"`
 	Owner        string         `yaml:"owner"`
 	Schema       string         `yaml:"schema"`
 	Tags         []string       `yaml:"tags"`
+	Columns      []ColumnDoc    `yaml:"columns"`
 	Tests        []TestConfig   `yaml:"tests"`
-	Meta         map[string]any `yaml:"meta"` // Extension point for custom fields
+	PreHook      Hooks          `yaml:"pre_hook"`  // SQL run before materialization
+	PostHook     Hooks          `yaml:"post_hook"` // SQL run after materialization
+	Meta         map[string]any `yaml:"meta"`      // Extension point for custom fields
+}
+
+// Hooks is a list of templated SQL statements. In YAML it may be written as a
+// single string or a list of strings.
+type Hooks []string
+
+// UnmarshalYAML decodes a hook string or list of hook strings.
+func (h *Hooks) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*h = Hooks{value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var hooks []string
+		if err := value.Decode(&hooks); err != nil {
+			return fmt.Errorf("line %d: hooks must be SQL strings", value.Line)
+		}
+		*h = hooks
+		return nil
+	default:
+		return fmt.Errorf("line %d: hook must be a SQL string or a list of SQL strings", value.Line)
+	}
+}
+
+// ColumnDoc represents documentation for a single output column in frontmatter.
+type ColumnDoc struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	Tags        []string       `yaml:"tags,omitempty"`
+	Meta        map[string]any `yaml:"meta,omitempty"`
 }
 
 // TestConfig represents a test configuration in frontmatter.
+// Besides the built-in checks, an entry may name a generic test defined as a
+// Starlark macro, e.g. "- relationships: {column: id, to: staging.users, field: id}".
 type TestConfig struct {
 	Unique         []string              `yaml:"unique,omitempty"`
 	NotNull        []string              `yaml:"not_null,omitempty"`
 	AcceptedValues *AcceptedValuesConfig `yaml:"accepted_values,omitempty"`
+	Generic        *GenericTestConfig    `yaml:"-"`
+	Severity       string                `yaml:"severity,omitempty"` // "error" (default) or "warn"
+}
+
+// GenericTestConfig represents a user-defined test invoked by name.
+type GenericTestConfig struct {
+	Name string
+	Args map[string]any
+}
+
+// Test severities.
+const (
+	SeverityError = "error"
+	SeverityWarn  = "warn"
+)
+
+// UnmarshalYAML decodes a test entry, treating any key other than the
+// built-in tests and "severity" as the name of a generic test.
+func (t *TestConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: test must be a mapping", value.Line)
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i].Value
+		node := value.Content[i+1]
+
+		var err error
+		switch key {
+		case "unique":
+			err = node.Decode(&t.Unique)
+		case "not_null":
+			err = node.Decode(&t.NotNull)
+		case "accepted_values":
+			err = node.Decode(&t.AcceptedValues)
+		case "severity":
+			err = node.Decode(&t.Severity)
+			if err == nil && t.Severity != SeverityError && t.Severity != SeverityWarn {
+				return fmt.Errorf("line %d: invalid severity %q, must be one of: error, warn", node.Line, t.Severity)
+			}
+		default:
+			if t.Generic != nil {
+				return fmt.Errorf("line %d: test entry defines more than one generic test (%s, %s)", node.Line, t.Generic.Name, key)
+			}
+			args := map[string]any{}
+			if node.Kind == yaml.ScalarNode {
+				// Shorthand: "- positive: amount" passes a single column
+				args["column"] = node.Value
+			} else if err = node.Decode(&args); err != nil {
+				return fmt.Errorf("line %d: arguments for test %q must be a mapping", node.Line, key)
+			}
+			t.Generic = &GenericTestConfig{Name: key, Args: args}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // AcceptedValuesConfig represents accepted values test configuration.
@@ -46,6 +141,10 @@ type FrontmatterResult struct {
 // The pattern allows optional content between the delimiters
 var frontmatterPattern = regexp.MustCompile(`(?s)^\s*/\*---\s*\n(.*?)\s*---\*/`)
 
+// materializedPattern matches a materialization name: an identifier,
+// optionally qualified by a macro namespace.
+var materializedPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
 // ExtractFrontmatter extracts YAML frontmatter from SQL content.
 // Returns the parsed config, remaining SQL, and any error.
 func ExtractFrontmatter(content string) (*FrontmatterResult, error) {
@@ -90,12 +189,16 @@ func parseFrontmatterYAML(yamlContent string) (*FrontmatterConfig, error) {
 	// Check for unknown fields
 	knownFields := map[string]bool{
 		"name":         true,
+		"description":  true,
 		"materialized": true,
 		"unique_key":   true,
 		"owner":        true,
 		"schema":       true,
 		"tags":         true,
+		"columns":      true,
 		"tests":        true,
+		"pre_hook":     true,
+		"post_hook":    true,
 		"meta":         true,
 	}
 
@@ -115,18 +218,30 @@ func parseFrontmatterYAML(yamlContent string) (*FrontmatterConfig, error) {
 		}
 	}
 
-	// Validate materialized value if present
-	if config.Materialized != "" {
-		validMaterialized := map[string]bool{
-			"table":       true,
-			"view":        true,
-			"incremental": true,
+	// Validate column docs
+	seenColumns := make(map[string]bool, len(config.Columns))
+	for i, col := range config.Columns {
+		if col.Name == "" {
+			return nil, &FrontmatterParseError{
+				Message: fmt.Sprintf("columns[%d]: name is required", i),
+			}
 		}
-		if !validMaterialized[config.Materialized] {
+		key := strings.ToLower(col.Name)
+		if seenColumns[key] {
 			return nil, &FrontmatterParseError{
-				Message: fmt.Sprintf("invalid materialized value: %q, must be one of: table, view, incremental", config.Materialized),
+				Message: fmt.Sprintf("columns: duplicate column %q", col.Name),
 			}
 		}
+		seenColumns[key] = true
+	}
+
+	// Validate materialized value if present. Names other than the builtin
+	// table, view and incremental refer to custom materializations defined
+	// in macros ("name" or "namespace.name"), resolved when the model runs.
+	if config.Materialized != "" && !materializedPattern.MatchString(config.Materialized) {
+		return nil, &FrontmatterParseError{
+			Message: fmt.Sprintf("invalid materialized value: %q, must be table, view, incremental or the name of a custom materialization", config.Materialized),
+		}
 	}
 
 	return &config, nil
@@ -210,7 +210,7 @@ SELECT 1`
 func TestExtractFrontmatter_InvalidMaterialized(t *testing.T) {
 	content := `/*---
 name: test_model
-materialized: invalid_type
+materialized: "invalid type"
 ---*/
 
 SELECT 1`
@@ -472,3 +472,153 @@ SELECT * FROM aggregated`
 		t.Error("expected SQL to contain the full query")
 	}
 }
+
+func TestExtractFrontmatter_ColumnDocs(t *testing.T) {
+	content := `/*---
+name: stg_customers
+description: Cleaned customer records
+columns:
+  - name: customer_id
+    description: Primary key
+    tags: [pk]
+  - name: email
+    description: Contact email
+    meta:
+      pii: true
+---*/
+
+SELECT customer_id, email FROM raw_customers`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fc := result.Config
+	if fc.Description != "Cleaned customer records" {
+		t.Errorf("expected description 'Cleaned customer records', got %q", fc.Description)
+	}
+	if len(fc.Columns) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(fc.Columns))
+	}
+	if fc.Columns[0].Name != "customer_id" || fc.Columns[0].Description != "Primary key" {
+		t.Errorf("unexpected first column: %+v", fc.Columns[0])
+	}
+	if len(fc.Columns[0].Tags) != 1 || fc.Columns[0].Tags[0] != "pk" {
+		t.Errorf("expected tags [pk], got %v", fc.Columns[0].Tags)
+	}
+	if fc.Columns[1].Meta["pii"] != true {
+		t.Errorf("expected meta.pii true, got %v", fc.Columns[1].Meta["pii"])
+	}
+}
+
+func TestExtractFrontmatter_ColumnDocsMissingName(t *testing.T) {
+	content := `/*---
+columns:
+  - description: no name
+---*/
+
+SELECT 1`
+
+	_, err := ExtractFrontmatter(content)
+	if _, ok := err.(*FrontmatterParseError); !ok {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+}
+
+func TestExtractFrontmatter_ColumnDocsDuplicate(t *testing.T) {
+	content := `/*---
+columns:
+  - name: id
+  - name: ID
+---*/
+
+SELECT 1 AS id`
+
+	_, err := ExtractFrontmatter(content)
+	if _, ok := err.(*FrontmatterParseError); !ok {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+}
+
+func TestExtractFrontmatter_GenericTests(t *testing.T) {
+	content := `/*---
+tests:
+  - unique: [order_id]
+    severity: warn
+  - relationships: {column: customer_id, to: staging.stg_customers, field: customer_id}
+  - positive: amount
+---*/
+
+SELECT 1`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := result.Config.Tests
+	if len(tests) != 3 {
+		t.Fatalf("expected 3 tests, got %d", len(tests))
+	}
+
+	if tests[0].Severity != SeverityWarn || len(tests[0].Unique) != 1 {
+		t.Errorf("unexpected first test: %+v", tests[0])
+	}
+
+	rel := tests[1].Generic
+	if rel == nil || rel.Name != "relationships" {
+		t.Fatalf("expected relationships generic test, got %+v", tests[1])
+	}
+	if rel.Args["to"] != "staging.stg_customers" || rel.Args["field"] != "customer_id" {
+		t.Errorf("unexpected relationships args: %v", rel.Args)
+	}
+
+	pos := tests[2].Generic
+	if pos == nil || pos.Name != "positive" || pos.Args["column"] != "amount" {
+		t.Errorf("expected positive test with column shorthand, got %+v", tests[2])
+	}
+}
+
+func TestExtractFrontmatter_InvalidSeverity(t *testing.T) {
+	content := `/*---
+tests:
+  - unique: [id]
+    severity: fatal
+---*/
+
+SELECT 1`
+
+	_, err := ExtractFrontmatter(content)
+	if _, ok := err.(*FrontmatterParseError); !ok {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+}
+
+func TestExtractFrontmatter_Hooks(t *testing.T) {
+	content := `/*---
+pre_hook: "DELETE FROM audit WHERE model = '{{ this.name }}'"
+post_hook:
+  - ANALYZE
+  - "INSERT INTO audit VALUES ('{{ this.name }}')"
+---*/
+
+SELECT 1`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Config.PreHook) != 1 || result.Config.PreHook[0] != "DELETE FROM audit WHERE model = '{{ this.name }}'" {
+		t.Errorf("unexpected pre_hook: %v", result.Config.PreHook)
+	}
+	if len(result.Config.PostHook) != 2 || result.Config.PostHook[0] != "ANALYZE" {
+		t.Errorf("unexpected post_hook: %v", result.Config.PostHook)
+	}
+
+	_, err = ExtractFrontmatter("/*---\npost_hook: {sql: x}\n---*/\nSELECT 1")
+	if _, ok := err.(*FrontmatterParseError); !ok {
+		t.Fatalf("expected FrontmatterParseError for a mapping hook, got %T: %v", err, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/leapstack-labs/leapsql/pkg/lineage"
 )
@@ -19,9 +20,13 @@ type ModelConfig struct {
 	Path string
 	// Name is the model name (filename without extension)
 	Name string
-	// FilePath is the absolute path to the SQL file
+	// Description is the human-readable model description from frontmatter
+	Description string
+	// FilePath is the absolute path to the model file
 	FilePath string
-	// Materialized defines how the model is stored: table, view, incremental
+	// Language is LanguageSQL for .sql models and LanguageStarlark for .star models
+	Language string
+	// Materialized defines how the model is stored: table, view, incremental (or "seed" for seed nodes)
 	Materialized string
 	// UniqueKey for incremental models
 	UniqueKey string
@@ -35,23 +40,51 @@ type ModelConfig struct {
 	Meta map[string]any
 	// Tests contains test configurations from frontmatter
 	Tests []TestConfig
+	// PreHooks are templated SQL statements run before the model is materialized
+	PreHooks []string
+	// PostHooks are templated SQL statements run after the model is materialized
+	PostHooks []string
+	// HookSources are the relations the hooks read, not counting relations they write
+	// (auto-detected via lineage parser). They order the model but aren't model inputs.
+	HookSources []string
 	// Imports are explicit model dependencies from @import pragmas (legacy)
 	Imports []string
 	// Sources are all table names referenced in the SQL (auto-detected via lineage parser)
 	// This includes both model references and external/raw tables
 	Sources []string
+	// Files are the file paths read by table functions like read_parquet('...')
+	// They are also listed in Sources
+	Files []string
 	// Columns contains column-level lineage information
 	Columns []ColumnInfo
-	// SQL is the raw SQL content (excluding pragmas/frontmatter)
+	// ColumnDocs contains column documentation declared in frontmatter
+	ColumnDocs []ColumnDoc
+	// SQL is the raw SQL content (excluding pragmas/frontmatter); empty for Starlark models
 	SQL string
+	// SQLLines holds the 1-based file line of each line of SQL
+	SQLLines []int
+	// SQLColumn is the 1-based file column of the first character of SQL
+	SQLColumn int
 	// RawContent is the full file content including pragmas/frontmatter
 	RawContent string
 	// Conditionals are #if directives for environment-specific SQL
 	Conditionals []Conditional
 	// HasFrontmatter indicates if YAML frontmatter was found
 	HasFrontmatter bool
+	// Seed holds the load configuration for seed nodes (Materialized == "seed")
+	Seed *SeedConfig
+	// Package is the name of the vendored package the model comes from (empty for project models)
+	Package string
+	// Warnings are non-fatal problems found while parsing (e.g., unknown documented columns)
+	Warnings []string
 }
 
+// Model languages.
+const (
+	LanguageSQL      = "sql"
+	LanguageStarlark = "starlark"
+)
+
 // Conditional represents an #if directive block.
 type Conditional struct {
 	Condition string
@@ -71,12 +104,18 @@ type ColumnInfo struct {
 	TransformType string      // "" (direct) or "EXPR"
 	Function      string      // "sum", "count", etc.
 	Sources       []SourceRef // where this column comes from
+	Description   string      // from frontmatter column docs
+	Tags          []string    // from frontmatter column docs
+	Meta          map[string]any
 }
 
 // Parser parses SQL model files and extracts pragmas.
 type Parser struct {
 	// BaseDir is the models directory root
 	BaseDir string
+
+	// Dialect is the SQL dialect models are written in (nil = DuckDB)
+	Dialect *lineage.Dialect
 }
 
 // NewParser creates a new parser with the given base directory.
@@ -98,13 +137,29 @@ var (
 	kvPattern = regexp.MustCompile(`(\w+)\s*=\s*'([^']*)'`)
 )
 
-// ParseFile parses a single SQL model file.
+// Template patterns, used to find table sources in templated SQL
+var (
+	// {{ expr }}
+	templateExprPattern = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	// {* stmt *}
+	templateStmtPattern = regexp.MustCompile(`(?s)\{\*.*?\*\}`)
+	// relation("schema.table") with a literal argument
+	relationCallPattern = regexp.MustCompile(`\brelation\(\s*(?:"([^"]+)"|'([^']+)')\s*\)`)
+)
+
+// templatePlaceholder replaces template expressions when detecting sources.
+const templatePlaceholder = "__leapsql_template__"
+
+// ParseFile parses a single model file: SQL, or Starlark for .star files.
 func (p *Parser) ParseFile(filePath string) (*ModelConfig, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	if filepath.Ext(filePath) == ".star" {
+		return p.ParseStarlark(filePath, string(content))
+	}
 	return p.ParseContent(filePath, string(content))
 }
 
@@ -112,6 +167,7 @@ func (p *Parser) ParseFile(filePath string) (*ModelConfig, error) {
 func (p *Parser) ParseContent(filePath string, content string) (*ModelConfig, error) {
 	config := &ModelConfig{
 		FilePath:     filePath,
+		Language:     LanguageSQL,
 		RawContent:   content,
 		Materialized: "table", // default
 		Imports:      []string{},
@@ -136,38 +192,18 @@ func (p *Parser) ParseContent(filePath string, content string) (*ModelConfig, er
 
 	// Apply frontmatter config if present
 	if frontmatter.HasYAML && frontmatter.Config != nil {
-		fc := frontmatter.Config
-		if fc.Name != "" {
-			config.Name = fc.Name
-		}
-		if fc.Materialized != "" {
-			config.Materialized = fc.Materialized
-		}
-		if fc.UniqueKey != "" {
-			config.UniqueKey = fc.UniqueKey
-		}
-		config.Owner = fc.Owner
-		if fc.Schema != "" {
-			config.Schema = fc.Schema
-		}
-		if len(fc.Tags) > 0 {
-			config.Tags = fc.Tags
-		}
-		if fc.Meta != nil {
-			config.Meta = fc.Meta
-		}
-		if len(fc.Tests) > 0 {
-			config.Tests = fc.Tests
-		}
+		applyFrontmatter(config, frontmatter.Config)
+		config.HookSources = hookSources(append(config.PreHooks, config.PostHooks...), p.Dialect)
 	}
 
 	// Continue parsing legacy pragmas from the SQL content
 	var sqlLines []string
+	var keptLines []int // index in sqlContent of each line of sqlLines
 	var inConditional bool
 	var currentConditional Conditional
 
 	scanner := bufio.NewScanner(strings.NewReader(sqlContent))
-	for scanner.Scan() {
+	for lineIndex := 0; scanner.Scan(); lineIndex++ {
 		line := scanner.Text()
 
 		// Check for @config pragma (legacy, overrides frontmatter)
@@ -213,37 +249,225 @@ func (p *Parser) ParseContent(filePath string, content string) (*ModelConfig, er
 
 		// Regular SQL line
 		sqlLines = append(sqlLines, line)
+		keptLines = append(keptLines, lineIndex)
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error scanning file: %w", err)
 	}
 
-	config.SQL = strings.TrimSpace(strings.Join(sqlLines, "\n"))
+	joined := strings.Join(sqlLines, "\n")
+	config.SQL = strings.TrimSpace(joined)
+	config.SQLLines, config.SQLColumn = sqlLineMap(content, sqlContent, joined, keptLines)
 
 	// Auto-detect table sources and column lineage using the lineage parser
 	if config.SQL != "" {
-		result, err := extractLineage(config.SQL)
+		result, err := extractLineage(config.SQL, p.Dialect)
 		if err == nil {
 			config.Sources = result.Sources
+			config.Files = result.Files
 			config.Columns = result.Columns
+		} else {
+			config.Sources = templateSources(config.SQL, p.Dialect)
 		}
 		// If lineage extraction fails, we continue without sources/columns
 		// The model may have syntax errors or use unsupported SQL features
 	}
 
+	// Attach column docs to lineage columns
+	applyColumnDocs(config)
+
 	return config, nil
 }
 
+// FilePosition maps a 1-based line and column in SQL back to the model file.
+// Positions in rendered SQL map to SQL first, through the template's
+// SourceMap.
+func (m *ModelConfig) FilePosition(line, column int) (int, int) {
+	if line < 1 || line > len(m.SQLLines) {
+		return line, column
+	}
+	if line == 1 {
+		column += m.SQLColumn - 1
+	}
+	return m.SQLLines[line-1], column
+}
+
+// sqlLineMap locates the lines of a model's SQL in its file. The SQL is
+// joined, the lines of sqlContent at indexes kept, with surrounding
+// whitespace trimmed; sqlContent is the file content after frontmatter.
+func sqlLineMap(content, sqlContent, joined string, kept []int) ([]int, int) {
+	start := strings.LastIndex(content, sqlContent)
+	if start < 0 {
+		start = 0
+	}
+	firstLine := strings.Count(content[:start], "\n") + 1
+	firstColumn := start - strings.LastIndexByte(content[:start], '\n')
+
+	// Leading whitespace trimmed off the joined lines
+	lead := joined[:len(joined)-len(strings.TrimLeftFunc(joined, unicode.IsSpace))]
+	skip := strings.Count(lead, "\n")
+	if skip >= len(kept) {
+		return nil, 0
+	}
+
+	lines := make([]int, 0, len(kept)-skip)
+	for _, i := range kept[skip:] {
+		lines = append(lines, firstLine+i)
+	}
+	column := len(lead) - strings.LastIndexByte(lead, '\n')
+	if kept[skip] == 0 {
+		column += firstColumn - 1
+	}
+	return lines, column
+}
+
+// applyFrontmatter copies frontmatter settings onto a model config.
+func applyFrontmatter(config *ModelConfig, fc *FrontmatterConfig) {
+	if fc.Name != "" {
+		config.Name = fc.Name
+	}
+	config.Description = fc.Description
+	if fc.Materialized != "" {
+		config.Materialized = fc.Materialized
+	}
+	if fc.UniqueKey != "" {
+		config.UniqueKey = fc.UniqueKey
+	}
+	config.Owner = fc.Owner
+	if fc.Schema != "" {
+		config.Schema = fc.Schema
+	}
+	if len(fc.Tags) > 0 {
+		config.Tags = fc.Tags
+	}
+	if fc.Meta != nil {
+		config.Meta = fc.Meta
+	}
+	if len(fc.Tests) > 0 {
+		config.Tests = fc.Tests
+	}
+	if len(fc.Columns) > 0 {
+		config.ColumnDocs = fc.Columns
+	}
+	config.PreHooks = fc.PreHook
+	config.PostHooks = fc.PostHook
+}
+
+// applyColumnDocs merges frontmatter column docs into the lineage output columns.
+// Documented columns that don't appear in the lineage output produce a warning.
+// Validation is skipped when the output columns aren't fully known (no lineage, or
+// an unexpanded SELECT *).
+func applyColumnDocs(config *ModelConfig) {
+	if len(config.ColumnDocs) == 0 {
+		return
+	}
+
+	byName := make(map[string]int, len(config.Columns))
+	complete := len(config.Columns) > 0
+	for i, col := range config.Columns {
+		if col.Name == "*" || strings.HasSuffix(col.Name, ".*") {
+			complete = false
+			continue
+		}
+		byName[strings.ToLower(col.Name)] = i
+	}
+
+	for _, doc := range config.ColumnDocs {
+		idx, ok := byName[strings.ToLower(doc.Name)]
+		if !ok {
+			if complete {
+				config.Warnings = append(config.Warnings,
+					fmt.Sprintf("%s: documented column %q is not an output column of the model", config.Path, doc.Name))
+			}
+			continue
+		}
+		config.Columns[idx].Description = doc.Description
+		config.Columns[idx].Tags = doc.Tags
+		config.Columns[idx].Meta = doc.Meta
+	}
+}
+
+// templateSources detects the table sources of templated SQL by replacing
+// template expressions with a placeholder and dropping statements. Column
+// lineage isn't derived this way since expressions are unknown until rendered.
+// Literal relation("...") calls are included as sources; dynamically built
+// ones are captured by the engine when it renders the model.
+func templateSources(sql string, dialect *lineage.Dialect) []string {
+	if !strings.Contains(sql, "{{") && !strings.Contains(sql, "{*") {
+		return nil
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	add := func(src string) {
+		if src != templatePlaceholder && !seen[src] {
+			seen[src] = true
+			sources = append(sources, src)
+		}
+	}
+
+	stripped := templateStmtPattern.ReplaceAllString(sql, "")
+	stripped = templateExprPattern.ReplaceAllString(stripped, templatePlaceholder)
+	if result, err := extractLineage(stripped, dialect); err == nil {
+		for _, src := range result.Sources {
+			add(src)
+		}
+	}
+
+	for _, m := range relationCallPattern.FindAllStringSubmatch(sql, -1) {
+		add(m[1] + m[2])
+	}
+	return sources
+}
+
+// hookSources detects the relations read by templated hook statements, which
+// may be any DDL or DML. Relations the hooks write, and those named by
+// template expressions such as {{ this }}, are left out.
+func hookSources(hooks []string, dialect *lineage.Dialect) []string {
+	var sources []string
+	seen := make(map[string]bool)
+	written := make(map[string]bool)
+
+	for _, hook := range hooks {
+		stripped := templateStmtPattern.ReplaceAllString(hook, "")
+		stripped = templateExprPattern.ReplaceAllString(stripped, templatePlaceholder)
+		stmts, err := lineage.ExtractScriptLineage(stripped, lineage.ExtractLineageOptions{Dialect: dialect})
+		if err != nil {
+			continue
+		}
+		for _, stmt := range stmts {
+			if stmt.Target != "" {
+				written[stmt.Target] = true
+			}
+			for _, src := range stmt.Sources {
+				if !seen[src] && !strings.Contains(src, templatePlaceholder) {
+					seen[src] = true
+					sources = append(sources, src)
+				}
+			}
+		}
+	}
+
+	var result []string
+	for _, src := range sources {
+		if !written[src] {
+			result = append(result, src)
+		}
+	}
+	return result
+}
+
 // lineageResult holds both table sources and column lineage information.
 type lineageResult struct {
 	Sources []string
+	Files   []string
 	Columns []ColumnInfo
 }
 
 // extractLineage uses the lineage parser to extract all table sources and column lineage from SQL.
-func extractLineage(sql string) (*lineageResult, error) {
-	modelLineage, err := lineage.ExtractLineage(sql, nil)
+func extractLineage(sql string, dialect *lineage.Dialect) (*lineageResult, error) {
+	modelLineage, err := lineage.ExtractLineageWithOptions(sql, lineage.ExtractLineageOptions{Dialect: dialect})
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +495,7 @@ func extractLineage(sql string) (*lineageResult, error) {
 
 	return &lineageResult{
 		Sources: modelLineage.Sources,
+		Files:   modelLineage.Files,
 		Columns: columns,
 	}, nil
 }
@@ -299,11 +524,12 @@ func (p *Parser) filePathToModelPath(filePath string) string {
 	relPath, err := filepath.Rel(p.BaseDir, filePath)
 	if err != nil {
 		// Fallback to just the filename
-		return strings.TrimSuffix(filepath.Base(filePath), ".sql")
+		base := filepath.Base(filePath)
+		return strings.TrimSuffix(base, filepath.Ext(base))
 	}
 
-	// Remove .sql extension
-	relPath = strings.TrimSuffix(relPath, ".sql")
+	// Remove the .sql or .star extension
+	relPath = strings.TrimSuffix(relPath, filepath.Ext(relPath))
 
 	// Convert path separators to dots
 	parts := strings.Split(relPath, string(filepath.Separator))
@@ -328,19 +554,25 @@ func ExtractReferences(sql string) []string {
 	return refs
 }
 
-// Scanner scans a directory for SQL model files.
+// Scanner scans a directory for model files.
 type Scanner struct {
 	parser *Parser
 }
 
 // NewScanner creates a new directory scanner.
 func NewScanner(baseDir string) *Scanner {
-	return &Scanner{
-		parser: NewParser(baseDir),
-	}
+	return NewScannerWithDialect(baseDir, nil)
 }
 
-// ScanDir recursively scans a directory for SQL files and parses them.
+// NewScannerWithDialect creates a directory scanner that parses models
+// written in the given SQL dialect.
+func NewScannerWithDialect(baseDir string, dialect *lineage.Dialect) *Scanner {
+	p := NewParser(baseDir)
+	p.Dialect = dialect
+	return &Scanner{parser: p}
+}
+
+// ScanDir recursively scans a directory for .sql and .star model files and parses them.
 func (s *Scanner) ScanDir(dir string) ([]*ModelConfig, error) {
 	var models []*ModelConfig
 
@@ -349,8 +581,8 @@ func (s *Scanner) ScanDir(dir string) ([]*ModelConfig, error) {
 			return err
 		}
 
-		// Skip directories and non-SQL files
-		if info.IsDir() || !strings.HasSuffix(info.Name(), ".sql") {
+		// Skip directories and files that aren't models
+		if info.IsDir() || !isModelFile(info.Name()) {
 			return nil
 		}
 
@@ -375,6 +607,44 @@ func (s *Scanner) ScanDir(dir string) ([]*ModelConfig, error) {
 	return models, nil
 }
 
+// isModelFile reports whether a file name has a model extension.
+func isModelFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".sql" || ext == ".star"
+}
+
+// ScanPackage scans the models directory of a vendored package. Package
+// models are namespaced by the package: their path is "<package>.<name>",
+// so they are built in a schema named after the package.
+func ScanPackage(namespace, dir string) ([]*ModelConfig, error) {
+	return ScanPackageWithDialect(namespace, dir, nil)
+}
+
+// ScanPackageWithDialect scans a vendored package whose models are written
+// in the given SQL dialect.
+func ScanPackageWithDialect(namespace, dir string, dialect *lineage.Dialect) ([]*ModelConfig, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	models, err := NewScannerWithDialect(dir, dialect).ScanDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("package %s: %w", namespace, err)
+	}
+
+	seen := make(map[string]string, len(models))
+	for _, m := range models {
+		m.Path = namespace + "." + m.Name
+		m.Package = namespace
+		if other, ok := seen[m.Path]; ok {
+			return nil, fmt.Errorf("package %s: models %s and %s both define %s", namespace, other, m.FilePath, m.Path)
+		}
+		seen[m.Path] = m.FilePath
+	}
+
+	return models, nil
+}
+
 // GetParser returns the underlying parser.
 func (s *Scanner) GetParser() *Parser {
 	return s.parser
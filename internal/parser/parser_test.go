@@ -3,6 +3,8 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -319,6 +321,39 @@ func TestScanner_ScanDir_SkipsHiddenFiles(t *testing.T) {
 	}
 }
 
+func TestScanPackage(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "staging"), 0755)
+	os.WriteFile(filepath.Join(dir, "calendar.sql"), []byte("SELECT 1 AS d"), 0644)
+	os.WriteFile(filepath.Join(dir, "staging", "stg_events.sql"), []byte("SELECT * FROM events"), 0644)
+
+	models, err := ScanPackage("utils", dir)
+	if err != nil {
+		t.Fatalf("ScanPackage() failed: %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, m := range models {
+		paths[m.Path] = true
+		if m.Package != "utils" {
+			t.Errorf("%s: package = %q, want utils", m.Path, m.Package)
+		}
+	}
+	if len(paths) != 2 || !paths["utils.calendar"] || !paths["utils.stg_events"] {
+		t.Errorf("unexpected package model paths: %v", paths)
+	}
+
+	// Model names must be unique within a package
+	os.WriteFile(filepath.Join(dir, "staging", "calendar.sql"), []byte("SELECT 2 AS d"), 0644)
+	if _, err := ScanPackage("utils", dir); err == nil {
+		t.Error("expected error for duplicate model name")
+	}
+
+	if models, err := ScanPackage("utils", filepath.Join(dir, "missing")); err != nil || models != nil {
+		t.Errorf("ScanPackage() on missing dir = %v, %v; want nil, nil", models, err)
+	}
+}
+
 func TestParser_ParseContent_AutoDetectSources(t *testing.T) {
 	p := NewParser("/models")
 
@@ -355,6 +390,25 @@ func TestParser_ParseContent_AutoDetectQualifiedSources(t *testing.T) {
 	}
 }
 
+func TestParser_ParseContent_AutoDetectFiles(t *testing.T) {
+	p := NewParser("/models")
+
+	content := `SELECT e.id, u.id AS user_id
+FROM read_parquet('s3://lake/events/*.parquet') e
+JOIN users u ON e.user_id = u.id`
+	config, err := p.ParseContent("/models/events.sql", content)
+	if err != nil {
+		t.Fatalf("failed to parse content: %v", err)
+	}
+
+	if len(config.Files) != 1 || config.Files[0] != "s3://lake/events/*.parquet" {
+		t.Errorf("expected file 's3://lake/events/*.parquet', got %v", config.Files)
+	}
+	if len(config.Sources) != 2 {
+		t.Errorf("expected the file and users as sources, got %v", config.Sources)
+	}
+}
+
 func TestParser_ParseContent_AutoDetectJoinSources(t *testing.T) {
 	p := NewParser("/models")
 
@@ -524,3 +578,166 @@ func TestParser_ParseContent_ColumnLineage(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_ParseContent_ColumnDocs(t *testing.T) {
+	p := NewParser("/models")
+
+	content := `/*---
+description: Customer summary
+columns:
+  - name: customer_id
+    description: Primary key
+  - name: missing_col
+    description: Not selected
+---*/
+SELECT customer_id, name FROM customers`
+
+	config, err := p.ParseContent("/models/summary.sql", content)
+	if err != nil {
+		t.Fatalf("failed to parse content: %v", err)
+	}
+
+	if config.Description != "Customer summary" {
+		t.Errorf("expected description 'Customer summary', got %q", config.Description)
+	}
+	if len(config.Columns) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(config.Columns))
+	}
+	if config.Columns[0].Description != "Primary key" {
+		t.Errorf("expected customer_id description 'Primary key', got %q", config.Columns[0].Description)
+	}
+	if len(config.Warnings) != 1 || !strings.Contains(config.Warnings[0], "missing_col") {
+		t.Errorf("expected one warning about missing_col, got %v", config.Warnings)
+	}
+}
+
+func TestParser_ParseContent_ColumnDocsSelectStar(t *testing.T) {
+	p := NewParser("/models")
+
+	content := `/*---
+columns:
+  - name: anything
+---*/
+SELECT * FROM customers`
+
+	config, err := p.ParseContent("/models/all.sql", content)
+	if err != nil {
+		t.Fatalf("failed to parse content: %v", err)
+	}
+
+	if len(config.Warnings) != 0 {
+		t.Errorf("expected no warnings for SELECT *, got %v", config.Warnings)
+	}
+}
+
+func TestTemplateSources(t *testing.T) {
+	sql := `{* if env == "prod": *}
+SELECT {{ utils.safe_cast("id", "INTEGER") }} AS id, name
+FROM raw_customers c
+JOIN {{ ref_table }} r ON r.id = c.id
+{* endif *}`
+
+	sources := templateSources(sql, nil)
+	if len(sources) != 1 || sources[0] != "raw_customers" {
+		t.Errorf("templateSources() = %v, want [raw_customers]", sources)
+	}
+
+	if got := templateSources("SELECT * FROM t", nil); got != nil {
+		t.Errorf("templateSources() on plain SQL = %v, want nil", got)
+	}
+}
+
+func TestTemplateSources_RelationCalls(t *testing.T) {
+	sql := `{* orders = relation('staging.stg_orders') *}
+SELECT o.id, c.name
+FROM {{ orders }} o
+JOIN {{ relation("staging.stg_customers") }} c ON c.id = o.customer_id
+JOIN {{ relation("staging." + name) }} d ON d.id = o.id
+JOIN raw_dates r ON r.id = o.id`
+
+	sources := templateSources(sql, nil)
+	want := []string{"raw_dates", "staging.stg_orders", "staging.stg_customers"}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("templateSources() = %v, want %v", sources, want)
+	}
+}
+
+func TestHookSources(t *testing.T) {
+	hooks := []string{
+		"DELETE FROM audit WHERE model = '{{ this.name }}'",
+		"INSERT INTO audit SELECT 'post', count(*) FROM {{ this.relation }} JOIN staging.stg_runs r ON r.id = 1",
+		"MERGE INTO snapshots s USING raw_events e ON s.id = e.id WHEN NOT MATCHED THEN INSERT (id) VALUES (e.id); ANALYZE",
+		"SELECT * FROM snapshots",
+	}
+
+	sources := hookSources(hooks, nil)
+	want := []string{"staging.stg_runs", "raw_events"}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("hookSources() = %v, want %v", sources, want)
+	}
+}
+
+func TestModelConfig_FilePosition(t *testing.T) {
+	p := NewParser("/models")
+	content := "---\nmaterialized: view\n---\n\n  SELECT id,\n-- @import(staging.users)\n    name\nFROM users"
+
+	config, err := p.ParseContent("/models/users.sql", content)
+	if err != nil {
+		t.Fatalf("ParseContent() error = %v", err)
+	}
+
+	tests := []struct {
+		find         string // text of SQL
+		line, column int    // position in the file
+	}{
+		{"SELECT", 5, 3},
+		{"id", 5, 10},
+		{"name", 7, 5},
+		{"users", 8, 6},
+	}
+	for _, tt := range tests {
+		offset := strings.Index(config.SQL, tt.find)
+		line := strings.Count(config.SQL[:offset], "\n") + 1
+		column := offset - strings.LastIndex(config.SQL[:offset], "\n")
+
+		gotLine, gotColumn := config.FilePosition(line, column)
+		if gotLine != tt.line || gotColumn != tt.column {
+			t.Errorf("%q at %d:%d maps to %d:%d, want %d:%d", tt.find, line, column, gotLine, gotColumn, tt.line, tt.column)
+		}
+	}
+}
+
+func TestParser_ParseStarlark(t *testing.T) {
+	p := NewParser("/models")
+
+	config, err := p.ParseStarlark("/models/marts/totals.star", `"""---
+materialized: view
+owner: finance
+---"""
+def model(ctx):
+    orders = ctx.ref("staging.orders")
+    return "SELECT * FROM %s JOIN %s USING (id)" % (orders, ctx.ref('staging.customers'), ctx.ref("staging.orders"))
+`)
+	if err != nil {
+		t.Fatalf("ParseStarlark() error = %v", err)
+	}
+	if config.Path != "marts.totals" || config.Name != "totals" || config.Language != LanguageStarlark {
+		t.Errorf("got path %q, name %q, language %q", config.Path, config.Name, config.Language)
+	}
+	if config.Materialized != "view" || config.Owner != "finance" || !config.HasFrontmatter {
+		t.Errorf("frontmatter not applied: %+v", config)
+	}
+	if want := []string{"staging.orders", "staging.customers"}; !reflect.DeepEqual(config.Sources, want) {
+		t.Errorf("Sources = %v, want %v", config.Sources, want)
+	}
+
+	for name, content := range map[string]string{
+		"no model":     "def build(ctx):\n    return 'SELECT 1'\n",
+		"syntax error": "def model(ctx)\n    return 'SELECT 1'\n",
+		"bad config":   "\"\"\"---\nmaterialized: [table]\n---\"\"\"\ndef model(ctx):\n    return 'SELECT 1'\n",
+	} {
+		if _, err := p.ParseStarlark("/models/x.star", content); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
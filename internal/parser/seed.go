@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/adapter"
+	"gopkg.in/yaml.v3"
+)
+
+// seedConfigSuffixes are the config file suffixes looked up next to a seed,
+// e.g. seeds/raw_customers.csv -> seeds/raw_customers.yml.
+var seedConfigSuffixes = []string{".yml", ".yaml"}
+
+// IsSeedFile reports whether a file name has an extension the adapter loads
+// (see adapter.FileFormat).
+func IsSeedFile(name string) bool {
+	return adapter.FileFormat(name) != ""
+}
+
+// SeedConfig configures how a seed file is loaded.
+// It is read from a YAML file with the same base name as the seed.
+type SeedConfig struct {
+	// Schema is the target schema (default: unqualified table)
+	Schema string `yaml:"schema"`
+	// Delimiter is the field separator (default: auto-detected)
+	Delimiter string `yaml:"delimiter"`
+	// Quote is the quoting character (default: auto-detected)
+	Quote string `yaml:"quote"`
+	// Header indicates whether the first line holds column names (default: true)
+	Header *bool `yaml:"header"`
+	// NullStrings are values loaded as NULL
+	NullStrings []string `yaml:"null_strings"`
+	// DateFormat is a strftime-style format for DATE columns
+	DateFormat string `yaml:"date_format"`
+	// TimestampFormat is a strftime-style format for TIMESTAMP columns
+	TimestampFormat string `yaml:"timestamp_format"`
+	// ColumnTypes overrides inferred column types, e.g. {zip: VARCHAR}
+	ColumnTypes map[string]string `yaml:"column_types"`
+	// FullRefresh drops and recreates the table on every load
+	FullRefresh bool `yaml:"full_refresh"`
+}
+
+// SeedConfigPath returns the config file path for a seed file, or "" if none exists.
+// For a multi-file seed (seeds/events/*.parquet) the config sits next to the
+// directory (seeds/events.yml).
+func SeedConfigPath(seedPath string) string {
+	base := strings.TrimSuffix(seedPath, filepath.Ext(seedPath))
+	if strings.ContainsAny(seedPath, "*?[") {
+		base = filepath.Dir(seedPath)
+	}
+	for _, suffix := range seedConfigSuffixes {
+		path := base + suffix
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadSeedConfig loads the config for a seed file.
+// A seed without a config file gets an empty config.
+func LoadSeedConfig(seedPath string) (*SeedConfig, error) {
+	path := SeedConfigPath(seedPath)
+	if path == "" {
+		return &SeedConfig{}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed config %s: %w", path, err)
+	}
+
+	return ParseSeedConfig(path, content)
+}
+
+// ParseSeedConfig parses seed config YAML. Unknown fields are rejected.
+func ParseSeedConfig(path string, content []byte) (*SeedConfig, error) {
+	config := &SeedConfig{}
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("invalid seed config: %v", err)}
+	}
+
+	if len([]rune(config.Quote)) > 1 {
+		return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("quote must be a single character, got %q", config.Quote)}
+	}
+	for col, typ := range config.ColumnTypes {
+		if strings.TrimSpace(typ) == "" {
+			return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("column_types: empty type for column %q", col)}
+		}
+	}
+
+	return config, nil
+}
+
+// ScanSeeds finds all seeds in a directory and returns them as seed nodes that
+// can be registered alongside models. Seeds are CSV, Parquet, JSON, NDJSON or
+// Excel files; a subdirectory of files with a single extension is one
+// multi-file seed whose FilePath is a glob (seeds/events/*.parquet).
+// A seed's path is its table name, qualified with the configured schema if
+// any (e.g. "raw.customers"). A missing directory yields no seeds.
+func ScanSeeds(dir string) ([]*ModelConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read seeds directory: %w", err)
+	}
+
+	var seeds []*ModelConfig
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		var name, filePath string
+		if entry.IsDir() {
+			ext, err := multiFileSeedExt(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if ext == "" {
+				continue
+			}
+			name = entry.Name()
+			filePath = filepath.Join(dir, entry.Name(), "*"+ext)
+		} else {
+			if !IsSeedFile(entry.Name()) {
+				continue
+			}
+			name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			filePath = filepath.Join(dir, entry.Name())
+		}
+
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("seed %q is defined by both %s and %s", name, other, filePath)
+		}
+		seen[name] = filePath
+
+		cfg, err := LoadSeedConfig(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		path := name
+		if cfg.Schema != "" {
+			path = cfg.Schema + "." + name
+		}
+
+		seeds = append(seeds, &ModelConfig{
+			Path:         path,
+			Name:         name,
+			FilePath:     filePath,
+			Materialized: "seed",
+			Schema:       cfg.Schema,
+			Seed:         cfg,
+		})
+	}
+
+	return seeds, nil
+}
+
+// multiFileSeedExt returns the extension shared by the seed files in a
+// directory in lower case, or "" if it holds none. Mixing formats in one
+// seed is an error.
+func multiFileSeedExt(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read seed directory %s: %w", dir, err)
+	}
+
+	ext := ""
+	for _, entry := range entries {
+		if entry.IsDir() || !IsSeedFile(entry.Name()) {
+			continue
+		}
+		e := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != "" && e != ext {
+			return "", fmt.Errorf("seed directory %s mixes %s and %s files", dir, ext, e)
+		}
+		ext = e
+	}
+	return ext, nil
+}
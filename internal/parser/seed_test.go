@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSeedConfig(t *testing.T) {
+	content := `schema: raw
+delimiter: ";"
+quote: "'"
+header: false
+null_strings: ["", "NA"]
+date_format: "%d/%m/%Y"
+column_types:
+  zip: VARCHAR
+full_refresh: true
+`
+
+	cfg, err := ParseSeedConfig("raw_customers.yml", []byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Schema != "raw" || cfg.Delimiter != ";" || cfg.Quote != "'" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.Header == nil || *cfg.Header {
+		t.Errorf("expected header false, got %v", cfg.Header)
+	}
+	if len(cfg.NullStrings) != 2 || cfg.NullStrings[1] != "NA" {
+		t.Errorf("unexpected null strings: %v", cfg.NullStrings)
+	}
+	if cfg.DateFormat != "%d/%m/%Y" {
+		t.Errorf("unexpected date format: %q", cfg.DateFormat)
+	}
+	if cfg.ColumnTypes["zip"] != "VARCHAR" {
+		t.Errorf("unexpected column types: %v", cfg.ColumnTypes)
+	}
+	if !cfg.FullRefresh {
+		t.Error("expected full_refresh true")
+	}
+}
+
+func TestParseSeedConfig_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": "delimeter: ';'\n",
+		"long quote":    "quote: \"''\"\n",
+		"empty type":    "column_types:\n  zip: ''\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSeedConfig("s.yml", []byte(content))
+			if _, ok := err.(*FrontmatterParseError); !ok {
+				t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestLoadSeedConfig(t *testing.T) {
+	dir := t.TempDir()
+	seedPath := filepath.Join(dir, "zips.csv")
+
+	cfg, err := LoadSeedConfig(seedPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Schema != "" || cfg.ColumnTypes != nil {
+		t.Errorf("expected empty config without file, got %+v", cfg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "zips.yaml"), []byte("schema: ref\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err = LoadSeedConfig(seedPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Schema != "ref" {
+		t.Errorf("expected schema 'ref', got %q", cfg.Schema)
+	}
+}
+
+func TestScanSeeds(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"raw_customers.csv": "id\n1\n",
+		"raw_orders.csv":    "id\n1\n",
+		"raw_orders.yml":    "schema: raw\n",
+		"notes.txt":         "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seeds, err := ScanSeeds(dir)
+	if err != nil {
+		t.Fatalf("ScanSeeds() failed: %v", err)
+	}
+	if len(seeds) != 2 {
+		t.Fatalf("expected 2 seeds, got %d", len(seeds))
+	}
+
+	if s := seeds[0]; s.Path != "raw_customers" || s.Name != "raw_customers" || s.Materialized != "seed" {
+		t.Errorf("unexpected seed: %+v", s)
+	}
+	if s := seeds[1]; s.Path != "raw.raw_orders" || s.Schema != "raw" || s.Seed == nil {
+		t.Errorf("unexpected seed: %+v", s)
+	}
+
+	missing, err := ScanSeeds(filepath.Join(dir, "missing"))
+	if err != nil || missing != nil {
+		t.Errorf("ScanSeeds() on missing dir = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestScanSeeds_FormatsAndDirectories(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rates.json":            "[]",
+		"events.ndjson":         "",
+		"zips.parquet":          "",
+		"orders/2024.parquet":   "",
+		"orders/2025.PARQUET":   "",
+		"orders.yml":            "schema: raw\n",
+		"empty/readme.txt":      "no seeds here",
+		"mixed/a.csv":           "",
+		"mixed/b.parquet":       "",
+		"mixed_ignored/.hidden": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ScanSeeds(dir); err == nil {
+		t.Fatal("expected error for a directory mixing formats")
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "mixed")); err != nil {
+		t.Fatal(err)
+	}
+
+	seeds, err := ScanSeeds(dir)
+	if err != nil {
+		t.Fatalf("ScanSeeds() failed: %v", err)
+	}
+
+	byPath := make(map[string]*ModelConfig)
+	for _, s := range seeds {
+		byPath[s.Path] = s
+	}
+	if len(byPath) != 4 {
+		t.Fatalf("expected 4 seeds, got %v", byPath)
+	}
+	for _, path := range []string{"rates", "events", "zips"} {
+		if byPath[path] == nil {
+			t.Errorf("seed %q not found", path)
+		}
+	}
+
+	orders := byPath["raw.orders"]
+	if orders == nil {
+		t.Fatal("multi-file seed raw.orders not found")
+	}
+	if want := filepath.Join(dir, "orders", "*.parquet"); orders.FilePath != want {
+		t.Errorf("FilePath = %q, want %q", orders.FilePath, want)
+	}
+
+	// Same name in two formats is ambiguous
+	if err := os.WriteFile(filepath.Join(dir, "rates.csv"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanSeeds(dir); err == nil {
+		t.Error("expected error for duplicate seed name")
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.starlark.net/syntax"
+)
+
+// StarlarkModelFunc is the function a .star model defines. It is called with
+// a ctx struct and returns SQL text or a list of row dicts.
+const StarlarkModelFunc = "model"
+
+// starlarkFrontmatterPattern matches a leading """--- ... ---""" docstring,
+// the .star counterpart of the /*--- ---*/ block in SQL models.
+var starlarkFrontmatterPattern = regexp.MustCompile(`(?s)^\s*"""---\s*\n(.*?)\s*---"""`)
+
+// refCallPattern matches ctx.ref("schema.table") with a literal argument
+var refCallPattern = regexp.MustCompile(`\.ref\(\s*(?:"([^"]+)"|'([^']+)')\s*\)`)
+
+// ParseStarlark parses a Starlark model file. The file must define
+// model(ctx). Configuration comes from an optional frontmatter docstring:
+//
+//	"""---
+//	materialized: table
+//	---"""
+//
+// Dependencies are the literal arguments of ctx.ref() calls; the code is not
+// executed until the model runs.
+func (p *Parser) ParseStarlark(filePath string, content string) (*ModelConfig, error) {
+	config := &ModelConfig{
+		FilePath:     filePath,
+		Language:     LanguageStarlark,
+		RawContent:   content,
+		Materialized: "table",
+		Imports:      []string{},
+		Conditionals: []Conditional{},
+		Tags:         []string{},
+		Meta:         make(map[string]any),
+	}
+	config.Name = strings.TrimSuffix(filepath.Base(filePath), ".star")
+	config.Path = p.filePathToModelPath(filePath)
+
+	if match := starlarkFrontmatterPattern.FindStringSubmatch(content); match != nil {
+		fc, err := parseFrontmatterYAML(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+		config.HasFrontmatter = true
+		applyFrontmatter(config, fc)
+		config.HookSources = hookSources(append(config.PreHooks, config.PostHooks...), p.Dialect)
+	}
+
+	f, err := syntax.Parse(filePath, content, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !definesFunc(f, StarlarkModelFunc) {
+		return nil, fmt.Errorf("%s: no %s(ctx) function defined", filePath, StarlarkModelFunc)
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range refCallPattern.FindAllStringSubmatch(content, -1) {
+		name := match[1] + match[2]
+		if !seen[name] {
+			seen[name] = true
+			config.Sources = append(config.Sources, name)
+		}
+	}
+
+	return config, nil
+}
+
+// definesFunc reports whether a file defines a top-level function.
+func definesFunc(f *syntax.File, name string) bool {
+	for _, stmt := range f.Stmts {
+		if def, ok := stmt.(*syntax.DefStmt); ok && def.Name.Name == name {
+			return true
+		}
+	}
+	return false
+}
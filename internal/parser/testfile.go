@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SingularTest represents a .sql file in the tests directory.
+// The query returns failing rows; the test passes when it returns none.
+type SingularTest struct {
+	// Name is the test name (filename without extension)
+	Name string
+	// FilePath is the absolute path to the .sql file
+	FilePath string
+	// SQL is the test query after frontmatter removal
+	SQL string
+	// Description is an optional human-readable description
+	Description string
+	// Severity is "error" (default) or "warn"
+	Severity string
+}
+
+// testFileConfig represents the frontmatter allowed in singular test files.
+type testFileConfig struct {
+	Description string `yaml:"description"`
+	Severity    string `yaml:"severity"`
+}
+
+// ParseTestFile parses a singular test file.
+func ParseTestFile(filePath string) (*SingularTest, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file %s: %w", filePath, err)
+	}
+	return ParseTestContent(filePath, string(content))
+}
+
+// ParseTestContent parses singular test content. An optional frontmatter block
+// may set "description" and "severity".
+func ParseTestContent(filePath, content string) (*SingularTest, error) {
+	test := &SingularTest{
+		Name:     strings.TrimSuffix(filepath.Base(filePath), ".sql"),
+		FilePath: filePath,
+		SQL:      strings.TrimSpace(content),
+		Severity: SeverityError,
+	}
+
+	matches := frontmatterPattern.FindStringSubmatch(content)
+	if matches == nil {
+		return test, nil
+	}
+	test.SQL = strings.TrimSpace(frontmatterPattern.ReplaceAllString(content, ""))
+
+	var raw map[string]any
+	if err := yaml.Unmarshal([]byte(matches[1]), &raw); err != nil {
+		return nil, &FrontmatterParseError{File: filePath, Message: fmt.Sprintf("invalid YAML: %v", err)}
+	}
+	for field := range raw {
+		if field != "description" && field != "severity" {
+			return nil, &FrontmatterParseError{
+				File:    filePath,
+				Message: fmt.Sprintf("unknown field %q in test frontmatter, must be one of: description, severity", field),
+			}
+		}
+	}
+
+	var cfg testFileConfig
+	if err := yaml.Unmarshal([]byte(matches[1]), &cfg); err != nil {
+		return nil, &FrontmatterParseError{File: filePath, Message: fmt.Sprintf("failed to parse frontmatter: %v", err)}
+	}
+	test.Description = cfg.Description
+	if cfg.Severity != "" {
+		if cfg.Severity != SeverityError && cfg.Severity != SeverityWarn {
+			return nil, &FrontmatterParseError{
+				File:    filePath,
+				Message: fmt.Sprintf("invalid severity %q, must be one of: error, warn", cfg.Severity),
+			}
+		}
+		test.Severity = cfg.Severity
+	}
+
+	return test, nil
+}
+
+// ScanTests recursively finds and parses all singular test files in a directory.
+// A missing directory yields no tests.
+func ScanTests(dir string) ([]*SingularTest, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var tests []*SingularTest
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".sql") {
+			return nil
+		}
+
+		test, err := ParseTestFile(path)
+		if err != nil {
+			return err
+		}
+		tests = append(tests, test)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tests, func(i, j int) bool { return tests[i].FilePath < tests[j].FilePath })
+	return tests, nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTestContent_Plain(t *testing.T) {
+	test, err := ParseTestContent("/tests/no_orphans.sql", "SELECT * FROM orders WHERE customer_id IS NULL\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if test.Name != "no_orphans" {
+		t.Errorf("expected name 'no_orphans', got %q", test.Name)
+	}
+	if test.Severity != SeverityError {
+		t.Errorf("expected default severity 'error', got %q", test.Severity)
+	}
+	if test.SQL != "SELECT * FROM orders WHERE customer_id IS NULL" {
+		t.Errorf("unexpected SQL: %q", test.SQL)
+	}
+}
+
+func TestParseTestContent_Frontmatter(t *testing.T) {
+	content := `/*---
+description: Orders must have a positive amount
+severity: warn
+---*/
+SELECT * FROM orders WHERE amount <= 0`
+
+	test, err := ParseTestContent("/tests/positive_amount.sql", content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if test.Severity != SeverityWarn {
+		t.Errorf("expected severity 'warn', got %q", test.Severity)
+	}
+	if test.Description != "Orders must have a positive amount" {
+		t.Errorf("unexpected description: %q", test.Description)
+	}
+	if test.SQL != "SELECT * FROM orders WHERE amount <= 0" {
+		t.Errorf("unexpected SQL: %q", test.SQL)
+	}
+}
+
+func TestParseTestContent_InvalidFrontmatter(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":    "/*---\nmaterialized: table\n---*/\nSELECT 1",
+		"invalid severity": "/*---\nseverity: fatal\n---*/\nSELECT 1",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTestContent("/tests/t.sql", content)
+			if _, ok := err.(*FrontmatterParseError); !ok {
+				t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestScanTests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.sql":         "SELECT 2",
+		"nested/a.sql":  "SELECT 1",
+		"notes.txt":     "ignored",
+		".hidden/x.sql": "SELECT 3",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	tests, err := ScanTests(dir)
+	if err != nil {
+		t.Fatalf("ScanTests failed: %v", err)
+	}
+	if len(tests) != 2 {
+		t.Fatalf("expected 2 tests, got %d", len(tests))
+	}
+	if tests[0].Name != "b" || tests[1].Name != "a" {
+		t.Errorf("unexpected order: %s, %s", tests[0].Name, tests[1].Name)
+	}
+
+	missing, err := ScanTests(filepath.Join(dir, "missing"))
+	if err != nil || missing != nil {
+		t.Errorf("expected no tests and no error for missing dir, got %v, %v", missing, err)
+	}
+}
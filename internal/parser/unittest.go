@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unitTestSuffixes are the fixture file suffixes looked up next to a model,
+// e.g. models/staging/stg_orders.sql -> models/staging/stg_orders.unit.yaml.
+var unitTestSuffixes = []string{".unit.yaml", ".unit.yml"}
+
+// UnitTestFile represents a unit test fixture file for a single model.
+type UnitTestFile struct {
+	Tests []UnitTestCase `yaml:"tests"`
+}
+
+// UnitTestCase declares fixture rows for a model's inputs and the rows the
+// model is expected to produce from them.
+type UnitTestCase struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Given maps an input table reference (as written in the SQL, or any name
+	// that resolves to the same model) to its fixture rows.
+	Given map[string][]map[string]any `yaml:"given"`
+	// Columns declares the columns and SQL types of fixtures, by the same
+	// keys as Given. A fixture without rows needs them to create its table;
+	// the values of other fixtures are cast to the declared types.
+	Columns map[string]map[string]string `yaml:"columns"`
+	// Expect contains the expected output rows. Only the columns listed in
+	// the expected rows are compared; row order is ignored.
+	Expect []map[string]any `yaml:"expect"`
+}
+
+// UnitTestPath returns the fixture file path for a model file, or "" if none exists.
+func UnitTestPath(modelFilePath string) string {
+	base := strings.TrimSuffix(modelFilePath, ".sql")
+	for _, suffix := range unitTestSuffixes {
+		path := base + suffix
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadUnitTests loads the unit test cases declared next to a model file.
+// A model without a fixture file has no unit tests.
+func LoadUnitTests(modelFilePath string) ([]UnitTestCase, error) {
+	path := UnitTestPath(modelFilePath)
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read unit tests %s: %w", path, err)
+	}
+
+	return ParseUnitTests(path, content)
+}
+
+// ParseUnitTests parses unit test fixture YAML.
+func ParseUnitTests(path string, content []byte) ([]UnitTestCase, error) {
+	var file UnitTestFile
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("invalid unit test file: %v", err)}
+	}
+
+	seen := make(map[string]bool, len(file.Tests))
+	for i, tc := range file.Tests {
+		if tc.Name == "" {
+			return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("tests[%d]: name is required", i)}
+		}
+		if seen[tc.Name] {
+			return nil, &FrontmatterParseError{File: path, Message: fmt.Sprintf("duplicate unit test %q", tc.Name)}
+		}
+		seen[tc.Name] = true
+
+		for input, rows := range tc.Given {
+			if len(rows) == 0 && len(tc.Columns[input]) == 0 {
+				return nil, &FrontmatterParseError{
+					File:    path,
+					Message: fmt.Sprintf("%s: fixture for %q has no rows; declare its columns under columns", tc.Name, input),
+				}
+			}
+		}
+		for input, cols := range tc.Columns {
+			if _, ok := tc.Given[input]; !ok {
+				return nil, &FrontmatterParseError{
+					File:    path,
+					Message: fmt.Sprintf("%s: columns for %q don't match a fixture under given", tc.Name, input),
+				}
+			}
+			for col, typ := range cols {
+				if typ == "" {
+					return nil, &FrontmatterParseError{
+						File:    path,
+						Message: fmt.Sprintf("%s: column %q of %q needs a type", tc.Name, col, input),
+					}
+				}
+			}
+		}
+	}
+
+	return file.Tests, nil
+}
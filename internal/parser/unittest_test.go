@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseUnitTests(t *testing.T) {
+	content := `tests:
+  - name: sums_amounts
+    given:
+      raw_orders:
+        - {id: 1, amount: 10}
+        - {id: 2, amount: 5}
+    expect:
+      - {total: 15}
+`
+
+	cases, err := ParseUnitTests("orders.unit.yaml", []byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("expected 1 case, got %d", len(cases))
+	}
+
+	tc := cases[0]
+	if tc.Name != "sums_amounts" {
+		t.Errorf("expected name 'sums_amounts', got %q", tc.Name)
+	}
+	if len(tc.Given["raw_orders"]) != 2 {
+		t.Errorf("expected 2 fixture rows, got %v", tc.Given["raw_orders"])
+	}
+	if len(tc.Expect) != 1 || tc.Expect[0]["total"] != 15 {
+		t.Errorf("unexpected expected rows: %v", tc.Expect)
+	}
+}
+
+func TestParseUnitTests_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"missing name":   "tests:\n  - given: {a: [{x: 1}]}\n",
+		"duplicate":      "tests:\n  - name: a\n  - name: a\n",
+		"empty fixture":  "tests:\n  - name: a\n    given: {raw: []}\n",
+		"unknown field":  "tests:\n  - name: a\n    inputs: {}\n",
+		"stray columns":  "tests:\n  - name: a\n    given: {raw: [{x: 1}]}\n    columns: {other: {x: INTEGER}}\n",
+		"untyped column": "tests:\n  - name: a\n    given: {raw: []}\n    columns: {raw: {x: \"\"}}\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseUnitTests("m.unit.yaml", []byte(content))
+			if _, ok := err.(*FrontmatterParseError); !ok {
+				t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestParseUnitTests_EmptyFixture(t *testing.T) {
+	content := "tests:\n  - name: a\n    given: {raw: []}\n    columns: {raw: {x: INTEGER}}\n"
+	cases, err := ParseUnitTests("m.unit.yaml", []byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cases[0].Columns["raw"]["x"] != "INTEGER" {
+		t.Errorf("unexpected columns: %v", cases[0].Columns)
+	}
+}
+
+func TestLoadUnitTests(t *testing.T) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "orders.sql")
+
+	cases, err := LoadUnitTests(modelPath)
+	if err != nil || cases != nil {
+		t.Fatalf("expected no cases without fixture file, got %v, %v", cases, err)
+	}
+
+	fixture := "tests:\n  - name: a\n    expect: []\n"
+	if err := os.WriteFile(filepath.Join(dir, "orders.unit.yml"), []byte(fixture), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	cases, err = LoadUnitTests(modelPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cases) != 1 || cases[0].Name != "a" {
+		t.Errorf("unexpected cases: %v", cases)
+	}
+}
@@ -0,0 +1,162 @@
+// Package project loads the project configuration file (leapsql.yaml).
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/lint"
+	"github.com/leapstack-labs/leapsql/internal/parser"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the project configuration file name.
+const DefaultConfigFile = "leapsql.yaml"
+
+// Config is the project configuration.
+type Config struct {
+	// Vars are project variables, read in templates with var(name, default)
+	Vars map[string]any `yaml:"vars"`
+	// OnRunStart are templated SQL statements run before the first model
+	OnRunStart parser.Hooks `yaml:"on_run_start"`
+	// OnRunEnd are templated SQL statements run after the last model
+	OnRunEnd parser.Hooks `yaml:"on_run_end"`
+	// Target describes the warehouse; its type selects the SQL dialect
+	Target *Target `yaml:"target"`
+	// Format is the layout used by leapsql fmt
+	Format *FormatConfig `yaml:"format"`
+	// Lint enables the rules of leapsql lint and sets their severity
+	Lint *lint.Config `yaml:"lint"`
+}
+
+// Target is the warehouse models are written for, exposed to templates as
+// target.type, target.schema and target.database.
+type Target struct {
+	// Type is the SQL dialect: duckdb, postgres, snowflake or bigquery
+	Type     string `yaml:"type"`
+	Schema   string `yaml:"schema"`
+	Database string `yaml:"database"`
+}
+
+// FormatConfig is the SQL layout of leapsql fmt. Unset fields take the
+// formatter's defaults.
+type FormatConfig struct {
+	// KeywordCase is upper or lower
+	KeywordCase string `yaml:"keyword_case"`
+	// Indent is the number of spaces per indentation level
+	Indent int `yaml:"indent"`
+	// CommaStyle is trailing or leading
+	CommaStyle string `yaml:"comma_style"`
+	// LineWidth is the line length past which lists and clauses break
+	LineWidth int `yaml:"line_width"`
+}
+
+// PrintOptions returns the formatter options for a dialect.
+func (f *FormatConfig) PrintOptions(dialect *lineage.Dialect) (lineage.PrintOptions, error) {
+	opts := lineage.PrintOptions{Dialect: dialect}
+	if f == nil {
+		return opts, nil
+	}
+	switch lineage.KeywordCase(f.KeywordCase) {
+	case "", lineage.KeywordUpper, lineage.KeywordLower:
+	default:
+		return opts, fmt.Errorf("invalid format.keyword_case %q: expected upper or lower", f.KeywordCase)
+	}
+	switch lineage.CommaStyle(f.CommaStyle) {
+	case "", lineage.CommaTrailing, lineage.CommaLeading:
+	default:
+		return opts, fmt.Errorf("invalid format.comma_style %q: expected trailing or leading", f.CommaStyle)
+	}
+	if f.Indent < 0 {
+		return opts, fmt.Errorf("invalid format.indent %d: expected a positive number", f.Indent)
+	}
+	if f.LineWidth < 0 {
+		return opts, fmt.Errorf("invalid format.line_width %d: expected a positive number", f.LineWidth)
+	}
+	opts.KeywordCase = lineage.KeywordCase(f.KeywordCase)
+	opts.CommaStyle = lineage.CommaStyle(f.CommaStyle)
+	opts.Indent = f.Indent
+	opts.LineWidth = f.LineWidth
+	return opts, nil
+}
+
+// Load reads a project configuration file. A missing file yields an empty
+// configuration.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	cfg.Vars = normalizeVars(cfg.Vars)
+
+	return cfg, nil
+}
+
+// ParseVars parses a --vars value: a YAML or JSON mapping such as
+// '{start_date: 2024-01-01, end_date: 2024-01-31}'.
+func ParseVars(s string) (map[string]any, error) {
+	var vars map[string]any
+	if err := yaml.Unmarshal([]byte(s), &vars); err != nil {
+		return nil, fmt.Errorf("invalid vars %q: expected a mapping such as '{name: value}': %w", s, err)
+	}
+	return normalizeVars(vars), nil
+}
+
+// MergeVars returns base with the keys of override replacing its own.
+func MergeVars(base, override map[string]any) map[string]any {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// normalizeVars converts YAML timestamps back to strings, so that
+// start_date: 2024-01-01 renders as it was written.
+func normalizeVars(vars map[string]any) map[string]any {
+	for k, v := range vars {
+		vars[k] = normalizeValue(v)
+	}
+	return vars
+}
+
+// normalizeValue converts the timestamps in a decoded YAML value to strings.
+func normalizeValue(v any) any {
+	switch val := v.(type) {
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02")
+		}
+		return val.Format(time.RFC3339Nano)
+	case []any:
+		for i, item := range val {
+			val[i] = normalizeValue(item)
+		}
+		return val
+	case map[string]any:
+		return normalizeVars(val)
+	default:
+		return v
+	}
+}
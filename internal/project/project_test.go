@@ -0,0 +1,109 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, DefaultConfigFile)
+	content := `vars:
+  start_date: 2024-01-01
+  loaded_at: 2024-01-01T10:30:00Z
+  regions: [eu, us]
+  limits:
+    rows: 100
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := map[string]any{
+		"start_date": "2024-01-01",
+		"loaded_at":  "2024-01-01T10:30:00Z",
+		"regions":    []any{"eu", "us"},
+		"limits":     map[string]any{"rows": 100},
+	}
+	if !reflect.DeepEqual(cfg.Vars, want) {
+		t.Errorf("Vars = %#v, want %#v", cfg.Vars, want)
+	}
+
+	if cfg.Target != nil {
+		t.Errorf("Target = %+v, want nil", cfg.Target)
+	}
+
+	// Target selects the warehouse
+	if err := os.WriteFile(path, []byte("target:\n  type: snowflake\n  schema: analytics\n  database: prod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if want := (&Target{Type: "snowflake", Schema: "analytics", Database: "prod"}); !reflect.DeepEqual(cfg.Target, want) {
+		t.Errorf("Target = %+v, want %+v", cfg.Target, want)
+	}
+
+	// A missing file is an empty config
+	cfg, err = Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil || cfg.Vars != nil {
+		t.Errorf("Load() on missing file = %+v, %v", cfg, err)
+	}
+
+	// Unknown keys are rejected
+	if err := os.WriteFile(path, []byte("varz:\n  a: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "varz") {
+		t.Errorf("expected error for unknown key, got %v", err)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars(`{start_date: 2024-01-01, "end_date": "2024-01-31", full: true}`)
+	if err != nil {
+		t.Fatalf("ParseVars() failed: %v", err)
+	}
+	want := map[string]any{"start_date": "2024-01-01", "end_date": "2024-01-31", "full": true}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("ParseVars() = %v, want %v", vars, want)
+	}
+
+	if _, err := ParseVars(`[1, 2]`); err == nil {
+		t.Error("expected error for a non-mapping value")
+	}
+
+	merged := MergeVars(map[string]any{"a": 1, "b": 2}, map[string]any{"b": 3})
+	if !reflect.DeepEqual(merged, map[string]any{"a": 1, "b": 3}) {
+		t.Errorf("MergeVars() = %v", merged)
+	}
+}
+
+func TestFormatConfig_PrintOptions(t *testing.T) {
+	var unset *FormatConfig
+	if _, err := unset.PrintOptions(nil); err != nil {
+		t.Errorf("PrintOptions() of unset config error = %v", err)
+	}
+
+	opts, err := (&FormatConfig{KeywordCase: "lower", CommaStyle: "leading", Indent: 2, LineWidth: 100}).PrintOptions(nil)
+	if err != nil {
+		t.Fatalf("PrintOptions() error = %v", err)
+	}
+	if opts.KeywordCase != "lower" || opts.CommaStyle != "leading" || opts.Indent != 2 || opts.LineWidth != 100 {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	for _, cfg := range []*FormatConfig{{KeywordCase: "title"}, {CommaStyle: "none"}, {Indent: -1}} {
+		if _, err := cfg.PrintOptions(nil); err == nil {
+			t.Errorf("PrintOptions() of %+v should fail", cfg)
+		}
+	}
+}
@@ -81,6 +81,20 @@ func (r *ModelRegistry) Resolve(tableName string) (modelPath string, isModel boo
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if path, ok := r.resolve(tableName); ok {
+		return path, true
+	}
+
+	// Lineage reports unquoted names in the dialect's case, e.g. upper case
+	// for Snowflake, while model names follow their file names
+	if lower := strings.ToLower(tableName); lower != tableName {
+		return r.resolve(lower)
+	}
+	return "", false
+}
+
+// resolve looks up a table name as written. The caller holds r.mu.
+func (r *ModelRegistry) resolve(tableName string) (string, bool) {
 	// 1. Try exact match on path
 	if _, ok := r.byPath[tableName]; ok {
 		return tableName, true
@@ -65,6 +65,13 @@ func TestModelRegistry_Resolve(t *testing.T) {
 			wantPath:  "staging.stg_customers",
 			wantFound: true,
 		},
+		// Upper-case name, as Snowflake lineage reports it
+		{
+			name:      "upper-case name",
+			tableName: "STAGING.STG_CUSTOMERS",
+			wantPath:  "staging.stg_customers",
+			wantFound: true,
+		},
 		// Non-existent model
 		{
 			name:      "non-existent model",
@@ -1,6 +1,7 @@
 package starlark
 
 import (
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
 	"go.starlark.net/starlark"
 )
 
@@ -59,13 +60,15 @@ func EnvToStarlark(env string) starlark.Value {
 }
 
 // Predeclared returns all predeclared/builtin globals for template execution.
-// This includes: config, env, target, this
-// Note: Macros are added separately via the macro loader.
+// This includes: config, env, target, this, the standard library modules
+// (datetime, json, re, math, hashing) and the run_query, adapter, relation
+// and var builtins.
+// Note: Macros are added separately via the macro loader and may shadow
+// standard library modules.
 func Predeclared(config starlark.Value, env string, target *TargetInfo, this *ThisInfo) starlark.StringDict {
-	globals := starlark.StringDict{
-		"config": config,
-		"env":    EnvToStarlark(env),
-	}
+	globals := stdlib.Globals()
+	globals["config"] = config
+	globals["env"] = EnvToStarlark(env)
 
 	if target != nil {
 		globals["target"] = target.ToStarlark()
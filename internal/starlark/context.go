@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/leapstack-labs/leapsql/internal/macro"
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
 	"go.starlark.net/starlark"
 )
 
@@ -24,13 +25,29 @@ type ExecutionContext struct {
 	Target *TargetInfo
 
 	// This contains current model info
-	// Accessible as: this.name, this.schema
+	// Accessible as: this.name, this.schema, this.relation
 	This *ThisInfo
 
 	// Macros contains loaded macro namespaces
 	// Each key is a namespace (e.g., "datetime") with a struct of functions
 	Macros starlark.StringDict
 
+	// Clock is the time source for datetime.now() and datetime.today().
+	// It is read once when the context is created, so every expression in a
+	// render sees the same instant.
+	Clock stdlib.Clock
+
+	// Introspection backs run_query() and adapter.get_columns().
+	// Nil disables them, as in parse-only commands.
+	Introspection *stdlib.Introspection
+
+	// Relations resolves relation() calls and records the names used.
+	// Nil emits names unchanged.
+	Relations *stdlib.Relations
+
+	// Vars are the project variables read by var(name, default)
+	Vars starlark.StringDict
+
 	// globals is the combined set of all globals for execution
 	globals starlark.StringDict
 
@@ -47,10 +64,19 @@ func NewExecutionContext(config starlark.Value, env string, target *TargetInfo,
 		This:   this,
 		Macros: make(starlark.StringDict),
 	}
+	ctx.freezeClock()
 	ctx.buildGlobals()
 	return ctx
 }
 
+// freezeClock replaces Clock with a fixed clock at its current time.
+func (ctx *ExecutionContext) freezeClock() {
+	if ctx.Clock == nil {
+		ctx.Clock = stdlib.DefaultClock
+	}
+	ctx.Clock = stdlib.FixedClock(ctx.Clock())
+}
+
 // buildGlobals constructs the combined globals dict.
 func (ctx *ExecutionContext) buildGlobals() {
 	ctx.mu.Lock()
@@ -160,15 +186,32 @@ func (ctx *ExecutionContext) EvalExprStringWithLocals(expr string, filename stri
 	}
 }
 
+// ExecFile executes a Starlark file, such as a .star model, with the
+// context's globals predeclared and returns the globals it defines.
+func (ctx *ExecutionContext) ExecFile(filename string, src any) (starlark.StringDict, error) {
+	return starlark.ExecFile(ctx.newThread(filename), filename, src, ctx.Globals())
+}
+
+// Call calls a Starlark function, such as a custom materialization, on a
+// thread set up like the ones used to render templates.
+func (ctx *ExecutionContext) Call(name string, fn starlark.Callable, args starlark.Tuple) (starlark.Value, error) {
+	return starlark.Call(ctx.newThread(name), fn, args, nil)
+}
+
 // newThread creates a new Starlark thread for execution.
 func (ctx *ExecutionContext) newThread(name string) *starlark.Thread {
-	return &starlark.Thread{
+	thread := &starlark.Thread{
 		Name: name,
 		Print: func(thread *starlark.Thread, msg string) {
 			// Template execution should not print - this is a no-op
 			// In the future, we could capture prints for debugging
 		},
 	}
+	stdlib.SetClock(thread, ctx.Clock)
+	stdlib.SetIntrospection(thread, ctx.Introspection)
+	stdlib.SetRelations(thread, ctx.Relations)
+	stdlib.SetVars(thread, ctx.Vars)
+	return thread
 }
 
 // EvalError represents an error during Starlark expression evaluation.
@@ -206,6 +249,34 @@ func WithMacroRegistry(registry *macro.Registry) ContextOption {
 	}
 }
 
+// WithClock sets the time source used by the datetime module.
+func WithClock(clock stdlib.Clock) ContextOption {
+	return func(ctx *ExecutionContext) {
+		ctx.Clock = clock
+	}
+}
+
+// WithIntrospection enables run_query() and adapter.get_columns().
+func WithIntrospection(in *stdlib.Introspection) ContextOption {
+	return func(ctx *ExecutionContext) {
+		ctx.Introspection = in
+	}
+}
+
+// WithRelations sets the resolver and recorder for relation().
+func WithRelations(r *stdlib.Relations) ContextOption {
+	return func(ctx *ExecutionContext) {
+		ctx.Relations = r
+	}
+}
+
+// WithVars sets the project variables read by var().
+func WithVars(vars starlark.StringDict) ContextOption {
+	return func(ctx *ExecutionContext) {
+		ctx.Vars = vars
+	}
+}
+
 // NewContext creates a new execution context with functional options.
 // This is an alternative constructor that uses the options pattern.
 func NewContext(config starlark.Value, env string, target *TargetInfo, this *ThisInfo, opts ...ContextOption) *ExecutionContext {
@@ -221,6 +292,7 @@ func NewContext(config starlark.Value, env string, target *TargetInfo, this *Thi
 		opt(ctx)
 	}
 
+	ctx.freezeClock()
 	ctx.buildGlobals()
 	return ctx
 }
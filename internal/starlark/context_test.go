@@ -1,9 +1,13 @@
 package starlark
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/leapstack-labs/leapsql/internal/macro"
+	"github.com/leapstack-labs/leapsql/internal/starlark/stdlib"
 	"go.starlark.net/starlark"
 )
 
@@ -319,3 +323,60 @@ func TestNewContext_WithMacroRegistry_Nil(t *testing.T) {
 		t.Error("env not found")
 	}
 }
+
+func TestNewContext_WithClock(t *testing.T) {
+	dir := t.TempDir()
+	macroContent := `
+def partition():
+    return "dt = '" + datetime.format(datetime.add(datetime.now(), days=-1), "%Y-%m-%d") + "'"
+`
+	if err := os.WriteFile(filepath.Join(dir, "utils.star"), []byte(macroContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := macro.LoadAndRegister(dir)
+	if err != nil {
+		t.Fatalf("failed to load macros: %v", err)
+	}
+
+	clock := stdlib.FixedClock(time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC))
+	ctx := NewContext(starlark.NewDict(0), "dev", nil, nil, WithMacroRegistry(registry), WithClock(clock))
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`datetime.today()`, "2024-03-15"},
+		{`utils.partition()`, "dt = '2024-03-14'"},
+		{`hashing.md5(json.encode([1]))`, "35dba5d75538a9bbe0b4da4422759a0e"},
+	}
+	for _, tt := range tests {
+		got, err := ctx.EvalExprString(tt.expr, "test.sql", 1)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestNewContext_FreezesClock(t *testing.T) {
+	calls := 0
+	clock := func() time.Time {
+		calls++
+		return time.Date(2024, 1, 1, 0, 0, calls, 0, time.UTC)
+	}
+	ctx := NewContext(starlark.NewDict(0), "dev", nil, nil, WithClock(clock))
+
+	first, err := ctx.EvalExprString(`datetime.format(datetime.now(), "%S")`, "test.sql", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctx.EvalExprString(`datetime.format(datetime.now(), "%S")`, "test.sql", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || calls != 1 {
+		t.Errorf("expected a single frozen instant, got %s and %s after %d clock reads", first, second, calls)
+	}
+}
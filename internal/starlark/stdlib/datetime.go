@@ -0,0 +1,247 @@
+package stdlib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// DatetimeModule provides date arithmetic and strftime-style formatting.
+// Values are the time objects of go.starlark.net/lib/time, so they support
+// comparison, subtraction and attributes such as .year and .unix.
+//
+//	datetime.now()                          current time from the thread clock (UTC)
+//	datetime.today()                        current date as "YYYY-MM-DD"
+//	datetime.date(year, month, day)         midnight UTC on a date
+//	datetime.from_timestamp(secs)           time from Unix seconds
+//	datetime.parse(s, format=None)          parse with a strftime format
+//	datetime.format(t, format)              format with a strftime format
+//	datetime.add(t, years=, months=, days=, hours=, minutes=, seconds=)
+//	datetime.diff(a, b, unit="days")        a - b in whole units
+//	datetime.truncate(t, unit)              start of the year, month, day or hour
+var DatetimeModule = &starlarkstruct.Module{
+	Name: "datetime",
+	Members: starlark.StringDict{
+		"now":            starlark.NewBuiltin("datetime.now", datetimeNow),
+		"today":          starlark.NewBuiltin("datetime.today", datetimeToday),
+		"date":           starlark.NewBuiltin("datetime.date", datetimeDate),
+		"from_timestamp": starlark.NewBuiltin("datetime.from_timestamp", datetimeFromTimestamp),
+		"parse":          starlark.NewBuiltin("datetime.parse", datetimeParse),
+		"format":         starlark.NewBuiltin("datetime.format", datetimeFormat),
+		"add":            starlark.NewBuiltin("datetime.add", datetimeAdd),
+		"diff":           starlark.NewBuiltin("datetime.diff", datetimeDiff),
+		"truncate":       starlark.NewBuiltin("datetime.truncate", datetimeTruncate),
+	},
+}
+
+// defaultParseLayouts are tried in order when parse is called without a format.
+var defaultParseLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// strftimeDirectives maps strftime directives to Go layout fragments.
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'f': "000000", // fractional seconds when parsing, after a dot
+	'p': "PM",
+	'b': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+func datetimeNow(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlarktime.Time(now(thread).UTC()), nil
+}
+
+func datetimeToday(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(now(thread).UTC().Format("2006-01-02")), nil
+}
+
+func datetimeDate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var year, month, day int
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "year", &year, "month", &month, "day", &day); err != nil {
+		return nil, err
+	}
+	return starlarktime.Time(time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)), nil
+}
+
+func datetimeFromTimestamp(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var secs int64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &secs); err != nil {
+		return nil, err
+	}
+	return starlarktime.Time(time.Unix(secs, 0).UTC()), nil
+}
+
+func datetimeParse(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	var format starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "s", &s, "format?", &format); err != nil {
+		return nil, err
+	}
+
+	layouts := defaultParseLayouts
+	if format != starlark.None {
+		f, ok := starlark.AsString(format)
+		if !ok {
+			return nil, fmt.Errorf("%s: format must be a string, got %s", b.Name(), format.Type())
+		}
+		layout, err := strftimeLayout(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		layouts = []string{layout}
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return starlarktime.Time(t.UTC()), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: cannot parse %q", b.Name(), s)
+}
+
+func datetimeFormat(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var t starlarktime.Time
+	var format string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "t", &t, "format", &format); err != nil {
+		return nil, err
+	}
+	s, err := strftime(time.Time(t), format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(s), nil
+}
+
+func datetimeAdd(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var t starlarktime.Time
+	var years, months, days, hours, minutes, seconds int
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"t", &t, "years?", &years, "months?", &months, "days?", &days,
+		"hours?", &hours, "minutes?", &minutes, "seconds?", &seconds); err != nil {
+		return nil, err
+	}
+	result := time.Time(t).AddDate(years, months, days).Add(
+		time.Duration(hours)*time.Hour +
+			time.Duration(minutes)*time.Minute +
+			time.Duration(seconds)*time.Second)
+	return starlarktime.Time(result), nil
+}
+
+func datetimeDiff(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var a, c starlarktime.Time
+	unit := "days"
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "a", &a, "b", &c, "unit?", &unit); err != nil {
+		return nil, err
+	}
+
+	d := time.Time(a).Sub(time.Time(c))
+	var per time.Duration
+	switch unit {
+	case "days":
+		per = 24 * time.Hour
+	case "hours":
+		per = time.Hour
+	case "minutes":
+		per = time.Minute
+	case "seconds":
+		per = time.Second
+	default:
+		return nil, fmt.Errorf("%s: unknown unit %q (expected days, hours, minutes or seconds)", b.Name(), unit)
+	}
+	return starlark.MakeInt64(int64(d / per)), nil
+}
+
+func datetimeTruncate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var t starlarktime.Time
+	var unit string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "t", &t, "unit", &unit); err != nil {
+		return nil, err
+	}
+
+	tt := time.Time(t)
+	y, m, d := tt.Date()
+	var result time.Time
+	switch unit {
+	case "year":
+		result = time.Date(y, 1, 1, 0, 0, 0, 0, tt.Location())
+	case "month":
+		result = time.Date(y, m, 1, 0, 0, 0, 0, tt.Location())
+	case "day":
+		result = time.Date(y, m, d, 0, 0, 0, 0, tt.Location())
+	case "hour":
+		result = time.Date(y, m, d, tt.Hour(), 0, 0, 0, tt.Location())
+	default:
+		return nil, fmt.Errorf("%s: unknown unit %q (expected year, month, day or hour)", b.Name(), unit)
+	}
+	return starlarktime.Time(result), nil
+}
+
+// strftime formats t according to a strftime format string. Literal text is
+// copied verbatim, so digits in the format are never mistaken for Go layout
+// elements.
+func strftime(t time.Time, format string) (string, error) {
+	return expandStrftime(format, func(directive byte, layout string) string {
+		if directive == 'f' {
+			// Go reads 000000 as microseconds only after a dot
+			return fmt.Sprintf("%06d", t.Nanosecond()/1000)
+		}
+		return t.Format(layout)
+	})
+}
+
+// strftimeLayout converts a strftime format string into a Go time layout.
+func strftimeLayout(format string) (string, error) {
+	return expandStrftime(format, func(_ byte, layout string) string { return layout })
+}
+
+// expandStrftime copies literal text from format and replaces each directive
+// with expand applied to it and its Go layout fragment.
+func expandStrftime(format string, expand func(directive byte, layout string) string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+		if i+1 >= len(format) {
+			return "", fmt.Errorf("format ends with a lone %%")
+		}
+		i++
+		if format[i] == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+		layout, ok := strftimeDirectives[format[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported directive %%%c", format[i])
+		}
+		sb.WriteString(expand(format[i], layout))
+	}
+	return sb.String(), nil
+}
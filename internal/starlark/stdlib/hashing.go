@@ -0,0 +1,76 @@
+package stdlib
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// HashingModule provides hex digests and surrogate key generation.
+//
+//	hashing.md5(s), hashing.sha1(s), hashing.sha256(s)  hex digest of a string
+//	hashing.surrogate_key(col, ...)  SQL expression hashing the given columns
+var HashingModule = &starlarkstruct.Module{
+	Name: "hashing",
+	Members: starlark.StringDict{
+		"md5":           digestBuiltin("md5", md5.New),
+		"sha1":          digestBuiltin("sha1", sha1.New),
+		"sha256":        digestBuiltin("sha256", sha256.New),
+		"surrogate_key": starlark.NewBuiltin("hashing.surrogate_key", surrogateKey),
+	},
+}
+
+// digestBuiltin returns a builtin computing the hex digest of its string argument.
+func digestBuiltin(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin("hashing."+name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var s string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write([]byte(s))
+		return starlark.String(hex.EncodeToString(h.Sum(nil))), nil
+	})
+}
+
+// surrogateKey builds a SQL expression that hashes columns into a stable key.
+// NULLs are replaced by a sentinel so that a NULL and an empty string differ.
+func surrogateKey(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if len(kwargs) > 0 {
+		return nil, fmt.Errorf("%s: unexpected keyword arguments", b.Name())
+	}
+
+	var cols []string
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case starlark.String:
+			cols = append(cols, string(v))
+		case *starlark.List:
+			for i := 0; i < v.Len(); i++ {
+				s, ok := starlark.AsString(v.Index(i))
+				if !ok {
+					return nil, fmt.Errorf("%s: column names must be strings, got %s", b.Name(), v.Index(i).Type())
+				}
+				cols = append(cols, s)
+			}
+		default:
+			return nil, fmt.Errorf("%s: column names must be strings, got %s", b.Name(), arg.Type())
+		}
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("%s: at least one column is required", b.Name())
+	}
+
+	parts := make([]string, len(cols))
+	for i, col := range cols {
+		parts[i] = fmt.Sprintf("coalesce(cast(%s as varchar), '_null_')", col)
+	}
+	return starlark.String(fmt.Sprintf("md5(%s)", strings.Join(parts, " || '-' || "))), nil
+}
@@ -0,0 +1,229 @@
+package stdlib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/adapter"
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Warehouse is the database access behind run_query() and adapter.get_columns().
+// adapter.Adapter satisfies it.
+type Warehouse interface {
+	Query(ctx context.Context, sql string) (*adapter.Rows, error)
+	GetTableMetadata(ctx context.Context, table string) (*adapter.Metadata, error)
+}
+
+// introspectionKey is the thread-local key holding the thread's Introspection.
+const introspectionKey = "leapsql.introspection"
+
+// Introspection lets templates and macros read from the warehouse. Results
+// are cached by query text and relation name, so create one per run: every
+// model rendered in the run sees the same answer for the same question.
+type Introspection struct {
+	ctx context.Context
+	db  Warehouse
+
+	mu      sync.Mutex
+	queries map[string]starlark.Value
+	columns map[string]starlark.Value
+}
+
+// NewIntrospection creates an introspection cache backed by db.
+func NewIntrospection(ctx context.Context, db Warehouse) *Introspection {
+	return &Introspection{
+		ctx:     ctx,
+		db:      db,
+		queries: make(map[string]starlark.Value),
+		columns: make(map[string]starlark.Value),
+	}
+}
+
+// SetIntrospection enables run_query() and adapter on a thread. Threads
+// without one (parse-only commands, unit tests) report an error instead of
+// touching the database.
+func SetIntrospection(thread *starlark.Thread, in *Introspection) {
+	thread.SetLocal(introspectionKey, in)
+}
+
+// introspection returns the thread's Introspection or an error naming fn.
+func introspection(thread *starlark.Thread, fn string) (*Introspection, error) {
+	if in, ok := thread.Local(introspectionKey).(*Introspection); ok && in != nil {
+		return in, nil
+	}
+	return nil, fmt.Errorf("%s: no database connection (only available while running models or tests)", fn)
+}
+
+// AdapterModule exposes warehouse metadata.
+//
+//	adapter.get_columns(relation)  columns of a table as structs with
+//	                               name, type, nullable and position
+var AdapterModule = &starlarkstruct.Module{
+	Name: "adapter",
+	Members: starlark.StringDict{
+		"get_columns": starlark.NewBuiltin("adapter.get_columns", adapterGetColumns),
+	},
+}
+
+// RunQuery is the run_query(sql) builtin. It returns a struct with columns
+// (a tuple of names) and rows (a tuple of tuples).
+var RunQuery = starlark.NewBuiltin("run_query", runQuery)
+
+func runQuery(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var sql string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "sql", &sql); err != nil {
+		return nil, err
+	}
+	in, err := introspection(thread, b.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if v, ok := in.queries[sql]; ok {
+		return v, nil
+	}
+
+	v, err := queryValue(in.ctx, in.db, sql)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	v.Freeze()
+	in.queries[sql] = v
+	return v, nil
+}
+
+// queryValue runs sql and converts the result to a Starlark struct.
+func queryValue(ctx context.Context, db Warehouse, sql string) (starlark.Value, error) {
+	rows, err := db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	names := make(starlark.Tuple, len(cols))
+	for i, col := range cols {
+		names[i] = starlark.String(col)
+	}
+
+	var result starlark.Tuple
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(starlark.Tuple, len(cols))
+		for i, v := range values {
+			row[i] = sqlValue(v)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"columns": names,
+		"rows":    result,
+	}), nil
+}
+
+func adapterGetColumns(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var relation string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "relation", &relation); err != nil {
+		return nil, err
+	}
+	in, err := introspection(thread, b.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if v, ok := in.columns[relation]; ok {
+		return v, nil
+	}
+
+	meta, err := in.db.GetTableMetadata(in.ctx, relation)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	v := columnsValue(meta)
+	v.Freeze()
+	in.columns[relation] = v
+	return v, nil
+}
+
+// columnsValue converts table metadata to a list of column structs.
+func columnsValue(meta *adapter.Metadata) *starlark.List {
+	cols := make([]starlark.Value, len(meta.Columns))
+	for i, col := range meta.Columns {
+		cols[i] = starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"name":     starlark.String(col.Name),
+			"type":     starlark.String(col.Type),
+			"nullable": starlark.Bool(col.Nullable),
+			"position": starlark.MakeInt(col.Position),
+		})
+	}
+	return starlark.NewList(cols)
+}
+
+// sqlValue converts a scanned database value to a Starlark value.
+func sqlValue(v any) starlark.Value {
+	switch val := v.(type) {
+	case nil:
+		return starlark.None
+	case bool:
+		return starlark.Bool(val)
+	case int:
+		return starlark.MakeInt(val)
+	case int8:
+		return starlark.MakeInt64(int64(val))
+	case int16:
+		return starlark.MakeInt64(int64(val))
+	case int32:
+		return starlark.MakeInt64(int64(val))
+	case int64:
+		return starlark.MakeInt64(val)
+	case uint8:
+		return starlark.MakeUint64(uint64(val))
+	case uint16:
+		return starlark.MakeUint64(uint64(val))
+	case uint32:
+		return starlark.MakeUint64(uint64(val))
+	case uint64:
+		return starlark.MakeUint64(val)
+	case *big.Int:
+		return starlark.MakeBigInt(val)
+	case float32:
+		return starlark.Float(val)
+	case float64:
+		return starlark.Float(val)
+	case string:
+		return starlark.String(val)
+	case []byte:
+		return starlark.String(val)
+	case time.Time:
+		return starlarktime.Time(val)
+	default:
+		if f, ok := adapter.Float64(val); ok {
+			return starlark.Float(f)
+		}
+		return starlark.String(fmt.Sprint(val))
+	}
+}
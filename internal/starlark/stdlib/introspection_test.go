@@ -0,0 +1,66 @@
+package stdlib
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/adapter"
+	"go.starlark.net/starlark"
+)
+
+func TestIntrospection(t *testing.T) {
+	ctx := context.Background()
+	db := adapter.NewDuckDBAdapter()
+	if err := db.Connect(ctx, adapter.Config{Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Exec(ctx, "CREATE TABLE orders AS SELECT * FROM (VALUES (1, 'placed', NULL), (2, 'shipped', 9.5)) t(id, status, amount)"); err != nil {
+		t.Fatal(err)
+	}
+
+	thread := &starlark.Thread{Name: "test"}
+	SetIntrospection(thread, NewIntrospection(ctx, db))
+	eval := func(expr string) string {
+		t.Helper()
+		v, err := starlark.Eval(thread, "test", expr, Globals())
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		return v.String()
+	}
+
+	query := `run_query("SELECT id, status, amount FROM orders ORDER BY id")`
+	if got := eval(query + ".columns"); got != `("id", "status", "amount")` {
+		t.Errorf("columns = %s", got)
+	}
+	if got := eval(query + ".rows"); got != `((1, "placed", None), (2, "shipped", 9.5))` {
+		t.Errorf("rows = %s", got)
+	}
+	if got := eval(`[c.name + ":" + c.type for c in adapter.get_columns("orders")]`); got != `["id:INTEGER", "status:VARCHAR", "amount:DECIMAL(2,1)"]` {
+		t.Errorf("get_columns = %s", got)
+	}
+
+	// Results are cached for the lifetime of the Introspection
+	if err := db.Exec(ctx, "DELETE FROM orders"); err != nil {
+		t.Fatal(err)
+	}
+	if got := eval(`len(` + query + `.rows)`); got != "2" {
+		t.Errorf("expected cached result with 2 rows, got %s", got)
+	}
+
+	if _, err := starlark.Eval(thread, "test", `run_query("SELECT * FROM missing")`, Globals()); err == nil {
+		t.Error("expected error for a failing query")
+	}
+}
+
+func TestIntrospection_Disabled(t *testing.T) {
+	thread := &starlark.Thread{Name: "test"}
+	for _, expr := range []string{`run_query("SELECT 1")`, `adapter.get_columns("orders")`} {
+		_, err := starlark.Eval(thread, "test", expr, Globals())
+		if err == nil || !strings.Contains(err.Error(), "no database connection") {
+			t.Errorf("%s: error = %v, want no database connection", expr, err)
+		}
+	}
+}
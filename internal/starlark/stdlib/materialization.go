@@ -0,0 +1,72 @@
+package stdlib
+
+import (
+	"context"
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Database is the warehouse access given to custom materializations.
+// adapter.Adapter satisfies it.
+type Database interface {
+	Warehouse
+	Exec(ctx context.Context, sql string) error
+}
+
+// NewAdapterHandle returns the adapter argument passed to a custom
+// materialization. Unlike run_query() and adapter.get_columns(), nothing is
+// cached: a materialization reads the warehouse it is changing.
+//
+//	adapter.exec(sql)                 run a statement
+//	adapter.query(sql)                .columns and .rows, as run_query()
+//	adapter.get_columns(relation)     columns of a table
+//	adapter.relation_exists(relation) whether a table or view exists
+func NewAdapterHandle(ctx context.Context, db Database) starlark.Value {
+	return &starlarkstruct.Module{
+		Name: "adapter",
+		Members: starlark.StringDict{
+			"exec": starlark.NewBuiltin("adapter.exec", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var sql string
+				if err := starlark.UnpackArgs(b.Name(), args, kwargs, "sql", &sql); err != nil {
+					return nil, err
+				}
+				if err := db.Exec(ctx, sql); err != nil {
+					return nil, fmt.Errorf("%s: %w", b.Name(), err)
+				}
+				return starlark.None, nil
+			}),
+			"query": starlark.NewBuiltin("adapter.query", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var sql string
+				if err := starlark.UnpackArgs(b.Name(), args, kwargs, "sql", &sql); err != nil {
+					return nil, err
+				}
+				v, err := queryValue(ctx, db, sql)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", b.Name(), err)
+				}
+				return v, nil
+			}),
+			"get_columns": starlark.NewBuiltin("adapter.get_columns", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var relation string
+				if err := starlark.UnpackArgs(b.Name(), args, kwargs, "relation", &relation); err != nil {
+					return nil, err
+				}
+				meta, err := db.GetTableMetadata(ctx, relation)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", b.Name(), err)
+				}
+				return columnsValue(meta), nil
+			}),
+			"relation_exists": starlark.NewBuiltin("adapter.relation_exists", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var relation string
+				if err := starlark.UnpackArgs(b.Name(), args, kwargs, "relation", &relation); err != nil {
+					return nil, err
+				}
+				_, err := db.GetTableMetadata(ctx, relation)
+				return starlark.Bool(err == nil), nil
+			}),
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package stdlib
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// ReModule provides regular expressions using Go (RE2) syntax.
+//
+//	re.match(pattern, s)         True if s contains a match
+//	re.search(pattern, s)        first match, or None
+//	re.groups(pattern, s)        submatches of the first match, or None
+//	re.find_all(pattern, s)      all matches
+//	re.sub(pattern, repl, s)     replace matches ($1 refers to a group)
+//	re.split(pattern, s)         split s around matches
+//	re.escape(s)                 quote regex metacharacters
+var ReModule = &starlarkstruct.Module{
+	Name: "re",
+	Members: starlark.StringDict{
+		"match":    starlark.NewBuiltin("re.match", reMatch),
+		"search":   starlark.NewBuiltin("re.search", reSearch),
+		"groups":   starlark.NewBuiltin("re.groups", reGroups),
+		"find_all": starlark.NewBuiltin("re.find_all", reFindAll),
+		"sub":      starlark.NewBuiltin("re.sub", reSub),
+		"split":    starlark.NewBuiltin("re.split", reSplit),
+		"escape":   starlark.NewBuiltin("re.escape", reEscape),
+	},
+}
+
+// regexCache holds compiled patterns, since macros often reuse the same ones.
+var regexCache sync.Map
+
+// compile returns the compiled form of a pattern.
+func compile(fn, pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// unpackPattern unpacks (pattern, s) and compiles the pattern.
+func unpackPattern(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (*regexp.Regexp, string, error) {
+	var pattern, s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "s", &s); err != nil {
+		return nil, "", err
+	}
+	re, err := compile(b.Name(), pattern)
+	return re, s, err
+}
+
+func reMatch(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, s, err := unpackPattern(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(re.MatchString(s)), nil
+}
+
+func reSearch(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, s, err := unpackPattern(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return starlark.None, nil
+	}
+	return starlark.String(s[loc[0]:loc[1]]), nil
+}
+
+func reGroups(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, s, err := unpackPattern(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return starlark.None, nil
+	}
+	return stringList(m[1:]), nil
+}
+
+func reFindAll(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, s, err := unpackPattern(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return stringList(re.FindAllString(s, -1)), nil
+}
+
+func reSub(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, repl, s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "repl", &repl, "s", &s); err != nil {
+		return nil, err
+	}
+	re, err := compile(b.Name(), pattern)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(re.ReplaceAllString(s, repl)), nil
+}
+
+func reSplit(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	re, s, err := unpackPattern(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return stringList(re.Split(s, -1)), nil
+}
+
+func reEscape(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+		return nil, err
+	}
+	return starlark.String(regexp.QuoteMeta(s)), nil
+}
+
+// stringList converts a string slice to a Starlark list.
+func stringList(items []string) *starlark.List {
+	values := make([]starlark.Value, len(items))
+	for i, item := range items {
+		values[i] = starlark.String(item)
+	}
+	return starlark.NewList(values)
+}
@@ -0,0 +1,95 @@
+package stdlib
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.starlark.net/starlark"
+)
+
+// relationsKey is the thread-local key holding the thread's Relations.
+const relationsKey = "leapsql.relations"
+
+// Relations backs the relation(name) builtin. It maps a model, seed or source
+// name to the qualified table name to emit and records every name requested,
+// so that dependencies built dynamically in Starlark reach the DAG.
+type Relations struct {
+	resolve func(name string) string
+
+	mu    sync.Mutex
+	names []string
+	seen  map[string]bool
+}
+
+// NewRelations creates a recorder that resolves names with resolve.
+// A nil resolve emits names unchanged.
+func NewRelations(resolve func(name string) string) *Relations {
+	return &Relations{
+		resolve: resolve,
+		seen:    make(map[string]bool),
+	}
+}
+
+// Names returns the relation names requested so far, in first-use order.
+func (r *Relations) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// SetRelations sets the resolver and recorder used by relation() on a thread.
+// Threads without one emit names unchanged.
+func SetRelations(thread *starlark.Thread, r *Relations) {
+	thread.SetLocal(relationsKey, r)
+}
+
+// Relation is the relation(name) builtin. It returns the qualified table name
+// for a model, seed or source and records the dependency.
+var Relation = starlark.NewBuiltin("relation", relation)
+
+func relation(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	if err := validateRelationName(name); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	r, ok := thread.Local(relationsKey).(*Relations)
+	if !ok || r == nil {
+		return starlark.String(name), nil
+	}
+
+	r.mu.Lock()
+	if !r.seen[name] {
+		r.seen[name] = true
+		r.names = append(r.names, name)
+	}
+	r.mu.Unlock()
+
+	if r.resolve == nil {
+		return starlark.String(name), nil
+	}
+	return starlark.String(r.resolve(name)), nil
+}
+
+// validateRelationName checks that name is a dotted list of identifiers.
+func validateRelationName(name string) error {
+	if name == "" {
+		return fmt.Errorf("relation name cannot be empty")
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid relation name %q", name)
+		}
+		for i, c := range part {
+			letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			if !letter && (i == 0 || c < '0' || c > '9') {
+				return fmt.Errorf("invalid relation name %q", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package stdlib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestRelation(t *testing.T) {
+	relations := NewRelations(func(name string) string {
+		if name == "stg_orders" {
+			return "staging.stg_orders"
+		}
+		return name
+	})
+	thread := &starlark.Thread{Name: "test"}
+	SetRelations(thread, relations)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`relation("stg_orders")`, "staging.stg_orders"},
+		{`relation("raw." + "payments")`, "raw.payments"},
+		{`relation(name = "stg_orders")`, "staging.stg_orders"},
+		{`relation("db.schema.table_2")`, "db.schema.table_2"},
+	}
+	for _, tt := range tests {
+		v, err := starlark.Eval(thread, "test", tt.expr, Globals())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if v != starlark.String(tt.want) {
+			t.Errorf("%s = %v, want %q", tt.expr, v, tt.want)
+		}
+	}
+
+	want := []string{"stg_orders", "raw.payments", "db.schema.table_2"}
+	if got := relations.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	for _, expr := range []string{`relation("")`, `relation("a..b")`, `relation("1abc")`, `relation("a; drop")`} {
+		_, err := starlark.Eval(thread, "test", expr, Globals())
+		if err == nil || !strings.Contains(err.Error(), "relation") {
+			t.Errorf("%s: expected invalid name error, got %v", expr, err)
+		}
+	}
+
+	// Without Relations on the thread names are emitted unchanged
+	v, err := starlark.Eval(&starlark.Thread{}, "test", `relation("stg_orders")`, Globals())
+	if err != nil || v != starlark.String("stg_orders") {
+		t.Errorf("relation() without Relations = %v, %v", v, err)
+	}
+}
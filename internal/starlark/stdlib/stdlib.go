@@ -0,0 +1,72 @@
+// Package stdlib provides the standard library modules available to templates
+// and .star macro files: datetime, json, re, math and hashing.
+//
+// All modules are deterministic: the only source of time is the clock set on
+// the executing thread with SetClock, so a render can be reproduced by
+// injecting a fixed clock.
+//
+// Globals additionally provides run_query and adapter, which read from the
+// warehouse through the Introspection set on the thread, relation, which
+// resolves and records dependencies through the thread's Relations, and var,
+// which reads the project variables set on the thread.
+package stdlib
+
+import (
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+)
+
+// Clock reports the current time.
+type Clock func() time.Time
+
+// clockKey is the thread-local key holding the thread's Clock.
+const clockKey = "leapsql.clock"
+
+// DefaultClock is used by threads without a clock of their own.
+var DefaultClock Clock = time.Now
+
+// SetClock sets the clock used by datetime functions on a thread.
+func SetClock(thread *starlark.Thread, clock Clock) {
+	thread.SetLocal(clockKey, clock)
+}
+
+// FixedClock returns a clock that always reports t.
+func FixedClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+// now returns the current time according to the thread's clock.
+func now(thread *starlark.Thread) time.Time {
+	if clock, ok := thread.Local(clockKey).(Clock); ok && clock != nil {
+		return clock()
+	}
+	return DefaultClock()
+}
+
+// Names lists the module names provided by the standard library.
+var Names = []string{"datetime", "json", "re", "math", "hashing"}
+
+// Globals returns the standard library modules together with the builtins
+// run_query, adapter, relation and var.
+func Globals() starlark.StringDict {
+	globals := Modules()
+	globals["run_query"] = RunQuery
+	globals["adapter"] = AdapterModule
+	globals["relation"] = Relation
+	globals["var"] = Var
+	return globals
+}
+
+// Modules returns the standard library modules keyed by global name.
+func Modules() starlark.StringDict {
+	return starlark.StringDict{
+		"datetime": DatetimeModule,
+		"json":     json.Module,
+		"re":       ReModule,
+		"math":     math.Module,
+		"hashing":  HashingModule,
+	}
+}
@@ -0,0 +1,115 @@
+package stdlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// eval evaluates expr with the standard library and a fixed clock.
+func eval(t *testing.T, expr string) (starlark.Value, error) {
+	t.Helper()
+	thread := &starlark.Thread{Name: "test"}
+	SetClock(thread, FixedClock(time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)))
+	return starlark.Eval(thread, "test", expr, Modules())
+}
+
+func TestModules(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// datetime
+		{`datetime.today()`, "2024-03-15"},
+		{`datetime.format(datetime.now(), "%Y-%m-%d %H:%M:%S")`, "2024-03-15 10:30:00"},
+		{`datetime.format(datetime.now(), "%j day, 100%%")`, "075 day, 100%"},
+		{`datetime.format(datetime.add(datetime.now(), months=-3, days=1), "%Y-%m-%d")`, "2023-12-16"},
+		{`datetime.format(datetime.add(datetime.date(2024, 1, 31), hours=25), "%Y-%m-%dT%H")`, "2024-02-01T01"},
+		{`datetime.format(datetime.parse("15/03/2024", "%d/%m/%Y"), "%B %d, %Y")`, "March 15, 2024"},
+		{`datetime.format(datetime.parse("2024-01-02"), "%a")`, "Tue"},
+		{`datetime.format(datetime.truncate(datetime.now(), "month"), "%Y-%m-%d %H")`, "2024-03-01 00"},
+		{`datetime.format(datetime.from_timestamp(0), "%Y")`, "1970"},
+		{`datetime.format(datetime.parse("2024-03-15 12:00:01.012345", "%Y-%m-%d %H:%M:%S.%f"), "%H%M%S%f")`, "120001012345"},
+		{`str(datetime.diff(datetime.now(), datetime.date(2024, 1, 1)))`, "74"},
+		{`str(datetime.diff(datetime.now(), datetime.date(2024, 3, 15), unit="minutes"))`, "630"},
+		{`str(datetime.now().year)`, "2024"},
+		// json
+		{`json.encode({"a": [1, 2]})`, `{"a":[1,2]}`},
+		{`str(json.decode('{"x": 3}')["x"])`, "3"},
+		// re
+		{`str(re.match("^ord_[0-9]+$", "ord_42"))`, "True"},
+		{`re.search("[0-9]+", "ord_42_x")`, "42"},
+		{`str(re.search("[0-9]+", "none"))`, "None"},
+		{`str(re.groups("(\\w+)@(\\w+)", "me@host"))`, `["me", "host"]`},
+		{`str(re.find_all("[a-z]+", "a1bc2d"))`, `["a", "bc", "d"]`},
+		{`re.sub("(\\w+)_id", "${1}_key", "user_id, order_id")`, "user_key, order_key"},
+		{`str(re.split("\\s*,\\s*", "a , b,c"))`, `["a", "b", "c"]`},
+		{`re.escape("a.b")`, `a\.b`},
+		// math
+		{`str(math.floor(2.7))`, "2"},
+		{`str(math.pow(2, 10))`, "1024.0"},
+		// hashing
+		{`hashing.md5("abc")`, "900150983cd24fb0d6963f7d28e17f72"},
+		{`hashing.sha1("abc")`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{`hashing.sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`hashing.surrogate_key("a", "b")`, "md5(coalesce(cast(a as varchar), '_null_') || '-' || coalesce(cast(b as varchar), '_null_'))"},
+		{`hashing.surrogate_key(["id"])`, "md5(coalesce(cast(id as varchar), '_null_'))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := eval(t, tt.expr)
+			if err != nil {
+				t.Fatalf("eval failed: %v", err)
+			}
+			got, ok := starlark.AsString(v)
+			if !ok {
+				t.Fatalf("expected string result, got %s", v.Type())
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModules_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`datetime.format(datetime.now(), "%Q")`, "unsupported directive %Q"},
+		{`datetime.parse("nope")`, `cannot parse "nope"`},
+		{`datetime.diff(datetime.now(), datetime.now(), unit="weeks")`, `unknown unit "weeks"`},
+		{`datetime.truncate(datetime.now(), "week")`, `unknown unit "week"`},
+		{`re.match("(", "x")`, "re.match: error parsing regexp"},
+		{`hashing.surrogate_key()`, "at least one column is required"},
+		{`hashing.surrogate_key(1)`, "column names must be strings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := eval(t, tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultClock(t *testing.T) {
+	old := DefaultClock
+	defer func() { DefaultClock = old }()
+	DefaultClock = FixedClock(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	thread := &starlark.Thread{Name: "test"}
+	v, err := starlark.Eval(thread, "test", `datetime.today()`, Modules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != starlark.String("2030-01-02") {
+		t.Errorf("today() = %v, want 2030-01-02", v)
+	}
+}
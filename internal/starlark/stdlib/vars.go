@@ -0,0 +1,38 @@
+package stdlib
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+)
+
+// varsKey is the thread-local key holding the thread's project variables.
+const varsKey = "leapsql.vars"
+
+// SetVars sets the project variables read by var() on a thread.
+func SetVars(thread *starlark.Thread, vars starlark.StringDict) {
+	thread.SetLocal(varsKey, vars)
+}
+
+// Var is the var(name, default) builtin. It returns a project variable, or
+// default when the variable is not set. Without a default, an unset variable
+// is an error.
+var Var = starlark.NewBuiltin("var", varBuiltin)
+
+func varBuiltin(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var def starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "default?", &def); err != nil {
+		return nil, err
+	}
+
+	if vars, ok := thread.Local(varsKey).(starlark.StringDict); ok {
+		if v, ok := vars[name]; ok {
+			return v, nil
+		}
+	}
+	if def != nil {
+		return def, nil
+	}
+	return nil, fmt.Errorf("%s: variable %q is not set (define it under vars: in leapsql.yaml or pass --vars)", b.Name(), name)
+}
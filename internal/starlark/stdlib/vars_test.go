@@ -0,0 +1,38 @@
+package stdlib
+
+import (
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestVar(t *testing.T) {
+	thread := &starlark.Thread{Name: "test"}
+	SetVars(thread, starlark.StringDict{"start_date": starlark.String("2024-01-01")})
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`var("start_date")`, `"2024-01-01"`},
+		{`var("start_date", "1970-01-01")`, `"2024-01-01"`},
+		{`var("end_date", "2024-12-31")`, `"2024-12-31"`},
+		{`var("end_date", None)`, "None"},
+		{`var(name = "missing", default = 3)`, "3"},
+	}
+	for _, tt := range tests {
+		v, err := starlark.Eval(thread, "test", tt.expr, Globals())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if v.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.expr, v, tt.want)
+		}
+	}
+
+	_, err := starlark.Eval(thread, "test", `var("end_date")`, Globals())
+	if err == nil || !strings.Contains(err.Error(), `variable "end_date" is not set`) {
+		t.Errorf("expected unset variable error, got %v", err)
+	}
+}
@@ -19,8 +19,9 @@ type TargetInfo struct {
 // ThisInfo contains current model information.
 // Exposed as the "this" global in Starlark execution.
 type ThisInfo struct {
-	Name   string // Current model name
-	Schema string // Current model schema
+	Name     string // Current model name
+	Schema   string // Current model schema
+	Relation string // Table the model is built as, e.g. "staging.orders"
 }
 
 // ToStarlark converts TargetInfo to a Starlark struct value.
@@ -35,8 +36,9 @@ func (t *TargetInfo) ToStarlark() starlark.Value {
 // ThisToStarlark converts ThisInfo to a Starlark struct value.
 func (t *ThisInfo) ToStarlark() starlark.Value {
 	return starlarkstruct.FromStringDict(starlark.String("this"), starlark.StringDict{
-		"name":   starlark.String(t.Name),
-		"schema": starlark.String(t.Schema),
+		"name":     starlark.String(t.Name),
+		"schema":   starlark.String(t.Schema),
+		"relation": starlark.String(t.Relation),
 	})
 }
 
@@ -99,6 +101,20 @@ func GoToStarlark(v any) (starlark.Value, error) {
 	}
 }
 
+// VarsToStarlark converts project variables to frozen Starlark values.
+func VarsToStarlark(vars map[string]any) (starlark.StringDict, error) {
+	result := make(starlark.StringDict, len(vars))
+	for name, v := range vars {
+		sv, err := GoToStarlark(v)
+		if err != nil {
+			return nil, fmt.Errorf("var %q: %w", name, err)
+		}
+		sv.Freeze()
+		result[name] = sv
+	}
+	return result, nil
+}
+
 // StarlarkToGo converts a Starlark value back to a Go value.
 // Returns: string, int64, float64, bool, []any, map[string]any, or nil
 func StarlarkToGo(v starlark.Value) (any, error) {
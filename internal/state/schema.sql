@@ -9,6 +9,7 @@ CREATE TABLE IF NOT EXISTS runs (
     started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     completed_at DATETIME,
     error TEXT,
+    vars TEXT,  -- JSON object of project variables
     
     CHECK (status IN ('running', 'completed', 'failed', 'cancelled'))
 );
@@ -22,24 +23,32 @@ CREATE TABLE IF NOT EXISTS models (
     id TEXT PRIMARY KEY,
     path TEXT NOT NULL UNIQUE,
     name TEXT NOT NULL,
-    materialized TEXT NOT NULL DEFAULT 'table',
+    materialized TEXT NOT NULL DEFAULT 'table',  -- table, view, incremental or a custom name
     unique_key TEXT,
     content_hash TEXT NOT NULL,
     -- New fields from frontmatter
     owner TEXT,
     schema_name TEXT,
+    description TEXT,
     tags TEXT,           -- JSON array: ["finance", "revenue"]
     tests TEXT,          -- JSON array of test configs
     meta TEXT,           -- JSON object for extensions
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    
-    CHECK (materialized IN ('table', 'view', 'incremental'))
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+    -- materialized is unconstrained: custom materializations are named by users
 );
 
 CREATE INDEX IF NOT EXISTS idx_models_path ON models(path);
 CREATE INDEX IF NOT EXISTS idx_models_name ON models(name);
 
+-- seeds: loaded seed files and their content hashes
+CREATE TABLE IF NOT EXISTS seeds (
+    path TEXT PRIMARY KEY,          -- table name, e.g. "raw.customers"
+    file_path TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    loaded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- model_runs: execution history per model
 CREATE TABLE IF NOT EXISTS model_runs (
     id TEXT PRIMARY KEY,
@@ -62,6 +71,28 @@ CREATE INDEX IF NOT EXISTS idx_model_runs_run_id ON model_runs(run_id);
 CREATE INDEX IF NOT EXISTS idx_model_runs_model_id ON model_runs(model_id);
 CREATE INDEX IF NOT EXISTS idx_model_runs_status ON model_runs(status);
 
+-- test_results: data test outcomes per run
+CREATE TABLE IF NOT EXISTS test_results (
+    id TEXT PRIMARY KEY,
+    run_id TEXT NOT NULL,
+    test_name TEXT NOT NULL,
+    model_path TEXT,                -- NULL for singular tests
+    severity TEXT NOT NULL DEFAULT 'error',
+    status TEXT NOT NULL,
+    failures INTEGER DEFAULT 0,
+    sample TEXT,                    -- JSON array of failing rows
+    error TEXT,
+    executed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    execution_ms INTEGER DEFAULT 0,
+    
+    FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE,
+    
+    CHECK (severity IN ('error', 'warn')),
+    CHECK (status IN ('pass', 'warn', 'fail', 'error'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_results_run_id ON test_results(run_id);
+
 -- dependencies: DAG edges (model -> parent relationships)
 CREATE TABLE IF NOT EXISTS dependencies (
     model_id TEXT NOT NULL,
@@ -106,6 +137,7 @@ CREATE TABLE IF NOT EXISTS model_columns (
     column_index   INTEGER NOT NULL,
     transform_type TEXT DEFAULT '',         -- '' (direct) or 'EXPR'
     function_name  TEXT DEFAULT '',         -- 'sum', 'count', etc.
+    description    TEXT,                    -- from frontmatter column docs
     PRIMARY KEY (model_path, column_name),
     FOREIGN KEY (model_path) REFERENCES models(path) ON DELETE CASCADE
 );
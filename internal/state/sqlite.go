@@ -1,10 +1,13 @@
 package state
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -70,9 +73,81 @@ func (s *SQLiteStore) InitSchema() error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
+
+	for _, stmt := range schemaMigrations {
+		if _, err := s.db.Exec(stmt); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	}
+	if err := s.dropMaterializedCheck(); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
 	return nil
 }
 
+// materializedCheckPattern matches the constraint older versions put on
+// models.materialized, which rejects custom materializations.
+var materializedCheckPattern = regexp.MustCompile(`,\s*CHECK \(materialized IN \([^)]*\)\)`)
+
+// dropMaterializedCheck rebuilds the models table of databases created by
+// older versions without the constraint on materialized. SQLite can't drop a
+// constraint in place, so the table is copied using its own definition minus
+// the CHECK. Foreign keys are disabled on the connection while the old table
+// is dropped so model_runs and friends are not cascade-deleted.
+func (s *SQLiteStore) dropMaterializedCheck() error {
+	var ddl string
+	err := s.db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'models'`).Scan(&ddl)
+	if err != nil {
+		return err
+	}
+	if !materializedCheckPattern.MatchString(ddl) {
+		return nil
+	}
+	ddl = materializedCheckPattern.ReplaceAllString(ddl, "")
+	ddl = strings.Replace(ddl, "models", "models_new", 1)
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		ddl,
+		`INSERT INTO models_new SELECT * FROM models`,
+		`DROP TABLE models`,
+		`ALTER TABLE models_new RENAME TO models`,
+		`CREATE INDEX IF NOT EXISTS idx_models_path ON models(path)`,
+		`CREATE INDEX IF NOT EXISTS idx_models_name ON models(name)`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// schemaMigrations adds columns introduced after the initial schema to
+// databases created by older versions. Each statement must be safe to re-run;
+// "duplicate column name" errors are ignored.
+var schemaMigrations = []string{
+	`ALTER TABLE models ADD COLUMN description TEXT`,
+	`ALTER TABLE model_columns ADD COLUMN description TEXT`,
+	`ALTER TABLE runs ADD COLUMN vars TEXT`,
+}
+
 // generateID creates a new UUID.
 func generateID() string {
 	return uuid.New().String()
@@ -82,6 +157,12 @@ func generateID() string {
 
 // CreateRun creates a new pipeline run.
 func (s *SQLiteStore) CreateRun(env string) (*Run, error) {
+	return s.CreateRunWithVars(env, nil)
+}
+
+// CreateRunWithVars creates a new pipeline run and records the project
+// variables it runs with.
+func (s *SQLiteStore) CreateRunWithVars(env string, vars map[string]any) (*Run, error) {
 	if s.db == nil {
 		return nil, fmt.Errorf("database not opened")
 	}
@@ -91,11 +172,17 @@ func (s *SQLiteStore) CreateRun(env string) (*Run, error) {
 		Environment: env,
 		Status:      RunStatusRunning,
 		StartedAt:   time.Now().UTC(),
+		Vars:        vars,
 	}
 
-	_, err := s.db.Exec(
-		`INSERT INTO runs (id, environment, status, started_at) VALUES (?, ?, ?, ?)`,
-		run.ID, run.Environment, run.Status, run.StartedAt,
+	varsJSON, err := serializeJSON(vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize vars: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO runs (id, environment, status, started_at, vars) VALUES (?, ?, ?, ?, ?)`,
+		run.ID, run.Environment, run.Status, run.StartedAt, varsJSON,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create run: %w", err)
@@ -112,12 +199,12 @@ func (s *SQLiteStore) GetRun(id string) (*Run, error) {
 
 	run := &Run{}
 	var completedAt sql.NullTime
-	var errMsg sql.NullString
+	var errMsg, vars sql.NullString
 
 	err := s.db.QueryRow(
-		`SELECT id, environment, status, started_at, completed_at, error FROM runs WHERE id = ?`,
+		`SELECT id, environment, status, started_at, completed_at, error, vars FROM runs WHERE id = ?`,
 		id,
-	).Scan(&run.ID, &run.Environment, &run.Status, &run.StartedAt, &completedAt, &errMsg)
+	).Scan(&run.ID, &run.Environment, &run.Status, &run.StartedAt, &completedAt, &errMsg, &vars)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("run not found: %s", id)
@@ -132,6 +219,9 @@ func (s *SQLiteStore) GetRun(id string) (*Run, error) {
 	if errMsg.Valid {
 		run.Error = errMsg.String
 	}
+	if err := deserializeJSON(vars, &run.Vars); err != nil {
+		return nil, fmt.Errorf("failed to deserialize vars: %w", err)
+	}
 
 	return run, nil
 }
@@ -172,13 +262,13 @@ func (s *SQLiteStore) GetLatestRun(env string) (*Run, error) {
 
 	run := &Run{}
 	var completedAt sql.NullTime
-	var errMsg sql.NullString
+	var errMsg, vars sql.NullString
 
 	err := s.db.QueryRow(
-		`SELECT id, environment, status, started_at, completed_at, error 
+		`SELECT id, environment, status, started_at, completed_at, error, vars 
 		 FROM runs WHERE environment = ? ORDER BY started_at DESC LIMIT 1`,
 		env,
-	).Scan(&run.ID, &run.Environment, &run.Status, &run.StartedAt, &completedAt, &errMsg)
+	).Scan(&run.ID, &run.Environment, &run.Status, &run.StartedAt, &completedAt, &errMsg, &vars)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // No runs found, return nil without error
@@ -193,6 +283,9 @@ func (s *SQLiteStore) GetLatestRun(env string) (*Run, error) {
 	if errMsg.Valid {
 		run.Error = errMsg.String
 	}
+	if err := deserializeJSON(vars, &run.Vars); err != nil {
+		return nil, fmt.Errorf("failed to deserialize vars: %w", err)
+	}
 
 	return run, nil
 }
@@ -240,10 +333,10 @@ func (s *SQLiteStore) RegisterModel(model *Model) error {
 
 		_, err := s.db.Exec(
 			`UPDATE models SET name = ?, materialized = ?, unique_key = ?, content_hash = ?, 
-			 owner = ?, schema_name = ?, tags = ?, tests = ?, meta = ?, updated_at = ? 
+			 owner = ?, schema_name = ?, description = ?, tags = ?, tests = ?, meta = ?, updated_at = ? 
 			 WHERE id = ?`,
 			model.Name, model.Materialized, model.UniqueKey, model.ContentHash,
-			nullString(model.Owner), nullString(model.Schema), tagsJSON, testsJSON, metaJSON,
+			nullString(model.Owner), nullString(model.Schema), nullString(model.Description), tagsJSON, testsJSON, metaJSON,
 			model.UpdatedAt, model.ID,
 		)
 		if err != nil {
@@ -259,10 +352,10 @@ func (s *SQLiteStore) RegisterModel(model *Model) error {
 
 		_, err := s.db.Exec(
 			`INSERT INTO models (id, path, name, materialized, unique_key, content_hash, 
-			 owner, schema_name, tags, tests, meta, created_at, updated_at) 
-			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			 owner, schema_name, description, tags, tests, meta, created_at, updated_at) 
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			model.ID, model.Path, model.Name, model.Materialized, model.UniqueKey, model.ContentHash,
-			nullString(model.Owner), nullString(model.Schema), tagsJSON, testsJSON, metaJSON,
+			nullString(model.Owner), nullString(model.Schema), nullString(model.Description), tagsJSON, testsJSON, metaJSON,
 			model.CreatedAt, model.UpdatedAt,
 		)
 		if err != nil {
@@ -297,6 +390,10 @@ func serializeJSON(v any) (sql.NullString, error) {
 		if len(val) == 0 {
 			return sql.NullString{Valid: false}, nil
 		}
+	case []map[string]any:
+		if len(val) == 0 {
+			return sql.NullString{Valid: false}, nil
+		}
 	case map[string]any:
 		if len(val) == 0 {
 			return sql.NullString{Valid: false}, nil
@@ -317,15 +414,15 @@ func (s *SQLiteStore) GetModelByID(id string) (*Model, error) {
 	}
 
 	model := &Model{}
-	var uniqueKey, owner, schema, tagsJSON, testsJSON, metaJSON sql.NullString
+	var uniqueKey, owner, schema, description, tagsJSON, testsJSON, metaJSON sql.NullString
 
 	err := s.db.QueryRow(
 		`SELECT id, path, name, materialized, unique_key, content_hash, 
-		 owner, schema_name, tags, tests, meta, created_at, updated_at 
+		 owner, schema_name, description, tags, tests, meta, created_at, updated_at 
 		 FROM models WHERE id = ?`,
 		id,
 	).Scan(&model.ID, &model.Path, &model.Name, &model.Materialized, &uniqueKey, &model.ContentHash,
-		&owner, &schema, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
+		&owner, &schema, &description, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("model not found: %s", id)
@@ -344,6 +441,9 @@ func (s *SQLiteStore) GetModelByID(id string) (*Model, error) {
 	if schema.Valid {
 		model.Schema = schema.String
 	}
+	if description.Valid {
+		model.Description = description.String
+	}
 
 	// Deserialize JSON fields
 	if err := deserializeJSON(tagsJSON, &model.Tags); err != nil {
@@ -374,15 +474,15 @@ func (s *SQLiteStore) GetModelByPath(path string) (*Model, error) {
 	}
 
 	model := &Model{}
-	var uniqueKey, owner, schema, tagsJSON, testsJSON, metaJSON sql.NullString
+	var uniqueKey, owner, schema, description, tagsJSON, testsJSON, metaJSON sql.NullString
 
 	err := s.db.QueryRow(
 		`SELECT id, path, name, materialized, unique_key, content_hash, 
-		 owner, schema_name, tags, tests, meta, created_at, updated_at 
+		 owner, schema_name, description, tags, tests, meta, created_at, updated_at 
 		 FROM models WHERE path = ?`,
 		path,
 	).Scan(&model.ID, &model.Path, &model.Name, &model.Materialized, &uniqueKey, &model.ContentHash,
-		&owner, &schema, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
+		&owner, &schema, &description, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // Not found, return nil without error
@@ -401,6 +501,9 @@ func (s *SQLiteStore) GetModelByPath(path string) (*Model, error) {
 	if schema.Valid {
 		model.Schema = schema.String
 	}
+	if description.Valid {
+		model.Description = description.String
+	}
 
 	// Deserialize JSON fields
 	if err := deserializeJSON(tagsJSON, &model.Tags); err != nil {
@@ -446,7 +549,7 @@ func (s *SQLiteStore) ListModels() ([]*Model, error) {
 
 	rows, err := s.db.Query(
 		`SELECT id, path, name, materialized, unique_key, content_hash, 
-		 owner, schema_name, tags, tests, meta, created_at, updated_at 
+		 owner, schema_name, description, tags, tests, meta, created_at, updated_at 
 		 FROM models ORDER BY path`,
 	)
 	if err != nil {
@@ -457,10 +560,10 @@ func (s *SQLiteStore) ListModels() ([]*Model, error) {
 	var models []*Model
 	for rows.Next() {
 		model := &Model{}
-		var uniqueKey, owner, schema, tagsJSON, testsJSON, metaJSON sql.NullString
+		var uniqueKey, owner, schema, description, tagsJSON, testsJSON, metaJSON sql.NullString
 
 		err := rows.Scan(&model.ID, &model.Path, &model.Name, &model.Materialized, &uniqueKey, &model.ContentHash,
-			&owner, &schema, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
+			&owner, &schema, &description, &tagsJSON, &testsJSON, &metaJSON, &model.CreatedAt, &model.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan model: %w", err)
 		}
@@ -475,6 +578,9 @@ func (s *SQLiteStore) ListModels() ([]*Model, error) {
 		if schema.Valid {
 			model.Schema = schema.String
 		}
+		if description.Valid {
+			model.Description = description.String
+		}
 
 		// Deserialize JSON fields
 		if err := deserializeJSON(tagsJSON, &model.Tags); err != nil {
@@ -627,6 +733,130 @@ func (s *SQLiteStore) GetLatestModelRun(modelID string) (*ModelRun, error) {
 	return mr, nil
 }
 
+// --- Test result operations ---
+
+// RecordTestResult records the outcome of a data test.
+func (s *SQLiteStore) RecordTestResult(result *TestResult) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	if result.ID == "" {
+		result.ID = generateID()
+	}
+	if result.ExecutedAt.IsZero() {
+		result.ExecutedAt = time.Now().UTC()
+	}
+	if result.Severity == "" {
+		result.Severity = "error"
+	}
+
+	sampleJSON, err := serializeJSON(result.Sample)
+	if err != nil {
+		return fmt.Errorf("failed to serialize sample: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO test_results (id, run_id, test_name, model_path, severity, status, failures, sample, error, executed_at, execution_ms) 
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.ID, result.RunID, result.TestName, nullString(result.ModelPath), result.Severity, result.Status,
+		result.Failures, sampleJSON, nullString(result.Error), result.ExecutedAt, result.ExecutionMS,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record test result: %w", err)
+	}
+
+	return nil
+}
+
+// GetTestResultsForRun retrieves all test results for a given run.
+func (s *SQLiteStore) GetTestResultsForRun(runID string) ([]*TestResult, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not opened")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, run_id, test_name, model_path, severity, status, failures, sample, error, executed_at, execution_ms 
+		 FROM test_results WHERE run_id = ? ORDER BY executed_at, test_name`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*TestResult
+	for rows.Next() {
+		tr := &TestResult{}
+		var modelPath, sampleJSON, errMsg sql.NullString
+
+		err := rows.Scan(&tr.ID, &tr.RunID, &tr.TestName, &modelPath, &tr.Severity, &tr.Status,
+			&tr.Failures, &sampleJSON, &errMsg, &tr.ExecutedAt, &tr.ExecutionMS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan test result: %w", err)
+		}
+
+		if modelPath.Valid {
+			tr.ModelPath = modelPath.String
+		}
+		if errMsg.Valid {
+			tr.Error = errMsg.String
+		}
+		if err := deserializeJSON(sampleJSON, &tr.Sample); err != nil {
+			return nil, fmt.Errorf("failed to deserialize sample: %w", err)
+		}
+
+		results = append(results, tr)
+	}
+
+	return results, rows.Err()
+}
+
+// --- Seed operations ---
+
+// RegisterSeed records that a seed was loaded with the given content hash.
+func (s *SQLiteStore) RegisterSeed(seed *Seed) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	seed.LoadedAt = time.Now().UTC()
+
+	_, err := s.db.Exec(
+		`INSERT INTO seeds (path, file_path, content_hash, loaded_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(path) DO UPDATE SET file_path = excluded.file_path, 
+		 content_hash = excluded.content_hash, loaded_at = excluded.loaded_at`,
+		seed.Path, seed.FilePath, seed.ContentHash, seed.LoadedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register seed: %w", err)
+	}
+
+	return nil
+}
+
+// GetSeed retrieves a seed by its path. Returns nil if the seed was never loaded.
+func (s *SQLiteStore) GetSeed(path string) (*Seed, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not opened")
+	}
+
+	seed := &Seed{}
+	err := s.db.QueryRow(
+		`SELECT path, file_path, content_hash, loaded_at FROM seeds WHERE path = ?`,
+		path,
+	).Scan(&seed.Path, &seed.FilePath, &seed.ContentHash, &seed.LoadedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found, return nil without error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	return seed, nil
+}
+
 // --- Dependency operations ---
 
 // SetDependencies sets the parent dependencies for a model.
@@ -817,7 +1047,7 @@ func (s *SQLiteStore) SaveModelColumns(modelPath string, columns []ColumnInfo) e
 	}
 
 	// Insert new columns
-	colStmt, err := tx.Prepare(`INSERT INTO model_columns (model_path, column_name, column_index, transform_type, function_name) VALUES (?, ?, ?, ?, ?)`)
+	colStmt, err := tx.Prepare(`INSERT INTO model_columns (model_path, column_name, column_index, transform_type, function_name, description) VALUES (?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare column insert: %w", err)
 	}
@@ -831,7 +1061,7 @@ func (s *SQLiteStore) SaveModelColumns(modelPath string, columns []ColumnInfo) e
 
 	for _, col := range columns {
 		// Insert column
-		_, err = colStmt.Exec(modelPath, col.Name, col.Index, col.TransformType, col.Function)
+		_, err = colStmt.Exec(modelPath, col.Name, col.Index, col.TransformType, col.Function, nullString(col.Description))
 		if err != nil {
 			return fmt.Errorf("failed to insert column %s: %w", col.Name, err)
 		}
@@ -863,7 +1093,7 @@ func (s *SQLiteStore) GetModelColumns(modelPath string) ([]ColumnInfo, error) {
 
 	// Get all columns for the model
 	colRows, err := s.db.Query(
-		`SELECT column_name, column_index, transform_type, function_name 
+		`SELECT column_name, column_index, transform_type, function_name, description 
 		 FROM model_columns 
 		 WHERE model_path = ? 
 		 ORDER BY column_index`,
@@ -880,9 +1110,9 @@ func (s *SQLiteStore) GetModelColumns(modelPath string) ([]ColumnInfo, error) {
 
 	for colRows.Next() {
 		var col ColumnInfo
-		var transformType, functionName sql.NullString
+		var transformType, functionName, description sql.NullString
 
-		if err := colRows.Scan(&col.Name, &col.Index, &transformType, &functionName); err != nil {
+		if err := colRows.Scan(&col.Name, &col.Index, &transformType, &functionName, &description); err != nil {
 			return nil, fmt.Errorf("failed to scan column: %w", err)
 		}
 
@@ -892,6 +1122,9 @@ func (s *SQLiteStore) GetModelColumns(modelPath string) ([]ColumnInfo, error) {
 		if functionName.Valid {
 			col.Function = functionName.String
 		}
+		if description.Valid {
+			col.Description = description.String
+		}
 
 		columnsIdxMap[col.Name] = len(columns)
 		columns = append(columns, col)
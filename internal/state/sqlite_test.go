@@ -1,6 +1,8 @@
 package state
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,6 +98,40 @@ func TestSQLiteStore_GetRun(t *testing.T) {
 	}
 }
 
+func TestSQLiteStore_CreateRunWithVars(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	vars := map[string]any{"start_date": "2024-01-01", "limit": 10}
+	created, err := store.CreateRunWithVars("dev", vars)
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	retrieved, err := store.GetRun(created.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if retrieved.Vars["start_date"] != "2024-01-01" || retrieved.Vars["limit"] != float64(10) {
+		t.Errorf("vars = %v, want start_date and limit", retrieved.Vars)
+	}
+
+	latest, err := store.GetLatestRun("dev")
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	if len(latest.Vars) != 2 {
+		t.Errorf("latest run vars = %v, want 2 entries", latest.Vars)
+	}
+
+	// Runs without vars store none
+	plain, _ := store.CreateRun("dev")
+	retrieved, _ = store.GetRun(plain.ID)
+	if retrieved.Vars != nil {
+		t.Errorf("vars = %v, want nil", retrieved.Vars)
+	}
+}
+
 func TestSQLiteStore_GetRun_NotFound(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -353,6 +389,7 @@ func TestSQLiteStore_RegisterModel_WithFrontmatterFields(t *testing.T) {
 		ContentHash:  "abc123",
 		Owner:        "data-team",
 		Schema:       "analytics",
+		Description:  "Cleaned user records",
 		Tags:         []string{"pii", "daily"},
 		Tests: []TestConfig{
 			{Unique: []string{"user_id"}},
@@ -381,6 +418,9 @@ func TestSQLiteStore_RegisterModel_WithFrontmatterFields(t *testing.T) {
 	if retrieved.Schema != "analytics" {
 		t.Errorf("expected schema 'analytics', got %q", retrieved.Schema)
 	}
+	if retrieved.Description != "Cleaned user records" {
+		t.Errorf("expected description 'Cleaned user records', got %q", retrieved.Description)
+	}
 	if len(retrieved.Tags) != 2 || retrieved.Tags[0] != "pii" || retrieved.Tags[1] != "daily" {
 		t.Errorf("expected tags [pii, daily], got %v", retrieved.Tags)
 	}
@@ -1264,3 +1304,163 @@ func TestSQLiteStore_TraceColumn_EmptyResults(t *testing.T) {
 		t.Errorf("expected empty forward trace, got %d results", len(forward))
 	}
 }
+
+func TestSQLiteStore_SaveModelColumns_Description(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	model := &Model{Path: "staging.stg_customers", Name: "stg_customers", ContentHash: "abc"}
+	if err := store.RegisterModel(model); err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+
+	columns := []ColumnInfo{
+		{Name: "customer_id", Index: 0, Description: "Primary key"},
+		{Name: "name", Index: 1},
+	}
+	if err := store.SaveModelColumns("staging.stg_customers", columns); err != nil {
+		t.Fatalf("failed to save columns: %v", err)
+	}
+
+	retrieved, err := store.GetModelColumns("staging.stg_customers")
+	if err != nil {
+		t.Fatalf("failed to get columns: %v", err)
+	}
+	if len(retrieved) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(retrieved))
+	}
+	if retrieved[0].Description != "Primary key" {
+		t.Errorf("expected description 'Primary key', got %q", retrieved[0].Description)
+	}
+	if retrieved[1].Description != "" {
+		t.Errorf("expected empty description, got %q", retrieved[1].Description)
+	}
+}
+
+func TestSQLiteStore_InitSchema_Idempotent(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	// Running migrations again must not fail on already-added columns
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("second InitSchema failed: %v", err)
+	}
+}
+
+func TestSQLiteStore_InitSchema_DropsMaterializedCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store := NewSQLiteStore()
+	if err := store.Open(path); err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	// A database created before custom materializations
+	oldSchema := strings.Replace(schemaSQL,
+		"-- materialized is unconstrained: custom materializations are named by users",
+		",CHECK (materialized IN ('table', 'view', 'incremental'))", 1)
+	if _, err := store.db.Exec(oldSchema); err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+	old := &Model{Path: "staging.orders", Name: "orders", Materialized: "table", ContentHash: "abc"}
+	if err := store.RegisterModel(old); err != nil {
+		t.Fatal(err)
+	}
+	run, _ := store.CreateRun("dev")
+	if err := store.RecordModelRun(&ModelRun{RunID: run.ID, ModelID: old.ID, Status: ModelRunStatusSuccess}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	custom := &Model{Path: "marts.snapshot", Name: "snapshot", Materialized: "scd2", ContentHash: "def"}
+	if err := store.RegisterModel(custom); err != nil {
+		t.Fatalf("RegisterModel with a custom materialization failed: %v", err)
+	}
+	if m, err := store.GetModelByPath("staging.orders"); err != nil || m.ID != old.ID {
+		t.Errorf("existing model lost: %v, %v", m, err)
+	}
+	if runs, err := store.GetModelRunsForRun(run.ID); err != nil || len(runs) != 1 {
+		t.Errorf("model runs lost: %v, %v", runs, err)
+	}
+}
+
+func TestSQLiteStore_RecordTestResult(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	run, err := store.CreateRun("dev")
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	results := []*TestResult{
+		{RunID: run.ID, TestName: "unique(id)", ModelPath: "staging.users", Status: TestStatusFail, Failures: 2,
+			Sample: []map[string]any{{"id": 1, "n_records": 2}}},
+		{RunID: run.ID, TestName: "no_orphans", Severity: "warn", Status: TestStatusPass},
+	}
+	for _, r := range results {
+		if err := store.RecordTestResult(r); err != nil {
+			t.Fatalf("failed to record test result: %v", err)
+		}
+	}
+
+	stored, err := store.GetTestResultsForRun(run.ID)
+	if err != nil {
+		t.Fatalf("failed to get test results: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(stored))
+	}
+
+	byName := map[string]*TestResult{}
+	for _, r := range stored {
+		byName[r.TestName] = r
+	}
+
+	unique := byName["unique(id)"]
+	if unique.Severity != "error" || unique.Status != TestStatusFail || unique.Failures != 2 {
+		t.Errorf("unexpected unique result: %+v", unique)
+	}
+	if len(unique.Sample) != 1 || unique.Sample[0]["id"] != float64(1) {
+		t.Errorf("unexpected sample: %v", unique.Sample)
+	}
+
+	orphans := byName["no_orphans"]
+	if orphans.ModelPath != "" || orphans.Severity != "warn" || orphans.Sample != nil {
+		t.Errorf("unexpected singular result: %+v", orphans)
+	}
+}
+
+func TestSQLiteStore_RegisterSeed(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	missing, err := store.GetSeed("raw_customers")
+	if err != nil || missing != nil {
+		t.Fatalf("GetSeed() on unknown seed = %v, %v; want nil, nil", missing, err)
+	}
+
+	seed := &Seed{Path: "raw_customers", FilePath: "seeds/raw_customers.csv", ContentHash: "abc"}
+	if err := store.RegisterSeed(seed); err != nil {
+		t.Fatalf("failed to register seed: %v", err)
+	}
+
+	seed.ContentHash = "def"
+	if err := store.RegisterSeed(seed); err != nil {
+		t.Fatalf("failed to update seed: %v", err)
+	}
+
+	got, err := store.GetSeed("raw_customers")
+	if err != nil {
+		t.Fatalf("failed to get seed: %v", err)
+	}
+	if got == nil || got.ContentHash != "def" || got.FilePath != "seeds/raw_customers.csv" {
+		t.Errorf("unexpected seed: %+v", got)
+	}
+	if got != nil && got.LoadedAt.IsZero() {
+		t.Error("expected LoadedAt to be set")
+	}
+}
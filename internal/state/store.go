@@ -27,6 +27,16 @@ const (
 	ModelRunStatusSkipped ModelRunStatus = "skipped"
 )
 
+// TestStatus represents the outcome of a data test.
+type TestStatus string
+
+const (
+	TestStatusPass  TestStatus = "pass"
+	TestStatusWarn  TestStatus = "warn"  // failing rows with severity "warn"
+	TestStatusFail  TestStatus = "fail"  // failing rows with severity "error"
+	TestStatusError TestStatus = "error" // the test query could not be executed
+)
+
 // Run represents a pipeline execution session.
 type Run struct {
 	ID          string     `json:"id"`
@@ -35,6 +45,8 @@ type Run struct {
 	StartedAt   time.Time  `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Error       string     `json:"error,omitempty"`
+	// Vars are the project variables the run was executed with
+	Vars map[string]any `json:"vars,omitempty"`
 }
 
 // Model represents a registered model in the state store.
@@ -42,11 +54,12 @@ type Model struct {
 	ID           string         `json:"id"`
 	Path         string         `json:"path"`         // e.g., "models.staging.stg_users"
 	Name         string         `json:"name"`         // e.g., "stg_users"
-	Materialized string         `json:"materialized"` // "table", "view", "incremental"
+	Materialized string         `json:"materialized"` // "table", "view", "incremental" or a custom materialization
 	UniqueKey    string         `json:"unique_key,omitempty"`
 	ContentHash  string         `json:"content_hash"`
 	Owner        string         `json:"owner,omitempty"`
 	Schema       string         `json:"schema,omitempty"`
+	Description  string         `json:"description,omitempty"`
 	Tags         []string       `json:"tags,omitempty"`
 	Tests        []TestConfig   `json:"tests,omitempty"`
 	Meta         map[string]any `json:"meta,omitempty"`
@@ -80,6 +93,29 @@ type ModelRun struct {
 	ExecutionMS  int64          `json:"execution_ms"`
 }
 
+// TestResult represents the outcome of a single data test within a run.
+type TestResult struct {
+	ID          string           `json:"id"`
+	RunID       string           `json:"run_id"`
+	TestName    string           `json:"test_name"`
+	ModelPath   string           `json:"model_path,omitempty"` // empty for singular tests
+	Severity    string           `json:"severity"`             // "error" or "warn"
+	Status      TestStatus       `json:"status"`
+	Failures    int64            `json:"failures"`
+	Sample      []map[string]any `json:"sample,omitempty"` // first failing rows
+	Error       string           `json:"error,omitempty"`
+	ExecutedAt  time.Time        `json:"executed_at"`
+	ExecutionMS int64            `json:"execution_ms"`
+}
+
+// Seed represents a loaded seed file and the content hash it was loaded from.
+type Seed struct {
+	Path        string    `json:"path"`      // table name, e.g. "raw.customers"
+	FilePath    string    `json:"file_path"` // source file
+	ContentHash string    `json:"content_hash"`
+	LoadedAt    time.Time `json:"loaded_at"`
+}
+
 // Dependency represents an edge in the model dependency graph.
 type Dependency struct {
 	ModelID  string `json:"model_id"`
@@ -107,6 +143,7 @@ type ColumnInfo struct {
 	TransformType string      `json:"transform_type"` // "" (direct) or "EXPR"
 	Function      string      `json:"function"`       // "sum", "count", etc.
 	Sources       []SourceRef `json:"sources"`        // where this column comes from
+	Description   string      `json:"description,omitempty"`
 }
 
 // TraceResult represents a single node in a column lineage trace.
@@ -130,6 +167,7 @@ type StateStore interface {
 
 	// Run operations
 	CreateRun(env string) (*Run, error)
+	CreateRunWithVars(env string, vars map[string]any) (*Run, error)
 	GetRun(id string) (*Run, error)
 	CompleteRun(id string, status RunStatus, errMsg string) error
 	GetLatestRun(env string) (*Run, error)
@@ -147,6 +185,14 @@ type StateStore interface {
 	GetModelRunsForRun(runID string) ([]*ModelRun, error)
 	GetLatestModelRun(modelID string) (*ModelRun, error)
 
+	// Test result operations
+	RecordTestResult(result *TestResult) error
+	GetTestResultsForRun(runID string) ([]*TestResult, error)
+
+	// Seed operations
+	RegisterSeed(seed *Seed) error
+	GetSeed(path string) (*Seed, error)
+
 	// Dependency operations
 	SetDependencies(modelID string, parentIDs []string) error
 	GetDependencies(modelID string) ([]string, error)
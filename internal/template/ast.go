@@ -7,6 +7,7 @@ type Position struct {
 	File   string
 	Line   int
 	Column int
+	Offset int // 0-based byte offset
 }
 
 // Node is the interface for all template AST nodes.
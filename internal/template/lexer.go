@@ -58,6 +58,7 @@ type Lexer struct {
 	col      int // current column number (1-based)
 	lastLine int // line at start of current token
 	lastCol  int // column at start of current token
+	lastPos  int // offset at start of current token
 }
 
 // NewLexer creates a new lexer for the given input.
@@ -266,14 +267,15 @@ func (l *Lexer) skipWhitespace() {
 func (l *Lexer) markStart() {
 	l.lastLine = l.line
 	l.lastCol = l.col
+	l.lastPos = l.pos
 }
 
 // position returns the current position.
 func (l *Lexer) position() Position {
-	return Position{File: l.file, Line: l.line, Column: l.col}
+	return Position{File: l.file, Line: l.line, Column: l.col, Offset: l.pos}
 }
 
 // startPosition returns the position where the current token started.
 func (l *Lexer) startPosition() Position {
-	return Position{File: l.file, Line: l.lastLine, Column: l.lastCol}
+	return Position{File: l.file, Line: l.lastLine, Column: l.lastCol, Offset: l.lastPos}
 }
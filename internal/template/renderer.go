@@ -9,8 +9,9 @@ import (
 
 // Renderer executes a parsed template with a Starlark context.
 type Renderer struct {
-	ctx    *starctx.ExecutionContext
-	locals starlark.StringDict // Local variables (e.g., loop variables)
+	ctx       *starctx.ExecutionContext
+	locals    starlark.StringDict // Local variables (e.g., loop variables)
+	sourceMap *SourceMap          // Records where output comes from, if non-nil
 }
 
 // NewRenderer creates a new renderer with the given execution context.
@@ -46,6 +47,7 @@ func (r *Renderer) renderNodes(nodes []Node, buf *strings.Builder, file string)
 func (r *Renderer) renderNode(node Node, buf *strings.Builder, file string) error {
 	switch n := node.(type) {
 	case *TextNode:
+		r.sourceMap.add(buf.Len(), n, n.Text, true)
 		buf.WriteString(n.Text)
 
 	case *ExprNode:
@@ -53,6 +55,7 @@ func (r *Renderer) renderNode(node Node, buf *strings.Builder, file string) erro
 		if err != nil {
 			return WrapRenderError(n.Pos(), "expression evaluation failed", err)
 		}
+		r.sourceMap.add(buf.Len(), n, result, false)
 		buf.WriteString(result)
 
 	case *ForBlock:
@@ -95,8 +98,9 @@ func (r *Renderer) renderForBlock(block *ForBlock, buf *strings.Builder, file st
 
 		// Render body with loop context
 		loopRenderer := &Renderer{
-			ctx:    r.ctx,
-			locals: loopLocals,
+			ctx:       r.ctx,
+			locals:    loopLocals,
+			sourceMap: r.sourceMap,
 		}
 		if err := loopRenderer.renderNodes(block.Body, buf, file); err != nil {
 			return err
@@ -147,6 +151,19 @@ func (r *Renderer) renderIfBlock(block *IfBlock, buf *strings.Builder, file stri
 	return nil
 }
 
+// RenderWithSourceMap executes the template and returns the rendered SQL
+// along with a map from its offsets back to the template source.
+func (r *Renderer) RenderWithSourceMap(tmpl *Template) (string, *SourceMap, error) {
+	r.sourceMap = &SourceMap{}
+	defer func() { r.sourceMap = nil }()
+
+	sql, err := r.Render(tmpl)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, r.sourceMap, nil
+}
+
 // RenderString is a convenience function to render a template string.
 func RenderString(input, file string, ctx *starctx.ExecutionContext) (string, error) {
 	tmpl, err := ParseString(input, file)
@@ -157,3 +174,15 @@ func RenderString(input, file string, ctx *starctx.ExecutionContext) (string, er
 	renderer := NewRenderer(ctx)
 	return renderer.Render(tmpl)
 }
+
+// RenderStringWithSourceMap renders a template string and returns a map from
+// offsets in the rendered SQL back to the template source.
+func RenderStringWithSourceMap(input, file string, ctx *starctx.ExecutionContext) (string, *SourceMap, error) {
+	tmpl, err := ParseString(input, file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	renderer := NewRenderer(ctx)
+	return renderer.RenderWithSourceMap(tmpl)
+}
@@ -397,3 +397,35 @@ func TestRenderer_TruthyFalsy(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderer_SourceMap(t *testing.T) {
+	input := "SELECT id\nFROM {{ target.schema }}.users\n{* for c in ['a', 'b']: *}\nJOIN {{ c }} ON bad\n{* endfor *}"
+	ctx := newTestContext()
+
+	result, sourceMap, err := RenderStringWithSourceMap(input, "test.sql", ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		find   string // text of the rendered SQL, by its last occurrence
+		line   int
+		column int
+	}{
+		{"id", 1, 8},
+		{"analytics", 2, 6}, // expression output maps to its {{
+		{".users", 2, 25},   // text after an expression
+		{"bad", 4, 17},      // loop bodies map to the template, each iteration alike
+		{"b ON", 4, 6},
+	}
+	for _, tt := range tests {
+		offset := strings.LastIndex(result, tt.find)
+		if offset < 0 {
+			t.Fatalf("%q not in %q", tt.find, result)
+		}
+		pos := sourceMap.Position(offset)
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("%q maps to %d:%d, want %d:%d", tt.find, pos.Line, pos.Column, tt.line, tt.column)
+		}
+	}
+}
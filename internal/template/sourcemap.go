@@ -0,0 +1,58 @@
+package template
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SourceMap maps byte offsets in rendered output back to positions in the
+// template source, so errors found in rendered SQL can point at the file.
+// Literal text maps character by character; everything an expression
+// produces maps to the start of its {{.
+type SourceMap struct {
+	segments []segment
+}
+
+// segment is a run of output produced by one node.
+type segment struct {
+	out     int      // offset of the run in the output
+	pos     Position // source position of the node
+	text    string   // the text, for literal runs
+	literal bool
+}
+
+// add records that the output from offset out on comes from node.
+func (m *SourceMap) add(out int, node Node, text string, literal bool) {
+	if m == nil {
+		return
+	}
+	m.segments = append(m.segments, segment{out: out, pos: node.Pos(), text: text, literal: literal})
+}
+
+// Position returns the source position of a byte offset in the rendered
+// output. Offsets past the end map to the end of the last literal text.
+func (m *SourceMap) Position(offset int) Position {
+	i := sort.Search(len(m.segments), func(i int) bool {
+		return m.segments[i].out > offset
+	}) - 1
+	if i < 0 {
+		return Position{Line: 1, Column: 1}
+	}
+
+	seg := m.segments[i]
+	if !seg.literal {
+		return seg.pos
+	}
+
+	prefix := seg.text[:min(offset-seg.out, len(seg.text))]
+	pos := seg.pos
+	pos.Offset += len(prefix)
+	if nl := strings.LastIndexByte(prefix, '\n'); nl >= 0 {
+		pos.Line += strings.Count(prefix, "\n")
+		pos.Column = utf8.RuneCountInString(prefix[nl+1:]) + 1
+	} else {
+		pos.Column += utf8.RuneCountInString(prefix)
+	}
+	return pos
+}
@@ -1,17 +1,38 @@
 package lineage
 
+// Node is implemented by every AST node.
+type Node interface {
+	NodeSpan() Span
+}
+
+// Span is the source range of a node: Start is its first character and End
+// is just past its last.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// NodeSpan returns the span, so that every node embedding a Span is a Node.
+func (s Span) NodeSpan() Span { return s }
+
+// setSpan sets the span; the parser records spans through it.
+func (s *Span) setSpan(span Span) { *s = span }
+
 // Statement represents a SQL statement.
 type Statement interface {
+	Node
 	stmtNode()
 }
 
 // Expr represents an expression in SQL.
 type Expr interface {
+	Node
 	exprNode()
 }
 
 // TableRef represents a table reference in FROM clause.
 type TableRef interface {
+	Node
 	tableRefNode()
 }
 
@@ -19,26 +40,130 @@ type TableRef interface {
 
 // SelectStmt represents a complete SELECT statement with optional WITH clause.
 type SelectStmt struct {
+	Span
 	With *WithClause
 	Body *SelectBody
 }
 
 func (*SelectStmt) stmtNode() {}
 
+// CreateStmt represents CREATE [OR REPLACE] TABLE or VIEW, with or without
+// AS query.
+type CreateStmt struct {
+	Span
+	OrReplace   bool
+	IfNotExists bool
+	View        bool
+	Target      *TableName
+	Columns     []string    // explicit column names: CREATE VIEW v (a, b) AS ...
+	Select      *SelectStmt // nil for CREATE TABLE with column definitions
+}
+
+func (*CreateStmt) stmtNode() {}
+
+// InsertStmt represents INSERT INTO ... SELECT or INSERT INTO ... VALUES.
+type InsertStmt struct {
+	Span
+	With    *WithClause // CTEs of a leading WITH, or nil
+	Target  *TableName
+	Columns []string // target column list, if given
+	ByName  bool     // INSERT INTO t BY NAME SELECT ...
+	Select  *SelectStmt
+	Values  [][]Expr // VALUES rows
+}
+
+func (*InsertStmt) stmtNode() {}
+
+// UpdateStmt represents UPDATE ... SET ... [FROM ...] [WHERE ...].
+type UpdateStmt struct {
+	Span
+	With   *WithClause // CTEs of a leading WITH, or nil
+	Target *TableName
+	Set    []Assignment
+	From   *FromClause
+	Where  Expr
+}
+
+func (*UpdateStmt) stmtNode() {}
+
+// DeleteStmt represents DELETE FROM ... [USING ...] [WHERE ...].
+type DeleteStmt struct {
+	Span
+	With   *WithClause // CTEs of a leading WITH, or nil
+	Target *TableName
+	Using  *FromClause
+	Where  Expr
+}
+
+func (*DeleteStmt) stmtNode() {}
+
+// MergeStmt represents MERGE INTO target USING source ON ... WHEN ... THEN ....
+type MergeStmt struct {
+	Span
+	Target  *TableName
+	Source  TableRef
+	On      Expr
+	Clauses []MergeClause
+}
+
+func (*MergeStmt) stmtNode() {}
+
+// MergeClause represents a WHEN [NOT] MATCHED clause of a MERGE.
+type MergeClause struct {
+	Span
+	Matched   bool
+	BySource  bool // WHEN NOT MATCHED BY SOURCE
+	Condition Expr // AND condition
+	Action    MergeAction
+	Set       []Assignment // UPDATE SET assignments
+	Columns   []string     // INSERT column list
+	Values    []Expr       // INSERT VALUES
+	Star      bool         // UPDATE SET * or INSERT *: every source column
+}
+
+// MergeAction is the action of a MERGE clause.
+type MergeAction string
+
+const (
+	MergeUpdate    MergeAction = "UPDATE"
+	MergeInsert    MergeAction = "INSERT"
+	MergeDelete    MergeAction = "DELETE"
+	MergeDoNothing MergeAction = "DO NOTHING"
+)
+
+// Assignment represents column = value in UPDATE SET.
+type Assignment struct {
+	Span
+	Column string
+	Value  Expr
+}
+
+// UnsupportedStmt represents a statement a script may contain that has no
+// lineage, such as SET, COPY or INSTALL. Its tokens are skipped.
+type UnsupportedStmt struct {
+	Span
+	Keyword string // first word of the statement
+}
+
+func (*UnsupportedStmt) stmtNode() {}
+
 // WithClause represents a WITH clause with CTEs.
 type WithClause struct {
+	Span
 	Recursive bool
 	CTEs      []*CTE
 }
 
 // CTE represents a Common Table Expression.
 type CTE struct {
+	Span
 	Name   string
 	Select *SelectStmt
 }
 
 // SelectBody represents the body of a SELECT with possible set operations.
 type SelectBody struct {
+	Span
 	Left  *SelectCore
 	Op    SetOpType   // UNION, INTERSECT, EXCEPT, or empty
 	All   bool        // UNION ALL
@@ -58,6 +183,7 @@ const (
 
 // SelectCore represents the core SELECT clause.
 type SelectCore struct {
+	Span
 	Distinct bool
 	Columns  []SelectItem
 	From     *FromClause
@@ -72,6 +198,7 @@ type SelectCore struct {
 
 // SelectItem represents an item in the SELECT list.
 type SelectItem struct {
+	Span
 	Star      bool   // SELECT *
 	TableStar string // SELECT t.*
 	Expr      Expr   // Expression
@@ -80,15 +207,19 @@ type SelectItem struct {
 
 // FromClause represents the FROM clause.
 type FromClause struct {
+	Span
 	Source TableRef
 	Joins  []*Join
 }
 
 // Join represents a JOIN clause.
 type Join struct {
+	Span
 	Type      JoinType
 	Right     TableRef
 	Condition Expr
+	Using     []string // USING (col, ...) key columns
+	Natural   bool     // NATURAL join on all shared columns
 }
 
 // JoinType represents the type of join.
@@ -105,6 +236,7 @@ const (
 
 // OrderByItem represents an item in ORDER BY clause.
 type OrderByItem struct {
+	Span
 	Expr       Expr
 	Desc       bool
 	NullsFirst *bool // nil means default, true = NULLS FIRST, false = NULLS LAST
@@ -114,6 +246,7 @@ type OrderByItem struct {
 
 // TableName represents a table name reference.
 type TableName struct {
+	Span
 	Catalog string
 	Schema  string
 	Name    string
@@ -124,6 +257,7 @@ func (*TableName) tableRefNode() {}
 
 // DerivedTable represents a subquery in FROM clause.
 type DerivedTable struct {
+	Span
 	Select *SelectStmt
 	Alias  string
 }
@@ -132,24 +266,80 @@ func (*DerivedTable) tableRefNode() {}
 
 // LateralTable represents a LATERAL subquery.
 type LateralTable struct {
+	Span
 	Select *SelectStmt
 	Alias  string
 }
 
 func (*LateralTable) tableRefNode() {}
 
+// TableFunction represents a function call in FROM, e.g. FLATTEN(input => col)
+// or read_parquet('orders.parquet') AS o(id, amount).
+type TableFunction struct {
+	Span
+	Name    string // upper-cased function name
+	Args    []Expr
+	Alias   string
+	Columns []string // column aliases: AS t(a, b)
+	Lateral bool
+	Table   bool // wrapped in TABLE(...)
+}
+
+func (*TableFunction) tableRefNode() {}
+
+// PivotTable represents a PIVOT, either in FROM:
+// sales PIVOT (SUM(amount) FOR quarter IN ('q1', 'q2')) AS p
+// or as DuckDB's statement form: PIVOT sales ON quarter USING SUM(amount).
+type PivotTable struct {
+	Span
+	Source     TableRef
+	Aggregates []SelectItem // aggregates computed for each pivoted value
+	On         []PivotColumn
+	GroupBy    []Expr // explicit group columns (statement form only)
+	Alias      string
+	Statement  bool // written as the PIVOT statement
+}
+
+func (*PivotTable) tableRefNode() {}
+
+// PivotColumn is a column whose values become output columns.
+type PivotColumn struct {
+	Span
+	Expr   Expr
+	Values []SelectItem // IN list; nil when values are only known at run time
+}
+
+// UnpivotTable represents an UNPIVOT, either in FROM:
+// t UNPIVOT (amount FOR quarter IN (q1, q2))
+// or as DuckDB's statement form: UNPIVOT t ON q1, q2 INTO NAME quarter VALUE amount.
+type UnpivotTable struct {
+	Span
+	Source       TableRef
+	On           []Expr   // columns turned into rows
+	Name         string   // column holding the unpivoted column names
+	Values       []string // columns holding the unpivoted values
+	IncludeNulls bool
+	Alias        string
+	Statement    bool // written as the UNPIVOT statement
+}
+
+func (*UnpivotTable) tableRefNode() {}
+
 // ---------- Expression Types ----------
 
 // ColumnRef represents a column reference (possibly qualified).
 type ColumnRef struct {
+	Span
 	Table  string // optional table/alias qualifier
 	Column string
+	Path   []string // every dotted part when there are more than two: s.t.c or t.struct.field
 }
 
 func (*ColumnRef) exprNode() {}
 
 // Literal represents a literal value.
 type Literal struct {
+	Span
 	Type  LiteralType
 	Value string
 }
@@ -168,6 +358,7 @@ const (
 
 // BinaryExpr represents a binary expression.
 type BinaryExpr struct {
+	Span
 	Left  Expr
 	Op    string
 	Right Expr
@@ -177,6 +368,7 @@ func (*BinaryExpr) exprNode() {}
 
 // UnaryExpr represents a unary expression.
 type UnaryExpr struct {
+	Span
 	Op   string // -, +, NOT
 	Expr Expr
 }
@@ -185,6 +377,7 @@ func (*UnaryExpr) exprNode() {}
 
 // FuncCall represents a function call.
 type FuncCall struct {
+	Span
 	Name     string
 	Distinct bool
 	Args     []Expr
@@ -197,6 +390,7 @@ func (*FuncCall) exprNode() {}
 
 // WindowSpec represents a window specification (OVER clause).
 type WindowSpec struct {
+	Span
 	Name        string // Named window reference
 	PartitionBy []Expr
 	OrderBy     []OrderByItem
@@ -205,6 +399,7 @@ type WindowSpec struct {
 
 // FrameSpec represents a window frame specification.
 type FrameSpec struct {
+	Span
 	Type  FrameType
 	Start *FrameBound
 	End   *FrameBound
@@ -221,6 +416,7 @@ const (
 
 // FrameBound represents a window frame bound.
 type FrameBound struct {
+	Span
 	Type   FrameBoundType
 	Offset Expr // for N PRECEDING/FOLLOWING
 }
@@ -238,6 +434,7 @@ const (
 
 // CaseExpr represents a CASE expression.
 type CaseExpr struct {
+	Span
 	Operand Expr // CASE operand WHEN... (optional)
 	Whens   []WhenClause
 	Else    Expr
@@ -247,20 +444,24 @@ func (*CaseExpr) exprNode() {}
 
 // WhenClause represents a WHEN clause in CASE expression.
 type WhenClause struct {
+	Span
 	Condition Expr
 	Result    Expr
 }
 
 // CastExpr represents a CAST expression.
 type CastExpr struct {
-	Expr     Expr
-	TypeName string
+	Span
+	Expr        Expr
+	TypeName    string
+	DoubleColon bool // written expr::type
 }
 
 func (*CastExpr) exprNode() {}
 
 // InExpr represents an IN expression.
 type InExpr struct {
+	Span
 	Expr   Expr
 	Not    bool
 	Values []Expr      // IN (1, 2, 3)
@@ -271,6 +472,7 @@ func (*InExpr) exprNode() {}
 
 // BetweenExpr represents a BETWEEN expression.
 type BetweenExpr struct {
+	Span
 	Expr Expr
 	Not  bool
 	Low  Expr
@@ -281,6 +483,7 @@ func (*BetweenExpr) exprNode() {}
 
 // IsNullExpr represents an IS NULL expression.
 type IsNullExpr struct {
+	Span
 	Expr Expr
 	Not  bool
 }
@@ -289,6 +492,7 @@ func (*IsNullExpr) exprNode() {}
 
 // LikeExpr represents a LIKE expression.
 type LikeExpr struct {
+	Span
 	Expr    Expr
 	Not     bool
 	Pattern Expr
@@ -299,6 +503,7 @@ func (*LikeExpr) exprNode() {}
 
 // ParenExpr represents a parenthesized expression.
 type ParenExpr struct {
+	Span
 	Expr Expr
 }
 
@@ -306,6 +511,7 @@ func (*ParenExpr) exprNode() {}
 
 // StarExpr represents a * expression (for SELECT *).
 type StarExpr struct {
+	Span
 	Table string // optional table qualifier for t.*
 }
 
@@ -313,6 +519,7 @@ func (*StarExpr) exprNode() {}
 
 // SubqueryExpr represents a subquery used as an expression (e.g., in EXISTS).
 type SubqueryExpr struct {
+	Span
 	Select *SelectStmt
 }
 
@@ -320,8 +527,72 @@ func (*SubqueryExpr) exprNode() {}
 
 // ExistsExpr represents an EXISTS expression.
 type ExistsExpr struct {
+	Span
 	Not    bool
 	Select *SelectStmt
 }
 
 func (*ExistsExpr) exprNode() {}
+
+// ListExpr represents a list literal: [1, 2, 3].
+type ListExpr struct {
+	Span
+	Elements []Expr
+}
+
+func (*ListExpr) exprNode() {}
+
+// IndexExpr represents a list subscript or slice: xs[1], xs[1:3].
+type IndexExpr struct {
+	Span
+	Expr  Expr
+	Index Expr // start of a slice; nil for xs[:n]
+	End   Expr // end of a slice; nil for xs[n:]
+	Slice bool
+}
+
+func (*IndexExpr) exprNode() {}
+
+// FieldExpr represents struct field access on an expression that is not a
+// plain column, e.g. (s).field or xs[1].field. Dotted column paths stay a
+// ColumnRef.
+type FieldExpr struct {
+	Span
+	Expr  Expr
+	Field string
+}
+
+func (*FieldExpr) exprNode() {}
+
+// StructExpr represents a struct literal: {'a': 1, 'b': x}.
+type StructExpr struct {
+	Span
+	Fields []StructField
+}
+
+func (*StructExpr) exprNode() {}
+
+// StructField is a single key/value pair of a struct literal.
+type StructField struct {
+	Span
+	Name  string
+	Value Expr
+}
+
+// LambdaExpr represents a lambda function argument: x -> x + 1, (a, b) -> a + b.
+type LambdaExpr struct {
+	Span
+	Params []string
+	Body   Expr
+}
+
+func (*LambdaExpr) exprNode() {}
+
+// NamedArg represents a named function argument: name => value.
+type NamedArg struct {
+	Span
+	Name  string
+	Value Expr
+}
+
+func (*NamedArg) exprNode() {}
@@ -34,6 +34,14 @@ type OperatorConfig struct {
 	ConcatCoalesce bool // CONCAT treats NULL as empty string (default: false)
 }
 
+// SyntaxConfig defines dialect-specific syntax accepted by the lexer and parser.
+type SyntaxConfig struct {
+	DoubleColonCast     bool // expr::type casts (Postgres, Snowflake, DuckDB)
+	DoubleQuotedStrings bool // "..." is a string literal rather than an identifier (BigQuery)
+	BackslashEscapes    bool // string literals use backslash escapes (BigQuery)
+	QuotedPaths         bool // a quoted identifier may hold a dotted path: `project.dataset.table` (BigQuery)
+}
+
 // LineageType classifies how a function affects lineage.
 type LineageType int
 
@@ -69,12 +77,19 @@ type Dialect struct {
 	Name        string
 	Identifiers IdentifierConfig
 	Operators   OperatorConfig
+	Syntax      SyntaxConfig
 
 	// Function classifications (normalized to dialect's normalization strategy)
 	aggregates map[string]struct{}
 	generators map[string]struct{}
 	windows    map[string]struct{}
 	aliases    map[string]string // alias -> canonical name
+	spellings  map[string]string // canonical name -> name in this dialect
+
+	// Output columns of table functions used in FROM (e.g., Snowflake FLATTEN)
+	tableFunctions map[string][]string
+	// Table functions that read files, whose path is an external source
+	fileReaders map[string]struct{}
 }
 
 // FunctionLineageType returns the lineage classification for a function.
@@ -110,6 +125,28 @@ func (d *Dialect) NormalizeName(name string) string {
 	}
 }
 
+// foldUnquoted returns an unquoted identifier as the database stores it:
+// dialects that fold unquoted names to upper or lower case fold it, others
+// keep its spelling.
+func (d *Dialect) foldUnquoted(name string) string {
+	switch d.Identifiers.Normalization {
+	case NormUppercase, NormLowercase:
+		return d.NormalizeName(name)
+	default:
+		return name
+	}
+}
+
+// identKey returns the key lineage compares an identifier by. Lineage lexes
+// unquoted names folded and quoted names as written, so only case-insensitive
+// dialects, which ignore case even when quoted, fold here.
+func (d *Dialect) identKey(name string) string {
+	if d.Identifiers.Normalization == NormCaseInsensitive {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
 // CanonicalFunctionName returns the canonical name for a function (resolving aliases).
 func (d *Dialect) CanonicalFunctionName(name string) string {
 	normalized := d.NormalizeName(name)
@@ -119,6 +156,18 @@ func (d *Dialect) CanonicalFunctionName(name string) string {
 	return normalized
 }
 
+// TableFunctionColumns returns the output columns of a table function, if known.
+func (d *Dialect) TableFunctionColumns(name string) ([]string, bool) {
+	cols, ok := d.tableFunctions[d.CanonicalFunctionName(name)]
+	return cols, ok
+}
+
+// IsFileReader returns true if the table function reads files (read_parquet, etc.).
+func (d *Dialect) IsFileReader(name string) bool {
+	_, ok := d.fileReaders[d.CanonicalFunctionName(name)]
+	return ok
+}
+
 // IsAggregate returns true if the function is an aggregate function.
 func (d *Dialect) IsAggregate(name string) bool {
 	return d.FunctionLineageType(name) == LineageAggregate
@@ -158,6 +207,10 @@ func NewDialect(name string) *DialectBuilder {
 			generators: make(map[string]struct{}),
 			windows:    make(map[string]struct{}),
 			aliases:    make(map[string]string),
+			spellings:  make(map[string]string),
+
+			tableFunctions: make(map[string][]string),
+			fileReaders:    make(map[string]struct{}),
 		},
 	}
 }
@@ -182,6 +235,12 @@ func (b *DialectBuilder) Operators(dpipeIsConcat, concatCoalesce bool) *DialectB
 	return b
 }
 
+// Syntax configures dialect-specific syntax.
+func (b *DialectBuilder) Syntax(syntax SyntaxConfig) *DialectBuilder {
+	b.dialect.Syntax = syntax
+	return b
+}
+
 // Aggregates adds aggregate functions to the dialect.
 func (b *DialectBuilder) Aggregates(funcs ...string) *DialectBuilder {
 	for _, f := range funcs {
@@ -214,6 +273,32 @@ func (b *DialectBuilder) Aliases(aliases map[string]string) *DialectBuilder {
 	return b
 }
 
+// Spellings adds the names this dialect writes functions with, for canonical
+// names (canonical -> name) it doesn't accept. Transpile uses them when
+// writing SQL for the dialect.
+func (b *DialectBuilder) Spellings(spellings map[string]string) *DialectBuilder {
+	for k, v := range spellings {
+		b.dialect.spellings[b.dialect.NormalizeName(k)] = b.dialect.NormalizeName(v)
+	}
+	return b
+}
+
+// TableFunctions adds table functions with known output columns.
+func (b *DialectBuilder) TableFunctions(funcs map[string][]string) *DialectBuilder {
+	for name, cols := range funcs {
+		b.dialect.tableFunctions[b.dialect.NormalizeName(name)] = cols
+	}
+	return b
+}
+
+// FileReaders adds table functions that read files, like read_parquet.
+func (b *DialectBuilder) FileReaders(funcs ...string) *DialectBuilder {
+	for _, f := range funcs {
+		b.dialect.fileReaders[b.dialect.NormalizeName(f)] = struct{}{}
+	}
+	return b
+}
+
 // Build returns the constructed dialect.
 func (b *DialectBuilder) Build() *Dialect {
 	return b.dialect
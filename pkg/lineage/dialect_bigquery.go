@@ -0,0 +1,62 @@
+package lineage
+
+// BigQuery dialect definition.
+
+func init() {
+	RegisterDialect(BigQuery)
+}
+
+// BigQuery is the BigQuery (GoogleSQL) dialect configuration.
+// Identifiers are quoted with backticks, and a quoted identifier may hold a
+// whole path such as `project.dataset.table`.
+var BigQuery = NewDialect("bigquery").
+	Identifiers("`", "`", "\\`", NormCaseInsensitive).
+	Operators(true, false). // || is concat, CONCAT returns NULL on NULL input
+	Syntax(SyntaxConfig{
+		DoubleQuotedStrings: true,
+		BackslashEscapes:    true,
+		QuotedPaths:         true,
+	}).
+	Aggregates(
+		// Standard aggregates
+		"SUM", "COUNT", "AVG", "MIN", "MAX",
+		"STDDEV", "STDDEV_POP", "STDDEV_SAMP",
+		"VARIANCE", "VAR_POP", "VAR_SAMP",
+		// BigQuery specific
+		"COUNTIF", "ARRAY_AGG", "ARRAY_CONCAT_AGG", "STRING_AGG",
+		"ANY_VALUE", "LOGICAL_AND", "LOGICAL_OR",
+		"BIT_AND", "BIT_OR", "BIT_XOR",
+		"APPROX_COUNT_DISTINCT", "APPROX_QUANTILES", "APPROX_TOP_COUNT", "APPROX_TOP_SUM",
+		"CORR", "COVAR_POP", "COVAR_SAMP",
+	).
+	Generators(
+		// Date/time generators
+		"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_DATETIME",
+		// Value generators
+		"GENERATE_UUID", "RAND",
+		// System functions
+		"SESSION_USER",
+	).
+	Windows(
+		// Ranking functions
+		"ROW_NUMBER", "RANK", "DENSE_RANK", "NTILE", "PERCENT_RANK", "CUME_DIST",
+		// Value functions
+		"LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "NTH_VALUE",
+	).
+	Aliases(map[string]string{
+		// NULL handling
+		"IFNULL": "COALESCE",
+		// String functions
+		"SUBSTR":           "SUBSTRING",
+		"CHAR_LENGTH":      "LENGTH",
+		"CHARACTER_LENGTH": "LENGTH",
+		// Aggregate aliases
+		"STDDEV":   "STDDEV_SAMP",
+		"VARIANCE": "VAR_SAMP",
+	}).
+	Spellings(map[string]string{
+		"LISTAGG": "STRING_AGG",
+		"IFF":     "IF",
+		"NOW":     "CURRENT_TIMESTAMP",
+	}).
+	Build()
@@ -1,7 +1,7 @@
 package lineage
 
 // DuckDB dialect definition.
-// For additional dialects, create new files like dialect_snowflake.go, dialect_bigquery.go, etc.
+// Other dialects live in dialect_postgres.go, dialect_snowflake.go and dialect_bigquery.go.
 
 func init() {
 	RegisterDialect(DuckDB)
@@ -11,6 +11,7 @@ func init() {
 var DuckDB = NewDialect("duckdb").
 	Identifiers(`"`, `"`, `""`, NormCaseInsensitive).
 	Operators(true, true). // || is concat, CONCAT coalesces NULL
+	Syntax(SyntaxConfig{DoubleColonCast: true}).
 	Aggregates(
 		// Standard aggregates
 		"SUM", "COUNT", "AVG", "MIN", "MAX",
@@ -54,7 +55,6 @@ var DuckDB = NewDialect("duckdb").
 		// NULL handling
 		"IFNULL": "COALESCE",
 		"NVL":    "COALESCE",
-		"NVL2":   "IF",
 		// String functions
 		"SUBSTR":      "SUBSTRING",
 		"LEN":         "LENGTH",
@@ -66,7 +66,25 @@ var DuckDB = NewDialect("duckdb").
 		"COLLECT_SET":  "LIST",
 		// Array
 		"ARRAY_LENGTH": "LEN",
+		// File readers
+		"PARQUET_SCAN":     "READ_PARQUET",
+		"READ_CSV_AUTO":    "READ_CSV",
+		"READ_JSON_AUTO":   "READ_JSON",
+		"READ_NDJSON_AUTO": "READ_NDJSON",
 	}).
+	Spellings(map[string]string{
+		"LISTAGG": "STRING_AGG",
+	}).
+	TableFunctions(map[string][]string{
+		// Column names DuckDB gives when no column aliases are set
+		"RANGE":           {"range"},
+		"GENERATE_SERIES": {"generate_series"},
+		"UNNEST":          {"unnest"},
+	}).
+	FileReaders(
+		"READ_PARQUET", "READ_CSV", "READ_JSON", "READ_NDJSON",
+		"READ_TEXT", "READ_BLOB", "DELTA_SCAN", "ICEBERG_SCAN",
+	).
 	Build()
 
 // DefaultDialect returns the default dialect (DuckDB).
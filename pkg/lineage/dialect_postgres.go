@@ -0,0 +1,66 @@
+package lineage
+
+// Postgres dialect definition.
+
+func init() {
+	RegisterDialect(Postgres)
+}
+
+// Postgres is the PostgreSQL dialect configuration.
+var Postgres = NewDialect("postgres").
+	Identifiers(`"`, `"`, `""`, NormLowercase).
+	Operators(true, true). // || is concat, CONCAT ignores NULL
+	Syntax(SyntaxConfig{DoubleColonCast: true}).
+	Aggregates(
+		// Standard aggregates
+		"SUM", "COUNT", "AVG", "MIN", "MAX",
+		"STDDEV", "STDDEV_POP", "STDDEV_SAMP",
+		"VARIANCE", "VAR_POP", "VAR_SAMP",
+		// Postgres specific
+		"ARRAY_AGG", "STRING_AGG", "JSON_AGG", "JSONB_AGG",
+		"JSON_OBJECT_AGG", "JSONB_OBJECT_AGG", "XMLAGG",
+		"BIT_AND", "BIT_OR", "BOOL_AND", "BOOL_OR", "EVERY",
+		"MODE", "PERCENTILE_CONT", "PERCENTILE_DISC",
+		"CORR", "COVAR_POP", "COVAR_SAMP", "REGR_AVGX", "REGR_AVGY",
+		"REGR_COUNT", "REGR_INTERCEPT", "REGR_R2", "REGR_SLOPE",
+		"REGR_SXX", "REGR_SXY", "REGR_SYY",
+	).
+	Generators(
+		// Date/time generators
+		"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME",
+		"NOW", "LOCALTIME", "LOCALTIMESTAMP",
+		"CLOCK_TIMESTAMP", "STATEMENT_TIMESTAMP", "TRANSACTION_TIMESTAMP", "TIMEOFDAY",
+		// Value generators
+		"GEN_RANDOM_UUID", "RANDOM",
+		// Constants
+		"PI",
+		// System functions
+		"CURRENT_SCHEMA", "CURRENT_DATABASE", "CURRENT_CATALOG",
+		"CURRENT_USER", "SESSION_USER", "VERSION",
+	).
+	Windows(
+		// Ranking functions
+		"ROW_NUMBER", "RANK", "DENSE_RANK", "NTILE", "PERCENT_RANK", "CUME_DIST",
+		// Value functions
+		"LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "NTH_VALUE",
+	).
+	Aliases(map[string]string{
+		// String functions
+		"SUBSTR":           "SUBSTRING",
+		"CHAR_LENGTH":      "LENGTH",
+		"CHARACTER_LENGTH": "LENGTH",
+		// Aggregate aliases
+		"STDDEV":   "STDDEV_SAMP",
+		"VARIANCE": "VAR_SAMP",
+		"EVERY":    "BOOL_AND",
+		// Date/time
+		"TRANSACTION_TIMESTAMP": "NOW",
+	}).
+	Spellings(map[string]string{
+		"LISTAGG": "STRING_AGG",
+	}).
+	TableFunctions(map[string][]string{
+		"GENERATE_SERIES": {"generate_series"},
+		"UNNEST":          {"unnest"},
+	}).
+	Build()
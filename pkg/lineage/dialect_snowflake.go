@@ -0,0 +1,75 @@
+package lineage
+
+// Snowflake dialect definition.
+
+func init() {
+	RegisterDialect(Snowflake)
+}
+
+// Snowflake is the Snowflake dialect configuration.
+// Unquoted identifiers resolve to upper case.
+var Snowflake = NewDialect("snowflake").
+	Identifiers(`"`, `"`, `""`, NormUppercase).
+	Operators(true, false). // || is concat, CONCAT returns NULL on NULL input
+	Syntax(SyntaxConfig{DoubleColonCast: true}).
+	Aggregates(
+		// Standard aggregates
+		"SUM", "COUNT", "AVG", "MIN", "MAX",
+		"STDDEV", "STDDEV_POP", "STDDEV_SAMP",
+		"VARIANCE", "VAR_POP", "VAR_SAMP",
+		// Snowflake specific
+		"COUNT_IF", "SUM_IF", "LISTAGG", "ARRAY_AGG", "ARRAY_UNIQUE_AGG", "OBJECT_AGG",
+		"ANY_VALUE", "MEDIAN", "MODE", "PERCENTILE_CONT", "PERCENTILE_DISC",
+		"APPROX_COUNT_DISTINCT", "HLL", "APPROX_PERCENTILE", "APPROX_TOP_K",
+		"BITAND_AGG", "BITOR_AGG", "BITXOR_AGG", "BOOLAND_AGG", "BOOLOR_AGG", "BOOLXOR_AGG",
+		"HASH_AGG", "MINHASH", "KURTOSIS", "SKEW",
+		"CORR", "COVAR_POP", "COVAR_SAMP", "REGR_AVGX", "REGR_AVGY",
+		"REGR_COUNT", "REGR_INTERCEPT", "REGR_R2", "REGR_SLOPE",
+		"REGR_SXX", "REGR_SXY", "REGR_SYY",
+	).
+	Generators(
+		// Date/time generators
+		"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME",
+		"SYSDATE", "SYSTIMESTAMP", "GETDATE",
+		"LOCALTIME", "LOCALTIMESTAMP",
+		// Value generators
+		"UUID_STRING", "RANDOM", "SEQ1", "SEQ2", "SEQ4", "SEQ8",
+		// Constants
+		"PI",
+		// System functions
+		"CURRENT_SCHEMA", "CURRENT_DATABASE", "CURRENT_WAREHOUSE",
+		"CURRENT_ROLE", "CURRENT_USER", "CURRENT_ACCOUNT", "CURRENT_REGION",
+		"CURRENT_VERSION",
+	).
+	Windows(
+		// Ranking functions
+		"ROW_NUMBER", "RANK", "DENSE_RANK", "NTILE", "PERCENT_RANK", "CUME_DIST",
+		// Value functions
+		"LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "NTH_VALUE",
+		"CONDITIONAL_CHANGE_EVENT", "CONDITIONAL_TRUE_EVENT", "RATIO_TO_REPORT",
+	).
+	Aliases(map[string]string{
+		// NULL handling
+		"IFNULL": "COALESCE",
+		"NVL":    "COALESCE",
+		"IFF":    "IF",
+		// String functions
+		"SUBSTR": "SUBSTRING",
+		"LEN":    "LENGTH",
+		// Aggregate aliases
+		"ARRAYAGG":      "ARRAY_AGG",
+		"WM_CONCAT":     "LISTAGG",
+		"VARIANCE_SAMP": "VAR_SAMP",
+		// Date/time
+		"GETDATE": "CURRENT_TIMESTAMP",
+		"NOW":     "CURRENT_TIMESTAMP",
+	}).
+	Spellings(map[string]string{
+		"IF":         "IFF",
+		"STRING_AGG": "LISTAGG",
+	}).
+	TableFunctions(map[string][]string{
+		// LATERAL FLATTEN(input => col) exposes one row per element
+		"FLATTEN": {"SEQ", "KEY", "PATH", "INDEX", "VALUE", "THIS"},
+	}).
+	Build()
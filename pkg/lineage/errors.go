@@ -12,6 +12,29 @@ func (e *ParseError) Error() string {
 	return fmt.Sprintf("parse error at line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
 }
 
+// ParseErrors lists the syntax errors of one parse, in source order. The
+// parser recovers after each error, so one pass reports all of them.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no parse errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+// Unwrap returns the errors, so that errors.As finds the first ParseError.
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
 // LexError represents a lexical analysis error.
 type LexError struct {
 	Pos     Position
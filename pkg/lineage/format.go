@@ -0,0 +1,176 @@
+package lineage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formatting: a script reprinted from its AST, keeping the comments and
+// identifier quoting of the source.
+//
+// Comments aren't part of the AST, so they are written at the first line
+// break after their source position. The formatted script is checked against
+// the source: both must have the same tokens, apart from noise words the
+// printer adds or drops (AS, INNER, OUTER, ASC, SELECT ALL, UNION DISTINCT),
+// or Format returns an error instead of changing the SQL.
+
+// FormatOptions control how Format lays out SQL.
+type FormatOptions struct {
+	PrintOptions
+
+	// Anchored reports whether a comment must stay between the same two
+	// tokens, as for placeholders that stand in for template code.
+	Anchored func(comment string) bool
+}
+
+// Format reprints a script of semicolon-separated statements. Statements the
+// parser doesn't model are kept as written.
+func Format(sql string, opts FormatOptions) (string, error) {
+	p := newPrinter(opts.PrintOptions)
+	out, err := p.script(sql, p.dialect)
+	if err != nil {
+		return "", err
+	}
+	if err := verify(sql, out, p.dialect, opts.Anchored); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// script prints a script written in a dialect, keeping its comments and
+// the quoting of its names.
+func (p *printer) script(sql string, dialect *Dialect) (string, error) {
+	p.source = sql
+	tokens, comments := lex(sql, dialect)
+	p.tokens, p.comments = tokens[:len(tokens)-1], comments
+	p.quoted = make(map[string]bool)
+	for _, tok := range tokens {
+		if tok.Quoted {
+			p.quoted[tok.Literal] = true
+		}
+	}
+
+	stmts, err := ParseScriptWithDialect(sql, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	for i, stmt := range stmts {
+		// Statements are separated by an empty line, unless comments on
+		// lines of their own already separate them
+		mark := len(p.out)
+		p.flushComments(stmt.NodeSpan().Start.Offset)
+		ownLine := strings.Contains(string(p.out[mark:]), "\n") || mark == 0 && len(p.out) > 0
+		if !p.lineEmpty() || p.afterLineComment {
+			p.afterLineComment = false
+			p.breakLine()
+		}
+		if i > 0 && !ownLine || ownLine && p.blankBefore(stmt.NodeSpan().Start.Offset) {
+			p.breakLine()
+		}
+		p.statement(stmt)
+		if i < len(stmts)-1 || endsWithSemi(p.tokens) {
+			p.write(";")
+		}
+	}
+	p.flushComments(len(sql) + 1)
+	out := strings.TrimRight(string(p.out), " \n")
+	if out != "" {
+		out += "\n"
+	}
+	return out, nil
+}
+
+// endsWithSemi reports whether a script ends with a semicolon.
+func endsWithSemi(tokens []Token) bool {
+	return len(tokens) > 0 && tokens[len(tokens)-1].Type == TOKEN_SEMI
+}
+
+// lex returns the tokens of sql, ending with EOF, and its comments.
+func lex(sql string, dialect *Dialect) ([]Token, []comment) {
+	l := NewLexerWithDialect(sql, dialect)
+	var tokens []Token
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == TOKEN_EOF {
+			return tokens, l.comments
+		}
+	}
+}
+
+// verify checks that formatting kept the tokens of a script, and the place
+// of its anchored comments.
+func verify(before, after string, dialect *Dialect, anchored func(string) bool) error {
+	oldTokens, oldComments := significant(before, dialect, anchored)
+	newTokens, newComments := significant(after, dialect, anchored)
+
+	for i := 0; i < max(len(oldTokens), len(newTokens)); i++ {
+		if i == len(oldTokens) || i == len(newTokens) || !sameToken(oldTokens[i], newTokens[i]) {
+			pos := Position{Line: 1, Column: 1}
+			if i < len(oldTokens) {
+				pos = oldTokens[i].Pos
+			} else if len(oldTokens) > 0 {
+				pos = oldTokens[len(oldTokens)-1].Pos
+			}
+			return fmt.Errorf("can't format without changing the SQL at line %d, column %d", pos.Line, pos.Column)
+		}
+	}
+	for i := 0; i < max(len(oldComments), len(newComments)); i++ {
+		if i == len(oldComments) || i == len(newComments) || oldComments[i] != newComments[i] {
+			return fmt.Errorf("can't format without moving a comment")
+		}
+	}
+	return nil
+}
+
+// anchoredComment is an anchored comment and the number of significant
+// tokens before it.
+type anchoredComment struct {
+	text  string
+	index int
+}
+
+// significant returns the tokens of sql without the noise words the printer
+// adds or drops, and its anchored comments.
+func significant(sql string, dialect *Dialect, anchored func(string) bool) ([]Token, []anchoredComment) {
+	tokens, comments := lex(sql, dialect)
+	var kept []Token
+	var marks []anchoredComment
+	c := 0
+	for i, tok := range tokens {
+		for ; c < len(comments) && comments[c].Span.Start.Offset < tok.Pos.Offset; c++ {
+			if anchored != nil && anchored(comments[c].Text) {
+				marks = append(marks, anchoredComment{text: comments[c].Text, index: len(kept)})
+			}
+		}
+		prev := TOKEN_EOF
+		if i > 0 {
+			prev = tokens[i-1].Type
+		}
+		switch {
+		case tok.Type == TOKEN_EOF, tok.Type == TOKEN_AS, tok.Type == TOKEN_INNER,
+			tok.Type == TOKEN_OUTER, tok.Type == TOKEN_ASC,
+			tok.Type == TOKEN_ALL && prev == TOKEN_SELECT,
+			tok.Type == TOKEN_DISTINCT && prev == TOKEN_UNION:
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	return kept, marks
+}
+
+// sameToken reports whether two tokens read the same. Unquoted names and
+// keywords are case-insensitive.
+func sameToken(a, b Token) bool {
+	if a.Type != b.Type || a.Quoted != b.Quoted {
+		return false
+	}
+	switch {
+	case a.Quoted, a.Type == TOKEN_STRING, a.Type == TOKEN_NUMBER:
+		return a.Literal == b.Literal
+	case a.Type == TOKEN_IDENT:
+		return strings.EqualFold(a.Literal, b.Literal)
+	}
+	return true
+}
@@ -13,14 +13,37 @@ type Lexer struct {
 	ch      byte // current char under examination
 	line    int  // current line number (1-based)
 	col     int  // current column number (1-based)
+
+	prevLine int // line of the last character read
+	prevCol  int // column of the last character read
+
+	dialect  *Dialect  // quoting and string syntax
+	comments []comment // comments skipped so far, for the formatter
+	fold     bool      // fold unquoted names to the dialect's case, for lineage
 }
 
-// NewLexer creates a new Lexer for the given input.
+// comment is a comment in the source; Text includes its delimiters.
+type comment struct {
+	Text string
+	Span Span
+}
+
+// NewLexer creates a new Lexer for the given input using the default dialect.
 func NewLexer(input string) *Lexer {
+	return NewLexerWithDialect(input, nil)
+}
+
+// NewLexerWithDialect creates a new Lexer that follows a dialect's identifier
+// quoting and string literal syntax.
+func NewLexerWithDialect(input string, dialect *Dialect) *Lexer {
+	if dialect == nil {
+		dialect = DefaultDialect()
+	}
 	l := &Lexer{
-		input: input,
-		line:  1,
-		col:   0,
+		input:   input,
+		line:    1,
+		col:     0,
+		dialect: dialect,
 	}
 	l.readChar()
 	return l
@@ -36,6 +59,7 @@ func (l *Lexer) readChar() {
 	l.pos = l.readPos
 	l.readPos++
 
+	l.prevLine, l.prevCol = l.line, l.col
 	if l.ch == '\n' {
 		l.line++
 		l.col = 0
@@ -63,6 +87,17 @@ func (l *Lexer) currentPos() Position {
 
 // NextToken returns the next token.
 func (l *Lexer) NextToken() Token {
+	tok := l.scanToken()
+	if tok.Type == TOKEN_EOF {
+		tok.End = tok.Pos
+	} else {
+		tok.End = Position{Line: l.prevLine, Column: l.prevCol + 1, Offset: l.pos}
+	}
+	return tok
+}
+
+// scanToken scans the next token, leaving the lexer just past it.
+func (l *Lexer) scanToken() Token {
 	l.skipWhitespaceAndComments()
 
 	pos := l.currentPos()
@@ -77,8 +112,14 @@ func (l *Lexer) NextToken() Token {
 	case '+':
 		tok = l.newToken(TOKEN_PLUS, "+")
 	case '-':
-		// Could be negative number or minus operator
-		tok = l.newToken(TOKEN_MINUS, "-")
+		if l.peekChar() == '>' {
+			// Lambda arrow: x -> x + 1
+			l.readChar()
+			tok = Token{Type: TOKEN_RARROW, Literal: "->", Pos: pos}
+		} else {
+			// Could be negative number or minus operator
+			tok = l.newToken(TOKEN_MINUS, "-")
+		}
 	case '*':
 		tok = l.newToken(TOKEN_STAR, "*")
 	case '/':
@@ -86,7 +127,19 @@ func (l *Lexer) NextToken() Token {
 	case '%':
 		tok = l.newToken(TOKEN_PERCENT, "%")
 	case '=':
-		tok = l.newToken(TOKEN_EQ, "=")
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = Token{Type: TOKEN_ARROW, Literal: "=>", Pos: pos}
+		} else {
+			tok = l.newToken(TOKEN_EQ, "=")
+		}
+	case ':':
+		if l.peekChar() == ':' {
+			l.readChar()
+			tok = Token{Type: TOKEN_DCOLON, Literal: "::", Pos: pos}
+		} else {
+			tok = l.newToken(TOKEN_COLON, ":")
+		}
 	case '<':
 		if l.peekChar() == '=' {
 			l.readChar()
@@ -122,6 +175,8 @@ func (l *Lexer) NextToken() Token {
 		tok = l.newToken(TOKEN_DOT, ".")
 	case ',':
 		tok = l.newToken(TOKEN_COMMA, ",")
+	case ';':
+		tok = l.newToken(TOKEN_SEMI, ";")
 	case '(':
 		tok = l.newToken(TOKEN_LPAREN, "(")
 	case ')':
@@ -130,21 +185,45 @@ func (l *Lexer) NextToken() Token {
 		tok = l.newToken(TOKEN_LBRACKET, "[")
 	case ']':
 		tok = l.newToken(TOKEN_RBRACKET, "]")
+	case '{':
+		tok = l.newToken(TOKEN_LBRACE, "{")
+	case '}':
+		tok = l.newToken(TOKEN_RBRACE, "}")
 	case '\'':
 		tok.Type = TOKEN_STRING
-		tok.Literal = l.readString()
+		tok.Literal = l.readString('\'')
 		tok.Pos = pos
 		return tok
 	case '"':
-		// Quoted identifier (DuckDB/ANSI style)
+		if l.dialect.Syntax.DoubleQuotedStrings {
+			tok.Type = TOKEN_STRING
+			tok.Literal = l.readString('"')
+		} else {
+			// Quoted identifier (DuckDB/ANSI style)
+			tok.Type = TOKEN_IDENT
+			tok.Literal = l.readQuotedIdentifier('"')
+			tok.Quoted = true
+		}
+		tok.Pos = pos
+		return tok
+	case '`':
+		if l.dialect.Identifiers.Quote != "`" {
+			tok = l.newToken(TOKEN_ILLEGAL, string(l.ch))
+			break
+		}
+		// Quoted identifier (BigQuery/MySQL style)
 		tok.Type = TOKEN_IDENT
-		tok.Literal = l.readQuotedIdentifier()
+		tok.Literal = l.readQuotedIdentifier('`')
+		tok.Quoted = true
 		tok.Pos = pos
 		return tok
 	default:
 		if isLetter(l.ch) || l.ch == '_' {
 			tok.Literal = l.readIdentifier()
 			tok.Type = LookupIdent(strings.ToLower(tok.Literal))
+			if l.fold {
+				tok.Literal = l.dialect.foldUnquoted(tok.Literal)
+			}
 			tok.Pos = pos
 			return tok
 		} else if isDigit(l.ch) {
@@ -176,13 +255,17 @@ func (l *Lexer) skipWhitespaceAndComments() {
 
 		// Skip line comment (-- ...)
 		if l.ch == '-' && l.peekChar() == '-' {
+			start := l.currentPos()
 			l.skipLineComment()
+			l.addComment(start)
 			continue
 		}
 
 		// Skip block comment (/* ... */)
 		if l.ch == '/' && l.peekChar() == '*' {
+			start := l.currentPos()
 			l.skipBlockComment()
+			l.addComment(start)
 			continue
 		}
 
@@ -190,6 +273,14 @@ func (l *Lexer) skipWhitespaceAndComments() {
 	}
 }
 
+// addComment records the comment from start to the current position.
+func (l *Lexer) addComment(start Position) {
+	l.comments = append(l.comments, comment{
+		Text: strings.TrimRight(l.input[start.Offset:l.pos], "\r"),
+		Span: Span{Start: start, End: Position{Line: l.prevLine, Column: l.prevCol + 1, Offset: l.pos}},
+	})
+}
+
 // skipLineComment skips a line comment.
 func (l *Lexer) skipLineComment() {
 	for l.ch != '\n' && l.ch != 0 {
@@ -215,9 +306,10 @@ func (l *Lexer) skipBlockComment() {
 	}
 }
 
-// readString reads a single-quoted string literal.
-// Handles doubled single quotes as escape: 'it”s' -> it's
-func (l *Lexer) readString() string {
+// readString reads a string literal delimited by quote.
+// Handles doubled quotes as escape: 'it”s' -> it's, and backslash escapes
+// in dialects that use them: 'it\'s' -> it's
+func (l *Lexer) readString(quote byte) string {
 	l.readChar() // skip opening quote
 
 	var result strings.Builder
@@ -226,10 +318,16 @@ func (l *Lexer) readString() string {
 			// Unterminated string
 			break
 		}
-		if l.ch == '\'' {
-			if l.peekChar() == '\'' {
+		if l.ch == '\\' && l.dialect.Syntax.BackslashEscapes && l.peekChar() != 0 {
+			l.readChar() // skip backslash
+			result.WriteByte(unescape(l.ch))
+			l.readChar()
+			continue
+		}
+		if l.ch == quote {
+			if l.peekChar() == quote {
 				// Doubled quote escape
-				result.WriteByte('\'')
+				result.WriteByte(quote)
 				l.readChar() // skip first quote
 				l.readChar() // skip second quote
 			} else {
@@ -245,9 +343,23 @@ func (l *Lexer) readString() string {
 	return result.String()
 }
 
-// readQuotedIdentifier reads a double-quoted identifier.
-// Handles doubled double quotes as escape: "col""name" -> col"name
-func (l *Lexer) readQuotedIdentifier() string {
+// unescape returns the character a backslash escape stands for.
+func unescape(ch byte) byte {
+	switch ch {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	default:
+		return ch
+	}
+}
+
+// readQuotedIdentifier reads an identifier delimited by quote.
+// Handles doubled quotes as escape: "col""name" -> col"name
+func (l *Lexer) readQuotedIdentifier(quote byte) string {
 	l.readChar() // skip opening quote
 
 	var result strings.Builder
@@ -256,10 +368,10 @@ func (l *Lexer) readQuotedIdentifier() string {
 			// Unterminated identifier
 			break
 		}
-		if l.ch == '"' {
-			if l.peekChar() == '"' {
+		if l.ch == quote {
+			if l.peekChar() == quote {
 				// Doubled quote escape
-				result.WriteByte('"')
+				result.WriteByte(quote)
 				l.readChar() // skip first quote
 				l.readChar() // skip second quote
 			} else {
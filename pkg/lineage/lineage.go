@@ -32,6 +32,7 @@ type ColumnLineage struct {
 // ModelLineage describes the complete lineage of a SQL model.
 type ModelLineage struct {
 	Sources []string         // All source tables (deduplicated, sorted)
+	Files   []string         // File paths read by table functions like read_parquet (also in Sources)
 	Columns []*ColumnLineage // Lineage for each output column
 }
 
@@ -48,6 +49,10 @@ func ExtractLineage(sql string, schema Schema) (*ModelLineage, error) {
 }
 
 // ExtractLineageWithOptions extracts lineage with full configuration options.
+// Names are reported as the dialect resolves them: unquoted names in its case
+// (upper case for Snowflake), quoted names as written, and table names in
+// lower case where the dialect ignores case, so every spelling of a table is
+// one source.
 func ExtractLineageWithOptions(sql string, opts ExtractLineageOptions) (*ModelLineage, error) {
 	dialect := opts.Dialect
 	if dialect == nil {
@@ -55,20 +60,26 @@ func ExtractLineageWithOptions(sql string, opts ExtractLineageOptions) (*ModelLi
 	}
 
 	// Parse the SQL
-	stmt, err := Parse(sql)
-	if err != nil {
-		return nil, err
+	p := newLineageParser(sql, dialect)
+	stmt := p.parseStatement()
+	if len(p.errors) > 0 {
+		return nil, p.errors
 	}
 
-	// Create extractor
-	extractor := &lineageExtractor{
+	// Extract lineage
+	return newLineageExtractor(dialect, opts.Schema).extract(stmt)
+}
+
+// newLineageExtractor creates an extractor for a single statement.
+func newLineageExtractor(dialect *Dialect, schema Schema) *lineageExtractor {
+	return &lineageExtractor{
 		dialect: dialect,
-		schema:  opts.Schema,
+		schema:  schema,
 		sources: make(map[string]struct{}),
-	}
+		files:   make(map[string]struct{}),
 
-	// Extract lineage
-	return extractor.extract(stmt)
+		functions: make(map[*ScopeEntry]struct{}),
+	}
 }
 
 // lineageExtractor walks the AST to extract lineage information.
@@ -76,6 +87,9 @@ type lineageExtractor struct {
 	dialect *Dialect
 	schema  Schema
 	sources map[string]struct{} // Collected source tables
+	files   map[string]struct{} // Collected file paths
+
+	functions map[*ScopeEntry]struct{} // Table functions whose arguments are being resolved
 }
 
 // extract extracts lineage from a parsed statement.
@@ -100,6 +114,7 @@ func (e *lineageExtractor) extract(stmt *SelectStmt) (*ModelLineage, error) {
 	// Build result
 	result := &ModelLineage{
 		Sources: e.getSortedSources(),
+		Files:   e.getSortedFiles(),
 		Columns: columns,
 	}
 
@@ -200,6 +215,17 @@ func (e *lineageExtractor) expandStar(scope *Scope, tableName string, startIndex
 
 	var lineages []*ColumnLineage
 	for _, ref := range refs {
+		// Columns merged by a join are unqualified and come from every side,
+		// and table function columns come from the function's arguments
+		if entry, ok := scope.Lookup(ref.Table); ref.Table == "" || ok && entry.Function != nil {
+			lineages = append(lineages, &ColumnLineage{
+				Name:      ref.Column,
+				Sources:   e.resolveColumnSources(scope, ref),
+				Transform: TransformDirect,
+			})
+			continue
+		}
+
 		source := SourceColumn{
 			Table:  ref.Table,
 			Column: ref.Column,
@@ -242,11 +268,8 @@ func (e *lineageExtractor) extractExprLineage(scope *Scope, colResolver *ColumnR
 
 	switch ex := expr.(type) {
 	case *ColumnRef:
-		// Direct column reference
-		source := e.resolveColumnRef(scope, ex)
-		if source != nil {
-			lineage.Sources = []SourceColumn{*source}
-		}
+		// Direct column reference (a USING key has a source on each side)
+		lineage.Sources = e.resolveColumnSources(scope, ex)
 		lineage.Transform = TransformDirect
 
 	case *Literal:
@@ -304,6 +327,12 @@ func (e *lineageExtractor) extractExprLineage(scope *Scope, colResolver *ColumnR
 		lineage.Sources = sources
 		lineage.Transform = TransformExpression
 
+	case *IndexExpr, *FieldExpr, *StructExpr, *ListExpr:
+		// Element and field access, and nested literals, reshape their sources
+		sources := e.collectExprSources(scope, colResolver, expr)
+		lineage.Sources = sources
+		lineage.Transform = TransformExpression
+
 	case *ParenExpr:
 		return e.extractExprLineage(scope, colResolver, ex.Expr)
 
@@ -326,12 +355,11 @@ func (e *lineageExtractor) collectExprSources(scope *Scope, colResolver *ColumnR
 	seen := make(map[string]struct{})
 
 	for _, ref := range refs {
-		source := e.resolveColumnRef(scope, ref)
-		if source != nil {
+		for _, source := range e.resolveColumnSources(scope, ref) {
 			key := source.Table + "." + source.Column
 			if _, ok := seen[key]; !ok {
 				seen[key] = struct{}{}
-				sources = append(sources, *source)
+				sources = append(sources, source)
 			}
 		}
 	}
@@ -339,6 +367,60 @@ func (e *lineageExtractor) collectExprSources(scope *Scope, colResolver *ColumnR
 	return sources
 }
 
+// resolveColumnSources resolves a column reference to its sources. An
+// unqualified reference to a column merged by a USING or NATURAL join
+// resolves to the column on every joined side.
+func (e *lineageExtractor) resolveColumnSources(scope *Scope, ref *ColumnRef) []SourceColumn {
+	if ref == nil {
+		return nil
+	}
+	ref = scope.StructColumn(ref)
+
+	if entry, ok := scope.ResolveColumn(ref); ok && entry.Function != nil {
+		return e.functionSources(scope, entry)
+	}
+
+	if ref.Table == "" {
+		if entries := scope.MergedEntries(ref.Column); len(entries) > 0 {
+			sources := make([]SourceColumn, 0, len(entries))
+			for _, entry := range entries {
+				if entry.Function != nil {
+					sources = e.mergeSources(sources, e.functionSources(scope, entry))
+					continue
+				}
+				qualified := &ColumnRef{Table: entry.EffectiveName(), Column: ref.Column}
+				if source := e.resolveColumnRef(scope, qualified); source != nil {
+					sources = append(sources, *source)
+				}
+			}
+			return sources
+		}
+	}
+
+	if source := e.resolveColumnRef(scope, ref); source != nil {
+		return []SourceColumn{*source}
+	}
+	return nil
+}
+
+// functionSources returns the sources of a table function's columns: the
+// columns read by its arguments. Generators such as range(10) read none.
+func (e *lineageExtractor) functionSources(scope *Scope, entry *ScopeEntry) []SourceColumn {
+	if _, ok := e.functions[entry]; ok {
+		// An argument referring to the function's own columns
+		return nil
+	}
+	e.functions[entry] = struct{}{}
+	defer delete(e.functions, entry)
+
+	colResolver := NewColumnResolver(scope, e.dialect)
+	var sources []SourceColumn
+	for _, arg := range entry.Function.Args {
+		sources = e.mergeSources(sources, e.collectExprSources(scope, colResolver, arg))
+	}
+	return sources
+}
+
 // resolveColumnRef resolves a column reference to its source.
 func (e *lineageExtractor) resolveColumnRef(scope *Scope, ref *ColumnRef) *SourceColumn {
 	if ref == nil {
@@ -396,9 +478,10 @@ func (e *lineageExtractor) resolveColumnRef(scope *Scope, ref *ColumnRef) *Sourc
 				}
 			}
 		}
-		e.sources[ref.Table] = struct{}{}
+		table := e.dialect.identKey(ref.Table)
+		e.sources[table] = struct{}{}
 		return &SourceColumn{
-			Table:  ref.Table,
+			Table:  table,
 			Column: ref.Column,
 		}
 	}
@@ -436,16 +519,7 @@ func (e *lineageExtractor) registerTableRef(scope *Scope, ref TableRef) {
 			return
 		}
 
-		// Build fully qualified name
-		var parts []string
-		if t.Catalog != "" {
-			parts = append(parts, t.Catalog)
-		}
-		if t.Schema != "" {
-			parts = append(parts, t.Schema)
-		}
-		parts = append(parts, t.Name)
-		e.sources[strings.Join(parts, ".")] = struct{}{}
+		e.sources[e.dialect.identKey(qualifiedTableName(t))] = struct{}{}
 
 	case *DerivedTable:
 		// Derived tables don't add sources directly
@@ -453,7 +527,28 @@ func (e *lineageExtractor) registerTableRef(scope *Scope, ref TableRef) {
 
 	case *LateralTable:
 		// Same as derived tables
+
+	case *TableFunction:
+		// File readers are external sources named by their path; other
+		// table functions read their arguments, which resolve through scope
+		for _, path := range FilePaths(t, e.dialect) {
+			e.sources[path] = struct{}{}
+			e.files[path] = struct{}{}
+		}
+	}
+}
+
+// qualifiedTableName returns the fully qualified name of a table: catalog.schema.table.
+func qualifiedTableName(t *TableName) string {
+	var parts []string
+	if t.Catalog != "" {
+		parts = append(parts, t.Catalog)
+	}
+	if t.Schema != "" {
+		parts = append(parts, t.Schema)
 	}
+	parts = append(parts, t.Name)
+	return strings.Join(parts, ".")
 }
 
 // collectSources collects sources from scope entries.
@@ -488,6 +583,19 @@ func (e *lineageExtractor) getSortedSources() []string {
 	return sources
 }
 
+// getSortedFiles returns the collected file paths as a sorted slice, or nil.
+func (e *lineageExtractor) getSortedFiles() []string {
+	if len(e.files) == 0 {
+		return nil
+	}
+	files := make([]string, 0, len(e.files))
+	for f := range e.files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // mergeSources merges two source lists, removing duplicates.
 func (e *lineageExtractor) mergeSources(a, b []SourceColumn) []SourceColumn {
 	seen := make(map[string]struct{})
@@ -531,6 +639,9 @@ func (e *lineageExtractor) inferColumnName(expr Expr, index int) string {
 	case *ParenExpr:
 		return e.inferColumnName(ex.Expr, index)
 
+	case *FieldExpr:
+		return ex.Field
+
 	default:
 		return e.generateColumnName(index)
 	}
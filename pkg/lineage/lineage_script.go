@@ -0,0 +1,347 @@
+package lineage
+
+import (
+	"slices"
+	"strings"
+)
+
+// StatementKind identifies what a statement of a script does.
+type StatementKind string
+
+const (
+	StatementSelect      StatementKind = "SELECT"
+	StatementCreateTable StatementKind = "CREATE TABLE"
+	StatementCreateView  StatementKind = "CREATE VIEW"
+	StatementInsert      StatementKind = "INSERT"
+	StatementUpdate      StatementKind = "UPDATE"
+	StatementDelete      StatementKind = "DELETE"
+	StatementMerge       StatementKind = "MERGE"
+	StatementOther       StatementKind = "OTHER" // statements without lineage, e.g. SET
+)
+
+// StatementLineage describes the lineage of one statement of a script.
+type StatementLineage struct {
+	Kind    StatementKind
+	Target  string           // Relation written (catalog.schema.table); empty for SELECT
+	Sources []string         // Relations read, not counting the target (deduplicated, sorted)
+	Files   []string         // File paths read by table functions (also in Sources)
+	Columns []*ColumnLineage // Columns written, or selected for SELECT
+}
+
+// ExtractScriptLineage extracts lineage from each statement of a script.
+// Relations created earlier in the script are added to the schema, so later
+// statements can expand SELECT * from them.
+func ExtractScriptLineage(sql string, opts ExtractLineageOptions) ([]*StatementLineage, error) {
+	dialect := opts.Dialect
+	if dialect == nil {
+		dialect = DefaultDialect()
+	}
+
+	p := newLineageParser(sql, dialect)
+	stmts := p.parseScript()
+	if len(p.errors) > 0 {
+		return nil, p.errors
+	}
+
+	schema := make(Schema, len(opts.Schema))
+	for table, columns := range opts.Schema {
+		schema[table] = columns
+	}
+
+	result := make([]*StatementLineage, 0, len(stmts))
+	for _, stmt := range stmts {
+		e := newLineageExtractor(dialect, schema)
+		sl, err := e.extractStatement(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if sl.Kind == StatementCreateTable || sl.Kind == StatementCreateView {
+			if columns := knownColumnNames(sl.Columns); columns != nil {
+				schema[sl.Target] = columns
+			}
+		}
+		result = append(result, sl)
+	}
+	return result, nil
+}
+
+// extractStatement extracts lineage from a statement of a script. DML is
+// traced as the query it amounts to: UPDATE t SET a = s.b FROM s reads like
+// SELECT s.b AS a FROM t, s.
+func (e *lineageExtractor) extractStatement(stmt Statement) (*StatementLineage, error) {
+	switch s := stmt.(type) {
+	case *SelectStmt:
+		return e.queryLineage(StatementSelect, nil, s, nil)
+
+	case *CreateStmt:
+		kind := StatementCreateTable
+		if s.View {
+			kind = StatementCreateView
+		}
+		if s.Select == nil {
+			return &StatementLineage{Kind: kind, Target: e.dialect.identKey(qualifiedTableName(s.Target))}, nil
+		}
+		return e.queryLineage(kind, s.Target, s.Select, s.Columns)
+
+	case *InsertStmt:
+		query := s.Select
+		if len(s.Values) > 0 {
+			query = valuesQuery(s.Values)
+		}
+		if query == nil {
+			// INSERT ... DEFAULT VALUES
+			return &StatementLineage{Kind: StatementInsert, Target: e.dialect.identKey(qualifiedTableName(s.Target))}, nil
+		}
+		columns := s.Columns
+		if s.ByName {
+			// Columns match by name, which the query already carries
+			columns = nil
+		}
+		return e.queryLineage(StatementInsert, s.Target, withQuery(s.With, query), columns)
+
+	case *UpdateStmt:
+		from := &FromClause{Source: s.Target}
+		if s.From != nil {
+			from.Joins = append(from.Joins, &Join{Type: JoinComma, Right: s.From.Source})
+			from.Joins = append(from.Joins, s.From.Joins...)
+		}
+		if _, err := e.subqueryLineage(s.With, s.Where); err != nil {
+			return nil, err
+		}
+		core := &SelectCore{From: from}
+		values := make([][]SourceColumn, len(s.Set))
+		for i, a := range s.Set {
+			core.Columns = append(core.Columns, SelectItem{Expr: a.Value, Alias: a.Column})
+			sources, err := e.subqueryLineage(s.With, a.Value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = sources
+		}
+		sl, err := e.queryLineage(StatementUpdate, s.Target, &SelectStmt{With: s.With, Body: &SelectBody{Left: core}}, nil)
+		if err != nil {
+			return nil, err
+		}
+		// SET a = (SELECT max(b) FROM w) gives a the sources of the subquery's column
+		for i, col := range sl.Columns {
+			if len(values[i]) > 0 {
+				col.Sources = e.mergeSources(col.Sources, values[i])
+				col.Transform = TransformExpression
+			}
+		}
+		return sl, nil
+
+	case *DeleteStmt:
+		from := &FromClause{Source: s.Target}
+		if s.Using != nil {
+			from.Joins = append(from.Joins, &Join{Type: JoinComma, Right: s.Using.Source})
+			from.Joins = append(from.Joins, s.Using.Joins...)
+		}
+		core := &SelectCore{From: from}
+		if _, err := e.subqueryLineage(s.With, s.Where); err != nil {
+			return nil, err
+		}
+		return e.queryLineage(StatementDelete, s.Target, &SelectStmt{With: s.With, Body: &SelectBody{Left: core}}, nil)
+
+	case *MergeStmt:
+		return e.mergeLineage(s)
+
+	default:
+		return &StatementLineage{Kind: StatementOther}, nil
+	}
+}
+
+// queryLineage extracts lineage from the query a statement runs. Target
+// column names, when given for every column, rename the query's columns by
+// position.
+func (e *lineageExtractor) queryLineage(kind StatementKind, target *TableName, query *SelectStmt, columns []string) (*StatementLineage, error) {
+	ml, err := e.extract(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sl := &StatementLineage{Kind: kind, Files: ml.Files, Columns: ml.Columns}
+	if target != nil {
+		sl.Target = e.dialect.identKey(qualifiedTableName(target))
+	}
+	for _, src := range ml.Sources {
+		// Reading the target (UPDATE t SET a = a + 1) is not a dependency
+		if target != nil && e.dialect.NormalizeName(src) == e.dialect.NormalizeName(sl.Target) {
+			continue
+		}
+		sl.Sources = append(sl.Sources, src)
+	}
+
+	if len(columns) > 0 && len(columns) == len(sl.Columns) {
+		for i, name := range columns {
+			sl.Columns[i].Name = name
+		}
+	}
+	return sl, nil
+}
+
+// mergeLineage extracts lineage from a MERGE. Columns written by several
+// clauses combine their sources.
+func (e *lineageExtractor) mergeLineage(s *MergeStmt) (*StatementLineage, error) {
+	core := &SelectCore{From: &FromClause{
+		Source: s.Target,
+		Joins:  []*Join{{Type: JoinInner, Right: s.Source, Condition: s.On}},
+	}}
+
+	for _, clause := range s.Clauses {
+		switch {
+		case clause.Star:
+			if name := tableRefName(s.Source); name != "" {
+				core.Columns = append(core.Columns, SelectItem{TableStar: name})
+			}
+		case clause.Action == MergeUpdate:
+			for _, a := range clause.Set {
+				core.Columns = append(core.Columns, SelectItem{Expr: a.Value, Alias: a.Column})
+			}
+		case clause.Action == MergeInsert:
+			for i, value := range clause.Values {
+				item := SelectItem{Expr: value}
+				if i < len(clause.Columns) {
+					item.Alias = clause.Columns[i]
+				}
+				core.Columns = append(core.Columns, item)
+			}
+		}
+	}
+
+	sl, err := e.queryLineage(StatementMerge, s.Target, &SelectStmt{Body: &SelectBody{Left: core}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*ColumnLineage
+	byName := make(map[string]*ColumnLineage)
+	for _, col := range sl.Columns {
+		key := e.dialect.NormalizeName(col.Name)
+		existing, ok := byName[key]
+		if !ok {
+			byName[key] = col
+			columns = append(columns, col)
+			continue
+		}
+		existing.Sources = e.mergeSources(existing.Sources, col.Sources)
+		if existing.Transform != col.Transform {
+			existing.Transform = TransformExpression
+		}
+	}
+	sl.Columns = columns
+	return sl, nil
+}
+
+// subqueryLineage extracts the lineage of the subqueries of an expression,
+// such as the WHERE of a DELETE, adding the relations they read to the
+// sources. It returns the sources of the columns scalar subqueries give the
+// expression. The subqueries see the CTEs of the statement's WITH clause.
+func (e *lineageExtractor) subqueryLineage(with *WithClause, expr Expr) ([]SourceColumn, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	var values []SourceColumn
+	var err error
+	Inspect(expr, func(n Node) bool {
+		if err != nil {
+			return false
+		}
+		var query *SelectStmt
+		scalar := false
+		switch x := n.(type) {
+		case *SubqueryExpr:
+			query, scalar = x.Select, true
+		case *SelectStmt:
+			// The query of IN or EXISTS
+			query = x
+		default:
+			return true
+		}
+
+		var ml *ModelLineage
+		ml, err = newLineageExtractor(e.dialect, e.schema).extract(withQuery(with, query))
+		if err != nil {
+			return false
+		}
+		for _, src := range ml.Sources {
+			e.sources[src] = struct{}{}
+		}
+		for _, path := range ml.Files {
+			e.files[path] = struct{}{}
+		}
+		if scalar && len(ml.Columns) > 0 {
+			values = e.mergeSources(values, ml.Columns[0].Sources)
+		}
+		return false
+	})
+	return values, err
+}
+
+// withQuery returns a query that also sees the CTEs of a statement's WITH
+// clause, ahead of the query's own.
+func withQuery(with *WithClause, query *SelectStmt) *SelectStmt {
+	if with == nil {
+		return query
+	}
+	combined := &WithClause{Span: with.Span, Recursive: with.Recursive, CTEs: with.CTEs}
+	if query.With != nil {
+		combined.Recursive = combined.Recursive || query.With.Recursive
+		combined.CTEs = append(slices.Clone(with.CTEs), query.With.CTEs...)
+	}
+	return &SelectStmt{Span: query.Span, With: combined, Body: query.Body}
+}
+
+// valuesQuery builds the query VALUES rows amount to: one SELECT per row,
+// combined with UNION ALL.
+func valuesQuery(rows [][]Expr) *SelectStmt {
+	var body *SelectBody
+	for i := len(rows) - 1; i >= 0; i-- {
+		core := &SelectCore{}
+		for _, value := range rows[i] {
+			core.Columns = append(core.Columns, SelectItem{Expr: value})
+		}
+		next := &SelectBody{Left: core}
+		if body != nil {
+			next.Op = SetOpUnionAll
+			next.All = true
+			next.Right = body
+		}
+		body = next
+	}
+	return &SelectStmt{Body: body}
+}
+
+// tableRefName returns the name a table reference is referenced by, or ""
+// if it has none.
+func tableRefName(ref TableRef) string {
+	switch t := ref.(type) {
+	case *TableName:
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return t.Name
+	case *DerivedTable:
+		return t.Alias
+	case *LateralTable:
+		return t.Alias
+	}
+	return ""
+}
+
+// knownColumnNames returns the names of columns, or nil if any is an
+// unexpanded star.
+func knownColumnNames(columns []*ColumnLineage) []string {
+	if len(columns) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.Name == "*" || strings.HasSuffix(col.Name, ".*") {
+			return nil
+		}
+		names = append(names, col.Name)
+	}
+	return names
+}
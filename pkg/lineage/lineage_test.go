@@ -1,6 +1,10 @@
 package lineage
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -43,6 +47,7 @@ type testCase struct {
 	name    string
 	sql     string
 	schema  Schema
+	dialect *Dialect  // nil = DuckDB
 	sources []string  // expected source tables
 	cols    []colSpec // expected columns
 }
@@ -52,7 +57,7 @@ func runLineageTests(t *testing.T, tests []testCase) {
 	t.Helper()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			lineage, err := ExtractLineage(tt.sql, tt.schema)
+			lineage, err := ExtractLineageWithOptions(tt.sql, ExtractLineageOptions{Dialect: tt.dialect, Schema: tt.schema})
 			if err != nil {
 				t.Fatalf("ExtractLineage failed: %v", err)
 			}
@@ -395,6 +400,122 @@ func TestExtractLineage_Joins(t *testing.T) {
 	})
 }
 
+func TestExtractLineage_UsingJoins(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name:    "USING key without schema",
+			sql:     `SELECT id, o.amount FROM customers c JOIN orders o USING (id)`,
+			sources: []string{"customers", "orders"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcCount: srcN(2), srcTable: "customers"},
+				{name: "amount", transform: TransformDirect, srcCount: srcN(1), srcTable: "orders"},
+			},
+		},
+		{
+			name: "USING key in expression",
+			sql:  `SELECT customer_id + 1 AS next_id FROM customers LEFT JOIN orders USING (customer_id, region)`,
+			schema: Schema{
+				"customers": {"customer_id", "region", "name"},
+				"orders":    {"order_id", "customer_id", "region", "amount"},
+			},
+			sources: []string{"customers", "orders"},
+			cols: []colSpec{
+				{name: "next_id", transform: TransformExpression, srcCount: srcN(2)},
+			},
+		},
+		{
+			name: "USING across three tables",
+			sql:  `SELECT id FROM a JOIN b USING (id) JOIN c USING (id)`,
+			schema: Schema{
+				"a": {"id"},
+				"b": {"id"},
+				"c": {"id"},
+			},
+			sources: []string{"a", "b", "c"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcCount: srcN(3)},
+			},
+		},
+		{
+			name: "star over USING does not duplicate the key",
+			sql:  `SELECT * FROM customers c FULL JOIN orders o USING (customer_id)`,
+			schema: Schema{
+				"customers": {"customer_id", "name"},
+				"orders":    {"order_id", "customer_id", "amount"},
+			},
+			sources: []string{"customers", "orders"},
+			cols: []colSpec{
+				{name: "customer_id", transform: TransformDirect, srcCount: srcN(2)},
+				{name: "name", transform: TransformDirect},
+				{name: "order_id", transform: TransformDirect},
+				{name: "amount", transform: TransformDirect},
+			},
+		},
+		{
+			name: "NATURAL join merges shared columns",
+			sql:  `SELECT * FROM customers NATURAL LEFT JOIN orders`,
+			schema: Schema{
+				"customers": {"customer_id", "name"},
+				"orders":    {"order_id", "customer_id", "amount"},
+			},
+			sources: []string{"customers", "orders"},
+			cols: []colSpec{
+				{name: "customer_id", transform: TransformDirect, srcCount: srcN(2)},
+				{name: "name", transform: TransformDirect},
+				{name: "order_id", transform: TransformDirect},
+				{name: "amount", transform: TransformDirect},
+			},
+		},
+	})
+}
+
+func TestExpandStar_UsingOrder(t *testing.T) {
+	sql := `SELECT * FROM customers c JOIN orders o USING (customer_id)`
+	schema := Schema{
+		"customers": {"name", "customer_id"},
+		"orders":    {"order_id", "customer_id"},
+	}
+	lineage, err := ExtractLineage(sql, schema)
+	if err != nil {
+		t.Fatalf("ExtractLineage failed: %v", err)
+	}
+
+	var names []string
+	for _, col := range lineage.Columns {
+		names = append(names, col.Name)
+	}
+	want := []string{"customer_id", "name", "order_id"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("columns = %v, want %v", names, want)
+	}
+
+	key := lineage.Columns[0]
+	if len(key.Sources) != 2 || key.Sources[0].Table != "customers" || key.Sources[1].Table != "orders" {
+		t.Errorf("customer_id sources = %+v, want customers and orders", key.Sources)
+	}
+}
+
+func TestParse_JoinUsing(t *testing.T) {
+	stmt, err := Parse(`SELECT * FROM a JOIN b USING (id, day) NATURAL JOIN c`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	joins := stmt.Body.Left.From.Joins
+	if len(joins) != 2 {
+		t.Fatalf("expected 2 joins, got %d", len(joins))
+	}
+	if got := strings.Join(joins[0].Using, ","); got != "id,day" || joins[0].Natural {
+		t.Errorf("first join: Using = %q, Natural = %v", got, joins[0].Natural)
+	}
+	if !joins[1].Natural || joins[1].Type != JoinInner {
+		t.Errorf("second join: Natural = %v, Type = %v", joins[1].Natural, joins[1].Type)
+	}
+
+	if _, err := Parse(`SELECT * FROM a NATURAL b`); err == nil {
+		t.Error("expected error for NATURAL without JOIN")
+	}
+}
+
 func TestExtractLineage_SetOperations(t *testing.T) {
 	runLineageTests(t, []testCase{
 		{
@@ -1158,7 +1279,17 @@ func TestExtractLineage_ExpressionEdgeCases(t *testing.T) {
 				{name: "is_valid", transform: TransformExpression},
 			},
 		},
-		// NOTE: type cast shorthand (::), array subscript ([n]), JSON access (->>) are not supported by the parser
+		{
+			name:    "type cast shorthand",
+			sql:     `SELECT id, amount::DECIMAL(10, 2) AS amount, '2024-01-01'::DATE AS start_date FROM orders`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect},
+				{name: "amount", transform: TransformExpression, srcCount: srcN(1)},
+				{name: "start_date", transform: TransformExpression, srcCount: srcN(0)},
+			},
+		},
+		// NOTE: array subscript ([n]), JSON access (->>) are not supported by the parser
 	})
 }
 
@@ -1351,6 +1482,764 @@ func TestExtractLineage_RealWorldPatterns(t *testing.T) {
 // Error Cases
 // =============================================================================
 
+func TestExtractLineage_TableFunctions(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name:    "read_parquet with column aliases",
+			sql:     `SELECT o.id, o.amount * 2 AS doubled FROM read_parquet('s3://bucket/orders/*.parquet') AS o(id, amount)`,
+			sources: []string{"s3://bucket/orders/*.parquet"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcTable: "s3://bucket/orders/*.parquet"},
+				{name: "doubled", transform: TransformExpression, srcTable: "s3://bucket/orders/*.parquet"},
+			},
+		},
+		{
+			name:    "read_csv_auto star with schema by path",
+			sql:     `SELECT * FROM read_csv_auto('data/customers.csv')`,
+			schema:  Schema{"data/customers.csv": {"id", "name"}},
+			sources: []string{"data/customers.csv"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect},
+				{name: "name", transform: TransformDirect},
+			},
+		},
+		{
+			name:    "read_parquet list of files",
+			sql:     `SELECT f.id FROM read_parquet(['a.parquet', 'b.parquet']) f`,
+			sources: []string{"a.parquet", "b.parquet"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect},
+			},
+		},
+		{
+			name: "range and generate_series",
+			sql: `SELECT r.range AS n, d.day
+				FROM range(10) r, generate_series(1, 31) AS d(day)`,
+			cols: []colSpec{
+				{name: "n", transform: TransformDirect, srcCount: srcN(0)},
+				{name: "day", transform: TransformDirect, srcCount: srcN(0)},
+			},
+		},
+		{
+			name: "range with column alias",
+			sql:  `SELECT x FROM range(10) AS r(x)`,
+			cols: []colSpec{
+				{name: "x", transform: TransformDirect, srcCount: srcN(0)},
+			},
+		},
+		{
+			name: "range star",
+			sql:  `SELECT * FROM range(10)`,
+			cols: []colSpec{
+				{name: "range", transform: TransformDirect, srcCount: srcN(0)},
+			},
+		},
+		{
+			name:    "unnest joined to a table",
+			sql:     `SELECT o.id, u.tag FROM orders o, UNNEST(o.tags) AS u(tag)`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcTable: "orders"},
+				{name: "tag", transform: TransformDirect, srcCount: srcN(1), srcTable: "orders"},
+			},
+		},
+		{
+			name:    "unnest of a column in the from list",
+			sql:     `SELECT a.id, u.v FROM a, UNNEST(a.x) AS u(v)`,
+			sources: []string{"a"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcTable: "a"},
+				{name: "v", transform: TransformDirect, srcCount: srcN(1), srcTable: "a"},
+			},
+		},
+		{
+			name:    "unnest star",
+			sql:     `SELECT u.* FROM a CROSS JOIN UNNEST(a.xs) AS u(x)`,
+			sources: []string{"a"},
+			cols: []colSpec{
+				{name: "x", transform: TransformDirect, srcCount: srcN(1), srcTable: "a"},
+			},
+		},
+		{
+			name: "unnest default column",
+			sql:  `SELECT unnest FROM UNNEST([1, 2, 3])`,
+			cols: []colSpec{
+				{name: "unnest", transform: TransformDirect, srcCount: srcN(0)},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_TableFunctionArguments(t *testing.T) {
+	tests := []struct {
+		sql     string
+		sources []string
+		want    map[string][]SourceColumn
+	}{
+		{
+			sql:     `SELECT a.id, u.v FROM a, UNNEST(a.x) AS u(v)`,
+			sources: []string{"a"},
+			want: map[string][]SourceColumn{
+				"id": {{Table: "a", Column: "id"}},
+				"v":  {{Table: "a", Column: "x"}},
+			},
+		},
+		{
+			sql:     `SELECT x FROM range(10) AS r(x)`,
+			sources: []string{},
+			want:    map[string][]SourceColumn{"x": nil},
+		},
+		{
+			sql:     `SELECT * FROM range(10)`,
+			sources: []string{},
+			want:    map[string][]SourceColumn{"range": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			lineage, err := ExtractLineage(tt.sql, nil)
+			if err != nil {
+				t.Fatalf("ExtractLineage failed: %v", err)
+			}
+			if !reflect.DeepEqual(lineage.Sources, tt.sources) {
+				t.Errorf("sources = %v, want %v", lineage.Sources, tt.sources)
+			}
+			if len(lineage.Columns) != len(tt.want) {
+				t.Fatalf("got %d columns, want %d", len(lineage.Columns), len(tt.want))
+			}
+			for _, col := range lineage.Columns {
+				want, ok := tt.want[col.Name]
+				if !ok {
+					t.Errorf("unexpected column %q", col.Name)
+					continue
+				}
+				if !reflect.DeepEqual(col.Sources, want) {
+					t.Errorf("column %q sources = %v, want %v", col.Name, col.Sources, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractLineage_NestedTypes(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name:    "struct field access",
+			sql:     `SELECT c.address.city, address.zip AS zip FROM customers c`,
+			schema:  Schema{"customers": {"id", "address"}},
+			sources: []string{"customers"},
+			cols: []colSpec{
+				{name: "city", transform: TransformDirect, srcTable: "customers"},
+				{name: "zip", transform: TransformDirect, srcTable: "customers"},
+			},
+		},
+		{
+			name:    "list index and slice",
+			sql:     `SELECT tags[1] AS first_tag, o.tags[2:3] AS some_tags, (items[1]).sku FROM orders o`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "first_tag", transform: TransformExpression, srcCount: srcN(1)},
+				{name: "some_tags", transform: TransformExpression, srcCount: srcN(1), srcTable: "orders"},
+				{name: "sku", transform: TransformExpression, srcCount: srcN(1)},
+			},
+		},
+		{
+			name:    "struct literal",
+			sql:     `SELECT {'id': o.id, total: o.amount * 2} AS info FROM orders o`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "info", transform: TransformExpression, srcCount: srcN(2), srcTable: "orders"},
+			},
+		},
+		{
+			name:    "lambda parameters are not columns",
+			sql:     `SELECT list_transform(o.prices, x -> x * o.rate) AS converted, list_reduce(o.prices, (a, b) -> a + b) AS total FROM orders o`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "converted", transform: TransformExpression, srcCount: srcN(2), srcTable: "orders"},
+				{name: "total", transform: TransformDirect, srcCount: srcN(1), srcTable: "orders"},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_Pivot(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name: "pivot in FROM",
+			sql: `SELECT p.region, p.q1, p.q2
+				FROM sales PIVOT (SUM(amount) FOR quarter IN ('q1', 'q2')) AS p`,
+			schema:  Schema{"sales": {"region", "quarter", "amount"}},
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "region", transform: TransformDirect, srcTable: "sales"},
+				{name: "q1", transform: TransformDirect, srcTable: "sales"},
+				{name: "q2", transform: TransformDirect, srcTable: "sales"},
+			},
+		},
+		{
+			name:    "pivot statement expands star",
+			sql:     `PIVOT sales ON quarter IN ('q1', 'q2') USING SUM(amount) AS total, COUNT(*) AS n GROUP BY region`,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "region", transform: TransformDirect},
+				{name: "q1_total", transform: TransformDirect},
+				{name: "q1_n", transform: TransformDirect},
+				{name: "q2_total", transform: TransformDirect},
+				{name: "q2_n", transform: TransformDirect},
+			},
+		},
+		{
+			name:    "pivot statement with values known at run time",
+			sql:     `PIVOT sales ON quarter USING SUM(amount)`,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "*", transform: TransformDirect},
+			},
+		},
+		{
+			name: "pivot statement in a CTE",
+			sql: `WITH p AS (PIVOT sales ON quarter USING SUM(amount) GROUP BY region)
+				SELECT p.region FROM p JOIN regions r ON p.region = r.name`,
+			sources: []string{"sales", "regions"},
+			cols: []colSpec{
+				{name: "region", transform: TransformDirect, srcTable: "sales"},
+			},
+		},
+		{
+			name:    "unpivot in FROM",
+			sql:     `SELECT u.region, u.quarter, u.amount FROM sales_wide UNPIVOT (amount FOR quarter IN (q1, q2)) u`,
+			schema:  Schema{"sales_wide": {"region", "q1", "q2"}},
+			sources: []string{"sales_wide"},
+			cols: []colSpec{
+				{name: "region", transform: TransformDirect, srcTable: "sales_wide"},
+				{name: "quarter", transform: TransformDirect, srcTable: "sales_wide"},
+				{name: "amount", transform: TransformDirect, srcTable: "sales_wide"},
+			},
+		},
+		{
+			name:    "unpivot statement",
+			sql:     `UNPIVOT sales_wide ON q1, q2 INTO NAME quarter VALUE amount`,
+			schema:  Schema{"sales_wide": {"region", "q1", "q2"}},
+			sources: []string{"sales_wide"},
+			cols: []colSpec{
+				{name: "region", transform: TransformDirect},
+				{name: "quarter", transform: TransformDirect},
+				{name: "amount", transform: TransformDirect},
+			},
+		},
+	})
+}
+
+func TestParse_NestedTypes(t *testing.T) {
+	stmt, err := Parse(`SELECT s.a.b, xs[1:], {'k': 1}, list_filter(xs, (x) -> x > 1) FROM t`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cols := stmt.Body.Left.Columns
+
+	ref, ok := cols[0].Expr.(*ColumnRef)
+	if !ok || strings.Join(ref.Path, ".") != "s.a.b" {
+		t.Errorf("expected column ref with path s.a.b, got %#v", cols[0].Expr)
+	}
+	if idx, ok := cols[1].Expr.(*IndexExpr); !ok || !idx.Slice || idx.End != nil {
+		t.Errorf("expected open slice, got %#v", cols[1].Expr)
+	}
+	if st, ok := cols[2].Expr.(*StructExpr); !ok || len(st.Fields) != 1 || st.Fields[0].Name != "k" {
+		t.Errorf("expected struct literal, got %#v", cols[2].Expr)
+	}
+	fn, ok := cols[3].Expr.(*FuncCall)
+	if !ok || len(fn.Args) != 2 {
+		t.Fatalf("expected function call with 2 args, got %#v", cols[3].Expr)
+	}
+	if lambda, ok := fn.Args[1].(*LambdaExpr); !ok || len(lambda.Params) != 1 || lambda.Params[0] != "x" {
+		t.Errorf("expected lambda with parameter x, got %#v", fn.Args[1])
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	stmts, err := ParseScript(`
+		SET threads = 4;
+		CREATE OR REPLACE TABLE analytics.daily (day, total) AS SELECT d, sum(x) FROM raw GROUP BY d;
+		INSERT INTO audit VALUES (1, 'a'), (2, 'b');
+		UPDATE t SET a = 1 WHERE id = 2;
+		DELETE FROM t USING s WHERE t.id = s.id;
+		MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET a = s.a WHEN NOT MATCHED THEN INSERT (id, a) VALUES (s.id, s.a);
+		SELECT replace(name, 'a', 'b') AS values FROM t;`)
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	if len(stmts) != 7 {
+		t.Fatalf("expected 7 statements, got %d", len(stmts))
+	}
+
+	if other, ok := stmts[0].(*UnsupportedStmt); !ok || other.Keyword != "SET" {
+		t.Errorf("expected unsupported SET statement, got %#v", stmts[0])
+	}
+	create, ok := stmts[1].(*CreateStmt)
+	if !ok || !create.OrReplace || create.Target.Schema != "analytics" || create.Select == nil || len(create.Columns) != 2 {
+		t.Errorf("unexpected CREATE statement: %#v", stmts[1])
+	}
+	if insert, ok := stmts[2].(*InsertStmt); !ok || len(insert.Values) != 2 {
+		t.Errorf("expected INSERT with 2 rows, got %#v", stmts[2])
+	}
+	if update, ok := stmts[3].(*UpdateStmt); !ok || len(update.Set) != 1 || update.Where == nil {
+		t.Errorf("unexpected UPDATE statement: %#v", stmts[3])
+	}
+	if del, ok := stmts[4].(*DeleteStmt); !ok || del.Using == nil {
+		t.Errorf("unexpected DELETE statement: %#v", stmts[4])
+	}
+	merge, ok := stmts[5].(*MergeStmt)
+	if !ok || len(merge.Clauses) != 2 || merge.Clauses[1].Matched || merge.Clauses[1].Action != MergeInsert {
+		t.Errorf("unexpected MERGE statement: %#v", stmts[5])
+	}
+	if _, ok := stmts[6].(*SelectStmt); !ok {
+		t.Errorf("expected SELECT, got %#v", stmts[6])
+	}
+}
+
+func TestParseScript_Errors(t *testing.T) {
+	for _, sql := range []string{
+		`CREATE INDEX idx ON t (a)`,
+		`SELECT a FROM t SELECT b FROM s`,
+		`MERGE INTO t USING s ON t.id = s.id`,
+		`UPDATE t WHERE a = 1`,
+	} {
+		if _, err := ParseScript(sql); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestParse_Spans(t *testing.T) {
+	sql := "SELECT o.id,\n  upper(c.name) AS name\nFROM orders o JOIN customers c ON o.customer_id = c.id"
+	stmt, err := Parse(sql)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	text := func(n Node) string {
+		span := n.NodeSpan()
+		return sql[span.Start.Offset:span.End.Offset]
+	}
+	core := stmt.Body.Left
+
+	tests := []struct {
+		node Node
+		want string
+	}{
+		{stmt, sql},
+		{core.Columns[0].Expr, "o.id"},
+		{core.Columns[1].Expr, "upper(c.name)"},
+		{core.Columns[1].Expr.(*FuncCall).Args[0], "c.name"},
+		{core.From.Source, "orders o"},
+		{core.From.Joins[0], "JOIN customers c ON o.customer_id = c.id"},
+		{core.From.Joins[0].Condition, "o.customer_id = c.id"},
+	}
+	for _, tt := range tests {
+		if got := text(tt.node); got != tt.want {
+			t.Errorf("%T spans %q, want %q", tt.node, got, tt.want)
+		}
+	}
+
+	if got := sql[core.Columns[1].Start.Offset:core.Columns[1].End.Offset]; got != "upper(c.name) AS name" {
+		t.Errorf("select item spans %q", got)
+	}
+	span := core.Columns[1].Expr.NodeSpan()
+	if span.Start.Line != 2 || span.Start.Column != 3 || span.End.Line != 2 || span.End.Column != 16 {
+		t.Errorf("unexpected span %+v", span)
+	}
+}
+
+func TestParse_ErrorRecovery(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns []int // column of each error, all on line 1
+	}{
+		{"SELECT a +, b + , c FROM t WHERE = 1", []int{11, 17, 34}},
+		{"SELECT a, FROM t GROUP BY , 2", []int{11, 27}},
+		{"SELECT (a + FROM t", []int{13}},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.sql)
+		var errs ParseErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("%q: expected ParseErrors, got %v", tt.sql, err)
+		}
+		var columns []int
+		for _, e := range errs {
+			columns = append(columns, e.Pos.Column)
+		}
+		if fmt.Sprint(columns) != fmt.Sprint(tt.columns) {
+			t.Errorf("%q: errors at columns %v, want %v (%v)", tt.sql, columns, tt.columns, err)
+		}
+	}
+
+	// Each statement of a script reports its own errors
+	_, err := ParseScript("SELECT 1 +;\nINSERT INTO t VALUES (1,);\nUPDATE t SET = 1;\nSELECT ok FROM t")
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", err)
+	}
+	for i, e := range errs {
+		if e.Pos.Line != i+1 {
+			t.Errorf("error %d on line %d, want %d", i, e.Pos.Line, i+1)
+		}
+	}
+	var first *ParseError
+	if !errors.As(err, &first) || first != errs[0] {
+		t.Errorf("expected errors.As to find the first ParseError")
+	}
+}
+
+func TestExtractScriptLineage(t *testing.T) {
+	results, err := ExtractScriptLineage(`
+		CREATE TABLE stage AS SELECT o.id, o.amount * 2 AS doubled FROM orders o;
+		INSERT INTO report (order_id, value) SELECT * FROM stage;
+		UPDATE report r SET value = r.value + f.fee FROM fees f WHERE r.order_id = f.order_id;
+		DELETE FROM report USING blocked b WHERE report.order_id = b.id;
+		MERGE INTO dim_customer d USING customers c ON d.id = c.id
+			WHEN MATCHED THEN UPDATE SET name = c.name
+			WHEN NOT MATCHED THEN INSERT (id, name) VALUES (c.id, c.name || ' (new)');
+		INSTALL httpfs`, ExtractLineageOptions{})
+	if err != nil {
+		t.Fatalf("ExtractScriptLineage failed: %v", err)
+	}
+
+	want := []struct {
+		kind    StatementKind
+		target  string
+		sources []string
+		columns []string
+	}{
+		{StatementCreateTable, "stage", []string{"orders"}, []string{"id", "doubled"}},
+		{StatementInsert, "report", []string{"stage"}, []string{"order_id", "value"}},
+		{StatementUpdate, "report", []string{"fees"}, []string{"value"}},
+		{StatementDelete, "report", []string{"blocked"}, nil},
+		{StatementMerge, "dim_customer", []string{"customers"}, []string{"name", "id"}},
+		{StatementOther, "", nil, nil},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d statements, got %d", len(want), len(results))
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Kind != w.kind || got.Target != w.target {
+			t.Errorf("statement %d: got %s %q, want %s %q", i, got.Kind, got.Target, w.kind, w.target)
+		}
+		if strings.Join(got.Sources, ",") != strings.Join(w.sources, ",") {
+			t.Errorf("statement %d: sources = %v, want %v", i, got.Sources, w.sources)
+		}
+		var names []string
+		for _, col := range got.Columns {
+			names = append(names, col.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(w.columns, ",") {
+			t.Errorf("statement %d: columns = %v, want %v", i, names, w.columns)
+		}
+	}
+
+	// INSERT ... SELECT * expands the table created earlier in the script
+	value := findColumn(results[1].Columns, "value")
+	if value == nil || len(value.Sources) != 1 || value.Sources[0].Table != "stage" || value.Sources[0].Column != "doubled" {
+		t.Errorf("expected report.value from stage.doubled, got %+v", value)
+	}
+
+	// MERGE columns written by several clauses combine their sources
+	name := findColumn(results[4].Columns, "name")
+	if name == nil || len(name.Sources) != 1 || name.Transform != TransformExpression {
+		t.Errorf("expected name from customers.name through an expression, got %+v", name)
+	}
+}
+
+func TestExtractScriptLineage_Subqueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		kind    StatementKind
+		sources []string
+		value   []SourceColumn // sources of the first column written, if checked
+	}{
+		{
+			name:    "delete where subquery",
+			sql:     `DELETE FROM x WHERE id IN (SELECT id FROM y)`,
+			kind:    StatementDelete,
+			sources: []string{"y"},
+		},
+		{
+			name:    "update set and where subqueries",
+			sql:     `UPDATE x SET total = (SELECT max(z.amount) FROM z) WHERE EXISTS (SELECT 1 FROM y WHERE y.id = x.id)`,
+			kind:    StatementUpdate,
+			sources: []string{"y", "z"},
+			value:   []SourceColumn{{Table: "z", Column: "amount"}},
+		},
+		{
+			name:    "update set scalar subquery",
+			sql:     `UPDATE x SET a = (SELECT max(w.b) FROM w)`,
+			kind:    StatementUpdate,
+			sources: []string{"w"},
+			value:   []SourceColumn{{Table: "w", Column: "b"}},
+		},
+		{
+			name:    "with before insert",
+			sql:     `WITH s AS (SELECT id, amount FROM y) INSERT INTO x SELECT * FROM s`,
+			kind:    StatementInsert,
+			sources: []string{"y"},
+		},
+		{
+			name:    "with before insert with its own with",
+			sql:     `WITH s AS (SELECT id FROM y) INSERT INTO x WITH u AS (SELECT id FROM s) SELECT id FROM u`,
+			kind:    StatementInsert,
+			sources: []string{"y"},
+		},
+		{
+			name:    "with before update",
+			sql:     `WITH s AS (SELECT id, amount FROM y) UPDATE x SET amount = s.amount FROM s WHERE x.id = s.id`,
+			kind:    StatementUpdate,
+			sources: []string{"y"},
+		},
+		{
+			name:    "with before delete",
+			sql:     `WITH s AS (SELECT id FROM y WHERE stale) DELETE FROM x WHERE id IN (SELECT id FROM s)`,
+			kind:    StatementDelete,
+			sources: []string{"y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ExtractScriptLineage(tt.sql, ExtractLineageOptions{})
+			if err != nil {
+				t.Fatalf("ExtractScriptLineage failed: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 statement, got %d", len(results))
+			}
+			got := results[0]
+			if got.Kind != tt.kind || got.Target != "x" {
+				t.Errorf("got %s %q, want %s %q", got.Kind, got.Target, tt.kind, "x")
+			}
+			if strings.Join(got.Sources, ",") != strings.Join(tt.sources, ",") {
+				t.Errorf("sources = %v, want %v", got.Sources, tt.sources)
+			}
+			if tt.value != nil && (len(got.Columns) == 0 || !reflect.DeepEqual(got.Columns[0].Sources, tt.value)) {
+				t.Errorf("first column = %+v, want sources %v", *got.Columns[0], tt.value)
+			}
+		})
+	}
+}
+
+func TestExtractLineage_Files(t *testing.T) {
+	lineage, err := ExtractLineage(`
+		SELECT c.id, o.total
+		FROM read_csv('raw/customers.csv') c
+		JOIN read_parquet('raw/orders.parquet') o ON c.id = o.customer_id
+		JOIN regions r ON c.region_id = r.id`, nil)
+	if err != nil {
+		t.Fatalf("ExtractLineage failed: %v", err)
+	}
+
+	wantFiles := []string{"raw/customers.csv", "raw/orders.parquet"}
+	if strings.Join(lineage.Files, ",") != strings.Join(wantFiles, ",") {
+		t.Errorf("Files = %v, want %v", lineage.Files, wantFiles)
+	}
+	for _, src := range append(wantFiles, "regions") {
+		if !contains(lineage.Sources, src) {
+			t.Errorf("missing source %q, got %v", src, lineage.Sources)
+		}
+	}
+}
+
+func TestExtractLineage_Dialects(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name:    "postgres cast and string_agg",
+			dialect: Postgres,
+			sql:     `SELECT customer_id, string_agg(name::text, ', ') AS names, now() AS loaded_at FROM orders GROUP BY customer_id`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "customer_id", transform: TransformDirect},
+				{name: "names", transform: TransformExpression, function: "string_agg", srcCount: srcN(1)},
+				{name: "loaded_at", transform: TransformExpression, function: "now", srcCount: srcN(0)},
+			},
+		},
+		{
+			name:    "snowflake upper-case normalization and aliases",
+			dialect: Snowflake,
+			sql:     `SELECT O.id, iff(o.amount > 0, 'paid', 'free') AS kind, count_if(o.refunded) AS refunds FROM raw.orders o GROUP BY 1, 2`,
+			sources: []string{"RAW.ORDERS"},
+			cols: []colSpec{
+				{name: "ID", transform: TransformDirect, srcTable: "RAW.ORDERS"},
+				{name: "KIND", transform: TransformDirect},
+				{name: "REFUNDS", transform: TransformExpression, function: "COUNT_IF"},
+			},
+		},
+		{
+			name:    "snowflake lateral flatten",
+			dialect: Snowflake,
+			sql: `SELECT o.id, f.value:: VARCHAR AS item, f.index AS position
+				FROM orders o, LATERAL FLATTEN(input => o.items) f`,
+			sources: []string{"ORDERS"},
+			cols: []colSpec{
+				{name: "ID", transform: TransformDirect},
+				{name: "ITEM", transform: TransformExpression, srcTable: "ORDERS"},
+				{name: "POSITION", transform: TransformDirect, srcTable: "ORDERS"},
+			},
+		},
+		{
+			name:    "snowflake table(flatten())",
+			dialect: Snowflake,
+			sql:     `SELECT e.value AS tag FROM events, TABLE(FLATTEN(input => events.tags)) e`,
+			sources: []string{"EVENTS"},
+			cols: []colSpec{
+				{name: "TAG", transform: TransformDirect, srcTable: "EVENTS"},
+			},
+		},
+		{
+			name:    "bigquery backtick path",
+			dialect: BigQuery,
+			sql:     "SELECT o.id, countif(o.status = \"done\") AS done FROM `my-project.sales.orders` AS o GROUP BY o.id",
+			sources: []string{"my-project.sales.orders"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect, srcTable: "my-project.sales.orders"},
+				{name: "done", transform: TransformExpression, function: "countif"},
+			},
+		},
+		{
+			name:    "bigquery split quoted path",
+			dialect: BigQuery,
+			sql:     "SELECT id, 'it\\'s' AS note, generate_uuid() AS uid FROM `my-project`.sales.`orders`",
+			sources: []string{"my-project.sales.orders"},
+			cols: []colSpec{
+				{name: "id", transform: TransformDirect},
+				{name: "note", transform: TransformExpression},
+				{name: "uid", transform: TransformExpression, function: "generate_uuid", srcCount: srcN(0)},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_IdentifierCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		sql     string
+		schema  Schema
+		sources []string
+		columns map[string][]SourceColumn
+	}{
+		{
+			name:    "duckdb spellings of one table",
+			sql:     `SELECT a.id, B."Name" FROM Staging.Customers a JOIN "staging"."CUSTOMERS" b ON a.id = b.id`,
+			sources: []string{"staging.customers"},
+			columns: map[string][]SourceColumn{
+				"id":   {{Table: "staging.customers", Column: "id"}},
+				"Name": {{Table: "staging.customers", Column: "Name"}},
+			},
+		},
+		{
+			name:    "snowflake quoted names keep their case",
+			dialect: Snowflake,
+			sql:     `WITH c AS (SELECT "id", ID FROM Raw.Orders) SELECT c."id" AS lower_id, c.id AS upper_id FROM c`,
+			sources: []string{"RAW.ORDERS"},
+			columns: map[string][]SourceColumn{
+				"LOWER_ID": {{Table: "RAW.ORDERS", Column: "id"}},
+				"UPPER_ID": {{Table: "RAW.ORDERS", Column: "ID"}},
+			},
+		},
+		{
+			name:    "snowflake quoted table",
+			dialect: Snowflake,
+			sql:     `SELECT o.amount FROM "raw"."orders" o`,
+			sources: []string{"raw.orders"},
+			columns: map[string][]SourceColumn{
+				"AMOUNT": {{Table: "raw.orders", Column: "AMOUNT"}},
+			},
+		},
+		{
+			name:    "snowflake schema names are unquoted",
+			dialect: Snowflake,
+			sql:     `SELECT * FROM raw.orders`,
+			schema:  Schema{"raw.orders": {"id"}},
+			sources: []string{"RAW.ORDERS"},
+			columns: map[string][]SourceColumn{
+				"ID": {{Table: "ORDERS", Column: "ID"}},
+			},
+		},
+		{
+			name:    "postgres quoted and unquoted columns",
+			dialect: Postgres,
+			sql:     `SELECT p."Name", p.Name AS plain FROM People p`,
+			sources: []string{"people"},
+			columns: map[string][]SourceColumn{
+				"Name":  {{Table: "people", Column: "Name"}},
+				"plain": {{Table: "people", Column: "name"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineage, err := ExtractLineageWithOptions(tt.sql, ExtractLineageOptions{Dialect: tt.dialect, Schema: tt.schema})
+			if err != nil {
+				t.Fatalf("ExtractLineage failed: %v", err)
+			}
+			if !reflect.DeepEqual(lineage.Sources, tt.sources) {
+				t.Errorf("Sources = %v, want %v", lineage.Sources, tt.sources)
+			}
+			if len(lineage.Columns) != len(tt.columns) {
+				t.Errorf("got %d columns, want %d", len(lineage.Columns), len(tt.columns))
+			}
+			for name, want := range tt.columns {
+				col := findColumn(lineage.Columns, name)
+				if col == nil {
+					t.Errorf("missing column %q", name)
+					continue
+				}
+				if !reflect.DeepEqual(col.Sources, want) {
+					t.Errorf("column %q sources = %v, want %v", name, col.Sources, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithDialect_Syntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		dialect *Dialect
+		wantErr string
+	}{
+		{"bigquery rejects :: casts", `SELECT a::INT FROM t`, BigQuery, "bigquery does not support '::' casts"},
+		{"duckdb rejects backticks", "SELECT a FROM `t`", DuckDB, "expected table name"},
+		{"postgres accepts :: casts", `SELECT a::INT FROM t`, Postgres, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithDialect(tt.sql, tt.dialect)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetDialect_Registered(t *testing.T) {
+	for _, name := range []string{"duckdb", "postgres", "snowflake", "bigquery"} {
+		if _, ok := GetDialect(name); !ok {
+			t.Errorf("dialect %q is not registered", name)
+		}
+	}
+}
+
 func TestExtractLineage_Errors(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1408,3 +2297,271 @@ func BenchmarkExtractLineage_Complex(b *testing.B) {
 		_, _ = ExtractLineage(sql, nil)
 	}
 }
+
+func TestFormat(t *testing.T) {
+	got, err := Format(`-- daily totals
+with t as (select id, sum(amount) total from orders o inner join customers c on o.cid = c.id where status = 'ok' group by id)
+
+select * from t -- all of it
+order by total desc;`, FormatOptions{})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `-- daily totals
+WITH t AS (
+    SELECT
+        id,
+        SUM(amount) AS total
+    FROM orders AS o
+    JOIN customers AS c ON o.cid = c.id
+    WHERE status = 'ok'
+    GROUP BY id
+)
+SELECT *
+FROM t -- all of it
+ORDER BY total DESC;
+`
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormat_Options(t *testing.T) {
+	opts := FormatOptions{PrintOptions: PrintOptions{KeywordCase: KeywordLower, CommaStyle: CommaLeading, Indent: 2, LineWidth: 30}}
+	got, err := Format(`SELECT a, COALESCE(b, c) AS d FROM "T" WHERE a > 1 AND b < 2`, opts)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `select
+  a
+  , coalesce(b, c) as d
+from "T"
+where a > 1 and b < 2
+`
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormat_Idempotent(t *testing.T) {
+	for _, sql := range []string{
+		`SELECT CASE WHEN aaaaaaaaaaaaaaaa = 1 THEN 'one' WHEN bbbbbbbbbbbbbbbb = 2 THEN 'two' END AS y, SUM(x) OVER (PARTITION BY aaaaaaaaaaaaaa ORDER BY cccccccccccccccccc ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS z FROM t`,
+		`SELECT a /* first */, b -- second
+		FROM t LEFT OUTER JOIN u USING (id) WHERE a IN (SELECT id FROM v) OR NOT EXISTS (SELECT 1 FROM w) UNION ALL SELECT c, d FROM x`,
+		`SELECT x::INT, -(-1), {a: 1, 'b': 2}, [1, 2][1], list_transform(l, x -> x + 1), 'it''s' FROM t`,
+		`PIVOT t ON year USING SUM(v) GROUP BY k`,
+		`SELECT * FROM t PIVOT (SUM(v) FOR year IN (2023, 2024)) AS p`,
+		`SET threads = 4;
+		CREATE OR REPLACE TABLE analytics.daily (day, total) AS SELECT d, sum(x) FROM raw GROUP BY d;
+		INSERT INTO audit VALUES (1, 'a'), (2, 'b');
+		UPDATE t SET a = 1 WHERE id = 2;
+		DELETE FROM t USING s WHERE t.id = s.id;
+		MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET a = s.a WHEN NOT MATCHED THEN INSERT (id, a) VALUES (s.id, s.a);`,
+		`WITH s AS (SELECT id FROM u) INSERT INTO t SELECT * FROM s;
+		WITH s AS (SELECT id FROM u) -- stale rows
+		DELETE FROM t WHERE id IN (SELECT id FROM s);`,
+	} {
+		once, err := Format(sql, FormatOptions{})
+		if err != nil {
+			t.Errorf("Format(%q) failed: %v", sql, err)
+			continue
+		}
+		twice, err := Format(once, FormatOptions{})
+		if err != nil || twice != once {
+			t.Errorf("formatting again changed\n%s\nto\n%s (err: %v)", once, twice, err)
+		}
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	if _, err := Format(`SELECT a FROM`, FormatOptions{}); err == nil {
+		t.Error("expected a syntax error")
+	}
+	// UNION DISTINCT and INTERSECT ALL are the same query to the parser
+	if _, err := Format(`SELECT a FROM t INTERSECT ALL SELECT a FROM u`, FormatOptions{}); err == nil {
+		t.Error("expected an error rather than dropping ALL")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	stmt, err := Parse(`SELECT "select", a.b, x::int FROM t WHERE (a OR b) AND NOT c`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := Print(stmt, PrintOptions{}), `SELECT "select", a.b, x::INT FROM t WHERE (a OR b) AND NOT c`; got != want {
+		t.Errorf("Print() = %s, want %s", got, want)
+	}
+
+	bigquery, _ := GetDialect("bigquery")
+	if got, want := Print(stmt.Body.Left.Columns[0].Expr, PrintOptions{Dialect: bigquery}), "`select`"; got != want {
+		t.Errorf("Print() = %s, want %s", got, want)
+	}
+}
+
+func TestTranspile(t *testing.T) {
+	duckdb, _ := GetDialect("duckdb")
+	postgres, _ := GetDialect("postgres")
+	snowflake, _ := GetDialect("snowflake")
+	bigquery, _ := GetDialect("bigquery")
+	tests := []struct {
+		name     string
+		sql      string
+		from, to *Dialect
+		want     string
+	}{
+		{
+			"quoting and casts",
+			`SELECT "Order".id, x::int FROM "Order" WHERE s = 'it''s'`,
+			duckdb, bigquery,
+			"SELECT `Order`.id, CAST(x AS INT) FROM `Order` WHERE s = 'it\\'s'\n",
+		},
+		{
+			"strings and paths",
+			"SELECT `my col`, \"str\" FROM t",
+			bigquery, duckdb,
+			"SELECT \"my col\", 'str' FROM t\n",
+		},
+		{
+			"target aliases and spellings",
+			`SELECT ifnull(a, 0), if(b, 1, 2), string_agg(s, ',') FROM t`,
+			duckdb, snowflake,
+			"SELECT IFNULL(a, 0), IFF(b, 1, 2), LISTAGG(s, ',') FROM t\n",
+		},
+		{
+			"canonical names",
+			`SELECT iff(a, 1, 2), nvl(a, b), getdate(), wm_concat(s) FROM t`,
+			snowflake, postgres,
+			"SELECT IF(a, 1, 2), COALESCE(a, b), CURRENT_TIMESTAMP, STRING_AGG(s) FROM t\n",
+		},
+		{
+			"functions the target lacks",
+			`SELECT iff(a, 1, 2), now(), current_date FROM t`,
+			duckdb, bigquery,
+			"SELECT IF(a, 1, 2), CURRENT_TIMESTAMP, CURRENT_DATE FROM t\n",
+		},
+		{
+			"quoted column named like a function",
+			`SELECT "current_date" FROM t`,
+			duckdb, bigquery,
+			"SELECT `current_date` FROM t\n",
+		},
+		{
+			"comments and statements",
+			"-- load\nCREATE TABLE x AS SELECT substr(s, 1) FROM t; INSERT INTO x SELECT 1",
+			duckdb, postgres,
+			"-- load\nCREATE TABLE x AS SELECT SUBSTR(s, 1) FROM t;\n\nINSERT INTO x SELECT 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Transpile(tt.sql, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Transpile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transpile() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Transpile(`SELECT a FROM`, duckdb, postgres); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
+
+// counter counts the nodes it visits by type.
+type counter map[string]int
+
+func (c counter) Visit(node Node) Visitor {
+	if node != nil {
+		c[fmt.Sprintf("%T", node)]++
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	stmts, err := ParseScript(`
+		WITH c AS (SELECT id FROM raw.orders WHERE x IN (SELECT y FROM raw.t))
+		SELECT c.id, sum(a) OVER (PARTITION BY b ROWS 1 PRECEDING) FROM c JOIN raw.cust ON c.id = cust.id;
+		UPDATE t SET a = b + 1 WHERE EXISTS (SELECT 1 FROM u);
+		MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET a = s.a WHEN NOT MATCHED THEN INSERT (a) VALUES (s.a)`)
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	c := counter{}
+	for _, stmt := range stmts {
+		Walk(stmt, c)
+	}
+	want := map[string]int{
+		"*lineage.SelectStmt":  4,
+		"*lineage.TableName":   8,
+		"*lineage.Assignment":  2,
+		"*lineage.MergeClause": 2,
+		"*lineage.FrameBound":  1,
+		"*lineage.ColumnRef":   13,
+	}
+	for typ, n := range want {
+		if c[typ] != n {
+			t.Errorf("visited %d %s, want %d", c[typ], typ, n)
+		}
+	}
+
+	// Returning false skips the children of a node
+	var tables []string
+	Inspect(stmts[0], func(node Node) bool {
+		if table, ok := node.(*TableName); ok {
+			tables = append(tables, table.Name)
+		}
+		_, isCTE := node.(*CTE)
+		return !isCTE
+	})
+	if got := strings.Join(tables, ","); got != "c,cust" {
+		t.Errorf("tables = %s, want c,cust", got)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	stmt, err := Parse(`SELECT o.id, sum(amount) FROM raw.orders AS o, raw.refunds WHERE 1 = 1 AND o.id > 0 GROUP BY o.id`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got := Rewrite(stmt, func(node Node) Node {
+		switch x := node.(type) {
+		case *TableName:
+			if x.Schema == "raw" {
+				x.Schema = "dev_raw"
+			}
+		case *ColumnRef:
+			if x.Column == "amount" {
+				return &FuncCall{Name: "COALESCE", Args: []Expr{x, &Literal{Value: "0"}}}
+			}
+		case *SelectItem:
+			if _, ok := x.Expr.(*FuncCall); ok {
+				return &SelectItem{Expr: x.Expr, Alias: "total"}
+			}
+		case *Join:
+			return nil
+		case *BinaryExpr:
+			if _, ok := x.Left.(*Literal); ok && x.Op == "=" {
+				return &Literal{Type: LiteralBool, Value: "TRUE"}
+			}
+		}
+		return node
+	})
+	want := `SELECT o.id, SUM(COALESCE(amount, 0)) AS total FROM dev_raw.orders AS o WHERE TRUE AND o.id > 0 GROUP BY o.id`
+	if printed := Print(got, PrintOptions{LineWidth: 200}); printed != want {
+		t.Errorf("Print(Rewrite()) = %s, want %s", printed, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic replacing a table with an expression")
+		}
+	}()
+	Rewrite(stmt, func(node Node) Node {
+		if _, ok := node.(*TableName); ok {
+			return &Literal{Value: "1"}
+		}
+		return node
+	})
+}
@@ -6,7 +6,9 @@
 //
 //   - parser.go (this file): Public API, Parser struct, token helpers
 //   - parser_stmt.go: Statement parsing (WITH, SELECT body, ORDER BY)
+//   - parser_script.go: Scripts of DDL/DML statements (CREATE, INSERT, UPDATE, DELETE, MERGE)
 //   - parser_from.go: FROM clause parsing (table refs, JOINs)
+//   - parser_pivot.go: PIVOT and UNPIVOT, in FROM and as statements
 //   - parser_expr.go: Expression precedence parsing (OR, AND, comparisons, arithmetic)
 //   - parser_primary.go: Primary expressions (literals, column refs, function calls)
 //   - parser_window.go: Window specifications and frame specs
@@ -32,7 +34,10 @@
 // See each file for detailed grammar rules for that section.
 package lineage
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 // Parser parses SQL into an AST.
 type Parser struct {
@@ -40,14 +45,39 @@ type Parser struct {
 	token        Token // current token
 	peek         Token // lookahead token
 	peek2        Token // second lookahead token
-	errors       []error
-	inSelectList bool // true when parsing SELECT columns (to detect scalar subqueries)
+	prev         Token // last consumed token, where spans end
+	errors       ParseErrors
+	recovering   bool     // after a syntax error, until the parser synchronizes
+	inSelectList bool     // true when parsing SELECT columns (to detect scalar subqueries)
+	dialect      *Dialect // syntax extensions such as '::' casts
 }
 
-// NewParser creates a new parser for the given SQL input.
+// NewParser creates a new parser for the given SQL input using the default dialect.
 func NewParser(sql string) *Parser {
+	return NewParserWithDialect(sql, nil)
+}
+
+// NewParserWithDialect creates a new parser that accepts a dialect's syntax.
+func NewParserWithDialect(sql string, dialect *Dialect) *Parser {
+	return newParser(sql, dialect, false)
+}
+
+// newLineageParser creates a parser for lineage extraction. Unquoted names
+// are folded to the dialect's case as they are lexed, so names compare the
+// way the database compares them while quoted names keep their spelling.
+func newLineageParser(sql string, dialect *Dialect) *Parser {
+	return newParser(sql, dialect, true)
+}
+
+func newParser(sql string, dialect *Dialect, fold bool) *Parser {
+	if dialect == nil {
+		dialect = DefaultDialect()
+	}
+	lexer := NewLexerWithDialect(sql, dialect)
+	lexer.fold = fold
 	p := &Parser{
-		lexer: NewLexer(sql),
+		lexer:   lexer,
+		dialect: dialect,
 	}
 	// Read three tokens to initialize current, peek, and peek2
 	p.nextToken()
@@ -58,10 +88,16 @@ func NewParser(sql string) *Parser {
 
 // Parse parses the SQL and returns the AST.
 func Parse(sql string) (*SelectStmt, error) {
-	p := NewParser(sql)
+	return ParseWithDialect(sql, nil)
+}
+
+// ParseWithDialect parses the SQL using a dialect's syntax and returns the AST.
+// On syntax errors, the error is a ParseErrors listing all of them.
+func ParseWithDialect(sql string, dialect *Dialect) (*SelectStmt, error) {
+	p := NewParserWithDialect(sql, dialect)
 	stmt := p.parseStatement()
 	if len(p.errors) > 0 {
-		return nil, p.errors[0]
+		return nil, p.errors
 	}
 	return stmt, nil
 }
@@ -70,6 +106,7 @@ func Parse(sql string) (*SelectStmt, error) {
 
 // nextToken advances to the next token.
 func (p *Parser) nextToken() {
+	p.prev = p.token
 	p.token = p.peek
 	p.peek = p.peek2
 	p.peek2 = p.lexer.NextToken()
@@ -120,14 +157,82 @@ func (p *Parser) expect(t TokenType) bool {
 	return false
 }
 
-// addError adds a parse error.
+// addError adds a parse error. Errors that follow before the parser
+// synchronizes are mostly consequences of the first, so they are dropped.
 func (p *Parser) addError(msg string) {
+	if p.recovering {
+		return
+	}
+	p.recovering = true
 	p.errors = append(p.errors, &ParseError{
 		Pos:     p.token.Pos,
 		Message: msg,
 	})
 }
 
+// synchronize recovers from a syntax error: it skips tokens up to the start
+// of a clause, one of the given types, the end of the statement or a
+// parenthesis closing the enclosing group, and resumes reporting errors.
+func (p *Parser) synchronize(stop ...TokenType) {
+	if !p.recovering {
+		return
+	}
+	depth := 0
+	for !p.check(TOKEN_EOF) {
+		if depth == 0 && (p.check(TOKEN_SEMI) || p.check(TOKEN_RPAREN) || p.check(TOKEN_FROM) ||
+			p.isClauseKeyword(p.token) || p.checkAny(stop...)) {
+			break
+		}
+		switch p.token.Type {
+		case TOKEN_LPAREN:
+			depth++
+		case TOKEN_RPAREN:
+			depth--
+		}
+		p.nextToken()
+	}
+	p.recovering = false
+}
+
+// checkAny returns true if the current token is of any of the given types.
+func (p *Parser) checkAny(types ...TokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- Span Helpers ----------
+
+// spanFrom returns the span from start to the end of the last consumed token.
+func (p *Parser) spanFrom(start Position) Span {
+	end := p.prev.End
+	if end.Offset < start.Offset {
+		// Nothing consumed, e.g. after a syntax error
+		end = start
+	}
+	return Span{Start: start, End: end}
+}
+
+// setSpan records the span of a node parsed from start. Nil nodes, left by
+// syntax errors, are ignored.
+func (p *Parser) setSpan(node Node, start Position) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return
+	}
+	if n, ok := node.(interface{ setSpan(Span) }); ok {
+		n.setSpan(p.spanFrom(start))
+	}
+}
+
+// spanned records the span of an expression parsed from start and returns it.
+func (p *Parser) spanned(expr Expr, start Position) Expr {
+	p.setSpan(expr, start)
+	return expr
+}
+
 // ---------- Keyword Helpers ----------
 
 // isKeyword returns true if the token is a reserved keyword that can't be used as alias.
@@ -136,7 +241,7 @@ func (p *Parser) isKeyword(tok Token) bool {
 	case TOKEN_FROM, TOKEN_WHERE, TOKEN_GROUP, TOKEN_HAVING, TOKEN_ORDER,
 		TOKEN_LIMIT, TOKEN_UNION, TOKEN_INTERSECT, TOKEN_EXCEPT,
 		TOKEN_LEFT, TOKEN_RIGHT, TOKEN_INNER, TOKEN_OUTER, TOKEN_FULL,
-		TOKEN_CROSS, TOKEN_JOIN, TOKEN_ON, TOKEN_QUALIFY:
+		TOKEN_CROSS, TOKEN_JOIN, TOKEN_ON, TOKEN_USING, TOKEN_NATURAL, TOKEN_QUALIFY:
 		return true
 	}
 	return false
@@ -146,7 +251,7 @@ func (p *Parser) isKeyword(tok Token) bool {
 func (p *Parser) isJoinKeyword(tok Token) bool {
 	switch tok.Type {
 	case TOKEN_JOIN, TOKEN_LEFT, TOKEN_RIGHT, TOKEN_INNER, TOKEN_OUTER,
-		TOKEN_FULL, TOKEN_CROSS, TOKEN_ON, TOKEN_LATERAL:
+		TOKEN_FULL, TOKEN_CROSS, TOKEN_ON, TOKEN_USING, TOKEN_NATURAL, TOKEN_LATERAL:
 		return true
 	}
 	return false
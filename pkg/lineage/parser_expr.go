@@ -1,5 +1,10 @@
 package lineage
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Expression precedence parsing: OR, AND, NOT, comparisons, arithmetic operators.
 //
 // Precedence (lowest to highest):
@@ -11,7 +16,8 @@ package lineage
 //  5. Addition: +, -, ||
 //  6. Multiplication: *, /, %
 //  7. Unary: -, +
-//  8. Primary: literals, column refs, function calls, parenthesized expressions
+//  8. Postfix: expr::type (dialects with DoubleColonCast), expr[i], expr[a:b], expr.field
+//  9. Primary: literals, column refs, function calls, parenthesized expressions
 //
 // Grammar:
 //
@@ -22,7 +28,9 @@ package lineage
 //	comparison    → addition ([NOT] (IN | BETWEEN | LIKE | ILIKE) ... | IS [NOT] NULL | cmp_op addition)?
 //	addition      → multiplication (("+"|"-"|"||") multiplication)*
 //	multiplication→ unary (("*"|"/"|"%") unary)*
-//	unary         → ("-"|"+") unary | primary
+//	unary         → ("-"|"+") unary | postfix
+//	postfix       → primary ("::" type_name | "[" subscript "]" | "." identifier)*
+//	subscript     → expr | [expr] ":" [expr]
 
 // parseExpression parses an expression.
 func (p *Parser) parseExpression() Expr {
@@ -31,11 +39,12 @@ func (p *Parser) parseExpression() Expr {
 
 // parseOrExpr parses OR expressions.
 func (p *Parser) parseOrExpr() Expr {
+	start := p.token.Pos
 	left := p.parseAndExpr()
 
 	for p.match(TOKEN_OR) {
 		right := p.parseAndExpr()
-		left = &BinaryExpr{Left: left, Op: "OR", Right: right}
+		left = p.spanned(&BinaryExpr{Left: left, Op: "OR", Right: right}, start)
 	}
 
 	return left
@@ -43,11 +52,12 @@ func (p *Parser) parseOrExpr() Expr {
 
 // parseAndExpr parses AND expressions.
 func (p *Parser) parseAndExpr() Expr {
+	start := p.token.Pos
 	left := p.parseNotExpr()
 
 	for p.match(TOKEN_AND) {
 		right := p.parseNotExpr()
-		left = &BinaryExpr{Left: left, Op: "AND", Right: right}
+		left = p.spanned(&BinaryExpr{Left: left, Op: "AND", Right: right}, start)
 	}
 
 	return left
@@ -55,15 +65,17 @@ func (p *Parser) parseAndExpr() Expr {
 
 // parseNotExpr parses NOT expressions.
 func (p *Parser) parseNotExpr() Expr {
+	start := p.token.Pos
 	if p.match(TOKEN_NOT) {
 		expr := p.parseNotExpr()
-		return &UnaryExpr{Op: "NOT", Expr: expr}
+		return p.spanned(&UnaryExpr{Op: "NOT", Expr: expr}, start)
 	}
 	return p.parseComparison()
 }
 
 // parseComparison parses comparison expressions.
 func (p *Parser) parseComparison() Expr {
+	start := p.token.Pos
 	left := p.parseAddition()
 
 	// Check for special comparison operators
@@ -74,30 +86,30 @@ func (p *Parser) parseComparison() Expr {
 
 	switch {
 	case p.match(TOKEN_IN):
-		return p.parseInExpr(left, not)
+		return p.spanned(p.parseInExpr(left, not), start)
 
 	case p.match(TOKEN_BETWEEN):
-		return p.parseBetweenExpr(left, not)
+		return p.spanned(p.parseBetweenExpr(left, not), start)
 
 	case p.match(TOKEN_LIKE):
-		return p.parseLikeExpr(left, not, false)
+		return p.spanned(p.parseLikeExpr(left, not, false), start)
 
 	case p.match(TOKEN_ILIKE):
-		return p.parseLikeExpr(left, not, true)
+		return p.spanned(p.parseLikeExpr(left, not, true), start)
 	}
 
 	// If we consumed NOT but didn't find IN/BETWEEN/LIKE, it was for IS NOT NULL
 	if not {
 		// Put NOT back conceptually - actually this path shouldn't happen
 		// because NOT IS would be parsed differently
-		return &UnaryExpr{Op: "NOT", Expr: left}
+		return p.spanned(&UnaryExpr{Op: "NOT", Expr: left}, start)
 	}
 
 	// IS NULL / IS NOT NULL
 	if p.match(TOKEN_IS) {
 		isNot := p.match(TOKEN_NOT)
 		if p.match(TOKEN_NULL) {
-			return &IsNullExpr{Expr: left, Not: isNot}
+			return p.spanned(&IsNullExpr{Expr: left, Not: isNot}, start)
 		}
 		// IS TRUE / IS FALSE could be handled here
 		p.addError("expected NULL after IS")
@@ -107,22 +119,22 @@ func (p *Parser) parseComparison() Expr {
 	switch p.token.Type {
 	case TOKEN_EQ:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: "=", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: "=", Right: p.parseAddition()}, start)
 	case TOKEN_NE:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: "!=", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: "!=", Right: p.parseAddition()}, start)
 	case TOKEN_LT:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: "<", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: "<", Right: p.parseAddition()}, start)
 	case TOKEN_GT:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: ">", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: ">", Right: p.parseAddition()}, start)
 	case TOKEN_LE:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: "<=", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: "<=", Right: p.parseAddition()}, start)
 	case TOKEN_GE:
 		p.nextToken()
-		return &BinaryExpr{Left: left, Op: ">=", Right: p.parseAddition()}
+		return p.spanned(&BinaryExpr{Left: left, Op: ">=", Right: p.parseAddition()}, start)
 	}
 
 	return left
@@ -163,19 +175,20 @@ func (p *Parser) parseLikeExpr(left Expr, not bool, ilike bool) Expr {
 
 // parseAddition parses addition/subtraction/concatenation expressions.
 func (p *Parser) parseAddition() Expr {
+	start := p.token.Pos
 	left := p.parseMultiplication()
 
 	for {
 		switch p.token.Type {
 		case TOKEN_PLUS:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "+", Right: p.parseMultiplication()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "+", Right: p.parseMultiplication()}, start)
 		case TOKEN_MINUS:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "-", Right: p.parseMultiplication()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "-", Right: p.parseMultiplication()}, start)
 		case TOKEN_DPIPE:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "||", Right: p.parseMultiplication()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "||", Right: p.parseMultiplication()}, start)
 		default:
 			return left
 		}
@@ -184,19 +197,20 @@ func (p *Parser) parseAddition() Expr {
 
 // parseMultiplication parses multiplication/division/modulo expressions.
 func (p *Parser) parseMultiplication() Expr {
+	start := p.token.Pos
 	left := p.parseUnary()
 
 	for {
 		switch p.token.Type {
 		case TOKEN_STAR:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "*", Right: p.parseUnary()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "*", Right: p.parseUnary()}, start)
 		case TOKEN_SLASH:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "/", Right: p.parseUnary()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "/", Right: p.parseUnary()}, start)
 		case TOKEN_PERCENT:
 			p.nextToken()
-			left = &BinaryExpr{Left: left, Op: "%", Right: p.parseUnary()}
+			left = p.spanned(&BinaryExpr{Left: left, Op: "%", Right: p.parseUnary()}, start)
 		default:
 			return left
 		}
@@ -205,13 +219,64 @@ func (p *Parser) parseMultiplication() Expr {
 
 // parseUnary parses unary expressions.
 func (p *Parser) parseUnary() Expr {
+	start := p.token.Pos
 	switch p.token.Type {
 	case TOKEN_MINUS:
 		p.nextToken()
-		return &UnaryExpr{Op: "-", Expr: p.parseUnary()}
+		return p.spanned(&UnaryExpr{Op: "-", Expr: p.parseUnary()}, start)
 	case TOKEN_PLUS:
 		p.nextToken()
-		return &UnaryExpr{Op: "+", Expr: p.parseUnary()}
+		return p.spanned(&UnaryExpr{Op: "+", Expr: p.parseUnary()}, start)
+	}
+	return p.parsePostfix(p.parsePrimary(), start)
+}
+
+// parsePostfix parses '::' casts, subscripts and field access following a
+// primary expression that starts at start.
+func (p *Parser) parsePostfix(expr Expr, start Position) Expr {
+	for {
+		switch p.token.Type {
+		case TOKEN_DCOLON:
+			if !p.dialect.Syntax.DoubleColonCast {
+				p.addError(fmt.Sprintf("%s does not support '::' casts", p.dialect.Name))
+				return expr
+			}
+			p.nextToken()
+			expr = p.spanned(&CastExpr{Expr: expr, TypeName: p.parseTypeName(), DoubleColon: true}, start)
+
+		case TOKEN_LBRACKET:
+			expr = p.spanned(p.parseSubscript(expr), start)
+
+		case TOKEN_DOT:
+			p.nextToken()
+			// Keywords are field names after a dot, as with column refs
+			if _, isKeyword := keywords[strings.ToLower(p.token.Literal)]; !p.check(TOKEN_IDENT) && !isKeyword {
+				p.addError("expected field name after '.'")
+				return expr
+			}
+			field := &FieldExpr{Expr: expr, Field: p.token.Literal}
+			p.nextToken()
+			expr = p.spanned(field, start)
+
+		default:
+			return expr
+		}
+	}
+}
+
+// parseSubscript parses a list subscript or slice: xs[1], xs[1:3], xs[:2].
+func (p *Parser) parseSubscript(expr Expr) Expr {
+	p.expect(TOKEN_LBRACKET)
+	index := &IndexExpr{Expr: expr}
+	if !p.check(TOKEN_COLON) {
+		index.Index = p.parseExpression()
+	}
+	if p.match(TOKEN_COLON) {
+		index.Slice = true
+		if !p.check(TOKEN_RBRACKET) {
+			index.End = p.parseExpression()
+		}
 	}
-	return p.parsePrimary()
+	p.expect(TOKEN_RBRACKET)
+	return index
 }
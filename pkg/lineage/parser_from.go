@@ -1,20 +1,24 @@
 package lineage
 
+import "strings"
+
 // FROM clause parsing: table references, derived tables, lateral joins, JOINs.
 //
 // Grammar:
 //
 //	from_clause   → table_ref (join)*
-//	table_ref     → table_name | derived_table | lateral_table
+//	table_ref     → (table_name | derived_table | lateral_table | table_func) (pivot_clause | unpivot_clause)*
 //	table_name    → [catalog "."] [schema "."] identifier [AS identifier]
 //	derived_table → "(" statement ")" [AS] identifier
 //	lateral_table → LATERAL "(" statement ")" [AS] identifier
-//	join          → join_type JOIN table_ref [ON expr] | "," table_ref
+//	table_func    → [LATERAL] [TABLE "("] identifier "(" [arg_list] ")" [")"] [[AS] identifier ["(" ident_list ")"]]
+//	join          → [NATURAL] join_type JOIN table_ref [ON expr | USING "(" ident_list ")"] | "," table_ref
 //	join_type     → [INNER] | LEFT [OUTER] | RIGHT [OUTER] | FULL [OUTER] | CROSS
 
 // parseFromClause parses the FROM clause.
 func (p *Parser) parseFromClause() *FromClause {
 	from := &FromClause{}
+	defer p.setSpan(from, p.token.Pos)
 	from.Source = p.parseTableRef()
 
 	// Parse JOINs
@@ -29,11 +33,32 @@ func (p *Parser) parseFromClause() *FromClause {
 	return from
 }
 
-// parseTableRef parses a table reference.
+// parseTableRef parses a table reference, with any PIVOT or UNPIVOT clauses
+// that follow it (see parser_pivot.go).
 func (p *Parser) parseTableRef() TableRef {
-	// LATERAL subquery
+	return p.parsePivotClauses(p.parseBaseTableRef())
+}
+
+// parseBaseTableRef parses a table reference without PIVOT or UNPIVOT clauses.
+func (p *Parser) parseBaseTableRef() TableRef {
+	start := p.token.Pos
+
+	// LATERAL subquery or table function
 	if p.match(TOKEN_LATERAL) {
-		return p.parseLateralTable()
+		if p.check(TOKEN_IDENT) && p.checkPeek(TOKEN_LPAREN) {
+			fn := p.parseTableFunction()
+			fn.Lateral = true
+			p.setSpan(fn, start)
+			return fn
+		}
+		lateral := p.parseLateralTable()
+		p.setSpan(lateral, start)
+		return lateral
+	}
+
+	// Table function: FLATTEN(...), TABLE(FLATTEN(...)), range(10)
+	if (p.check(TOKEN_IDENT) || p.check(TOKEN_RANGE)) && p.checkPeek(TOKEN_LPAREN) {
+		return p.parseTableFunction()
 	}
 
 	// Derived table (subquery)
@@ -45,9 +70,31 @@ func (p *Parser) parseTableRef() TableRef {
 	return p.parseTableName()
 }
 
-// parseTableName parses a table name with optional schema/catalog.
+// parseTableName parses a table name with optional schema/catalog and alias.
 func (p *Parser) parseTableName() *TableName {
+	start := p.token.Pos
+	table := p.parseObjectName()
+	defer p.setSpan(table, start)
+
+	// Optional alias
+	if p.match(TOKEN_AS) {
+		if p.check(TOKEN_IDENT) {
+			table.Alias = p.token.Literal
+			p.nextToken()
+		}
+	} else if p.check(TOKEN_IDENT) && !p.isJoinKeyword(p.token) && !p.isClauseKeyword(p.token) {
+		table.Alias = p.token.Literal
+		p.nextToken()
+	}
+
+	return table
+}
+
+// parseObjectName parses a possibly qualified relation name, without an
+// alias: catalog.schema.table.
+func (p *Parser) parseObjectName() *TableName {
 	table := &TableName{}
+	defer p.setSpan(table, p.token.Pos)
 
 	if !p.check(TOKEN_IDENT) {
 		p.addError("expected table name")
@@ -65,6 +112,15 @@ func (p *Parser) parseTableName() *TableName {
 		}
 	}
 
+	// BigQuery allows a whole path in one quoted identifier: `project.dataset.table`
+	if p.dialect.Syntax.QuotedPaths {
+		var split []string
+		for _, part := range parts {
+			split = append(split, strings.Split(part, ".")...)
+		}
+		parts = split
+	}
+
 	switch len(parts) {
 	case 1:
 		table.Name = parts[0]
@@ -77,24 +133,59 @@ func (p *Parser) parseTableName() *TableName {
 		table.Name = parts[2]
 	}
 
+	return table
+}
+
+// parseTableFunction parses a function call used as a table source.
+// TABLE(fn(...)) is unwrapped to fn.
+func (p *Parser) parseTableFunction() *TableFunction {
+	start := p.token.Pos
+	name := p.token.Literal
+	p.nextToken()
+
+	var call Expr
+	table := strings.EqualFold(name, "table") && p.checkPeek(TOKEN_IDENT) && p.checkPeek2(TOKEN_LPAREN)
+	if table {
+		p.expect(TOKEN_LPAREN)
+		inner := p.token.Literal
+		p.nextToken()
+		call = p.parseFuncCall(inner)
+		p.expect(TOKEN_RPAREN)
+	} else {
+		call = p.parseFuncCall(name)
+	}
+
+	fn := &TableFunction{Table: table}
+	if fc, ok := call.(*FuncCall); ok {
+		fn.Name = fc.Name
+		fn.Args = fc.Args
+	}
+
 	// Optional alias
 	if p.match(TOKEN_AS) {
 		if p.check(TOKEN_IDENT) {
-			table.Alias = p.token.Literal
+			fn.Alias = p.token.Literal
 			p.nextToken()
 		}
 	} else if p.check(TOKEN_IDENT) && !p.isJoinKeyword(p.token) && !p.isClauseKeyword(p.token) {
-		table.Alias = p.token.Literal
+		fn.Alias = p.token.Literal
 		p.nextToken()
 	}
 
-	return table
+	// Optional column aliases: AS t(a, b)
+	if fn.Alias != "" && p.check(TOKEN_LPAREN) {
+		fn.Columns = p.parseColumnList()
+	}
+
+	p.setSpan(fn, start)
+	return fn
 }
 
 // parseDerivedTable parses a derived table (subquery in FROM).
 func (p *Parser) parseDerivedTable() *DerivedTable {
-	p.expect(TOKEN_LPAREN)
 	derived := &DerivedTable{}
+	defer p.setSpan(derived, p.token.Pos)
+	p.expect(TOKEN_LPAREN)
 	derived.Select = p.parseStatement()
 	p.expect(TOKEN_RPAREN)
 
@@ -114,8 +205,9 @@ func (p *Parser) parseDerivedTable() *DerivedTable {
 
 // parseLateralTable parses a LATERAL subquery.
 func (p *Parser) parseLateralTable() *LateralTable {
-	p.expect(TOKEN_LPAREN)
 	lateral := &LateralTable{}
+	defer p.setSpan(lateral, p.token.Pos)
+	p.expect(TOKEN_LPAREN)
 	lateral.Select = p.parseStatement()
 	p.expect(TOKEN_RPAREN)
 
@@ -136,6 +228,7 @@ func (p *Parser) parseLateralTable() *LateralTable {
 // parseJoin parses a JOIN clause.
 func (p *Parser) parseJoin() *Join {
 	join := &Join{}
+	defer p.setSpan(join, p.token.Pos)
 
 	// Comma join (implicit cross join)
 	if p.match(TOKEN_COMMA) {
@@ -144,9 +237,11 @@ func (p *Parser) parseJoin() *Join {
 		return join
 	}
 
+	join.Natural = p.match(TOKEN_NATURAL)
+
 	// Determine join type
 	switch {
-	case p.match(TOKEN_CROSS):
+	case !join.Natural && p.match(TOKEN_CROSS):
 		join.Type = JoinCross
 		p.expect(TOKEN_JOIN)
 		join.Right = p.parseTableRef()
@@ -171,6 +266,9 @@ func (p *Parser) parseJoin() *Join {
 		join.Type = JoinInner // default
 
 	default:
+		if join.Natural {
+			p.addError("expected JOIN after NATURAL")
+		}
 		return nil // no join
 	}
 
@@ -180,10 +278,34 @@ func (p *Parser) parseJoin() *Join {
 
 	join.Right = p.parseTableRef()
 
-	// ON clause
-	if p.match(TOKEN_ON) {
+	// ON or USING clause (NATURAL joins take neither)
+	switch {
+	case join.Natural:
+	case p.match(TOKEN_ON):
 		join.Condition = p.parseExpression()
+	case p.match(TOKEN_USING):
+		join.Using = p.parseColumnList()
 	}
 
 	return join
 }
+
+// parseColumnList parses a parenthesized list of column names, as in
+// USING (a, b) or AS t(a, b).
+func (p *Parser) parseColumnList() []string {
+	p.expect(TOKEN_LPAREN)
+	var cols []string
+	for {
+		if !p.check(TOKEN_IDENT) {
+			p.addError("expected column name")
+			break
+		}
+		cols = append(cols, p.token.Literal)
+		p.nextToken()
+		if !p.match(TOKEN_COMMA) {
+			break
+		}
+	}
+	p.expect(TOKEN_RPAREN)
+	return cols
+}
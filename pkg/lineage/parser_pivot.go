@@ -0,0 +1,285 @@
+package lineage
+
+import "strings"
+
+// PIVOT and UNPIVOT parsing, in FROM and as DuckDB's statement forms.
+//
+// Grammar:
+//
+//	pivot_clause   → PIVOT "(" alias_item ("," alias_item)* FOR pivot_in+ ")" [[AS] identifier]
+//	unpivot_clause → UNPIVOT [(INCLUDE | EXCLUDE) NULLS] "(" value_cols FOR identifier IN "(" alias_item ("," alias_item)* ")" ")" [[AS] identifier]
+//	pivot_stmt     → PIVOT table_ref ON pivot_on ("," pivot_on)* [USING alias_item ("," alias_item)*]
+//	                 [GROUP BY expr_list] [ORDER BY order_list] [LIMIT expr]
+//	unpivot_stmt   → UNPIVOT table_ref ON expr_list INTO NAME identifier VALUE identifier ("," identifier)*
+//	                 [ORDER BY order_list] [LIMIT expr]
+//	pivot_in       → addition IN "(" (alias_item ("," alias_item)* | ANY [ORDER BY order_list] | statement) ")"
+//	pivot_on       → addition [IN "(" alias_item ("," alias_item)* ")"]
+//	value_cols     → identifier | "(" ident_list ")"
+//	alias_item     → expr [[AS] identifier]
+
+// parsePivotClauses wraps a table reference in any PIVOT or UNPIVOT clauses
+// that follow it.
+func (p *Parser) parsePivotClauses(ref TableRef) TableRef {
+	for {
+		switch {
+		case p.check(TOKEN_PIVOT):
+			ref = p.parsePivotClause(ref)
+		case p.check(TOKEN_UNPIVOT):
+			ref = p.parseUnpivotClause(ref)
+		default:
+			return ref
+		}
+	}
+}
+
+// parsePivotClause parses PIVOT (agg FOR col IN (values)) following a table reference.
+func (p *Parser) parsePivotClause(source TableRef) *PivotTable {
+	pivot := &PivotTable{Source: source}
+	defer p.setSpan(pivot, source.NodeSpan().Start)
+	p.expect(TOKEN_PIVOT)
+	p.expect(TOKEN_LPAREN)
+
+	for {
+		pivot.Aggregates = append(pivot.Aggregates, p.parseAliasItem())
+		if !p.match(TOKEN_COMMA) {
+			break
+		}
+	}
+
+	p.expectWord("FOR")
+	for {
+		start := p.token.Pos
+		col := PivotColumn{Expr: p.parseAddition()}
+		p.expect(TOKEN_IN)
+		col.Values = p.parsePivotValues()
+		col.Span = p.spanFrom(start)
+		pivot.On = append(pivot.On, col)
+
+		// DuckDB allows several columns: FOR a IN (...) b IN (...)
+		if p.check(TOKEN_RPAREN) || p.check(TOKEN_GROUP) || p.check(TOKEN_EOF) || p.recovering {
+			break
+		}
+	}
+
+	if p.match(TOKEN_GROUP) {
+		p.expect(TOKEN_BY)
+		pivot.GroupBy = p.parseExpressionList()
+	}
+
+	p.expect(TOKEN_RPAREN)
+	pivot.Alias = p.parsePivotAlias()
+	return pivot
+}
+
+// parseUnpivotClause parses UNPIVOT (value FOR name IN (cols)) following a table reference.
+func (p *Parser) parseUnpivotClause(source TableRef) *UnpivotTable {
+	unpivot := &UnpivotTable{Source: source}
+	defer p.setSpan(unpivot, source.NodeSpan().Start)
+	p.expect(TOKEN_UNPIVOT)
+
+	if p.checkWord("INCLUDE") || p.checkWord("EXCLUDE") {
+		unpivot.IncludeNulls = p.checkWord("INCLUDE")
+		p.nextToken()
+		p.expect(TOKEN_NULLS)
+	}
+
+	p.expect(TOKEN_LPAREN)
+	if p.check(TOKEN_LPAREN) {
+		unpivot.Values = p.parseColumnList()
+	} else if p.check(TOKEN_IDENT) {
+		unpivot.Values = []string{p.token.Literal}
+		p.nextToken()
+	} else {
+		p.addError("expected value column in UNPIVOT")
+	}
+
+	p.expectWord("FOR")
+	if p.check(TOKEN_IDENT) {
+		unpivot.Name = p.token.Literal
+		p.nextToken()
+	} else {
+		p.addError("expected name column in UNPIVOT")
+	}
+
+	p.expect(TOKEN_IN)
+	p.expect(TOKEN_LPAREN)
+	for {
+		unpivot.On = append(unpivot.On, p.parseAliasItem().Expr)
+		if !p.match(TOKEN_COMMA) {
+			break
+		}
+	}
+	p.expect(TOKEN_RPAREN)
+
+	p.expect(TOKEN_RPAREN)
+	unpivot.Alias = p.parsePivotAlias()
+	return unpivot
+}
+
+// parsePivotStatement parses DuckDB's PIVOT and UNPIVOT statements, which
+// select every column of the pivoted table.
+func (p *Parser) parsePivotStatement() *SelectCore {
+	start := p.token.Pos
+	var ref TableRef
+	if p.match(TOKEN_PIVOT) {
+		pivot := &PivotTable{Source: p.parseTableRef(), Statement: true}
+		p.expect(TOKEN_ON)
+		for {
+			colStart := p.token.Pos
+			col := PivotColumn{Expr: p.parseAddition()}
+			if p.match(TOKEN_IN) {
+				col.Values = p.parsePivotValues()
+			}
+			col.Span = p.spanFrom(colStart)
+			pivot.On = append(pivot.On, col)
+			if !p.match(TOKEN_COMMA) {
+				break
+			}
+		}
+		if p.match(TOKEN_USING) {
+			for {
+				pivot.Aggregates = append(pivot.Aggregates, p.parseAliasItem())
+				if !p.match(TOKEN_COMMA) {
+					break
+				}
+			}
+		}
+		if p.match(TOKEN_GROUP) {
+			p.expect(TOKEN_BY)
+			pivot.GroupBy = p.parseExpressionList()
+		}
+		p.setSpan(pivot, start)
+		ref = pivot
+	} else {
+		p.expect(TOKEN_UNPIVOT)
+		unpivot := &UnpivotTable{Source: p.parseTableRef(), Statement: true}
+		p.expect(TOKEN_ON)
+		unpivot.On = p.parseExpressionList()
+		p.expectWord("INTO")
+		p.expectWord("NAME")
+		if p.check(TOKEN_IDENT) {
+			unpivot.Name = p.token.Literal
+			p.nextToken()
+		} else {
+			p.addError("expected name column in UNPIVOT")
+		}
+		p.expectWord("VALUE")
+		for p.check(TOKEN_IDENT) {
+			unpivot.Values = append(unpivot.Values, p.token.Literal)
+			p.nextToken()
+			if !p.match(TOKEN_COMMA) {
+				break
+			}
+		}
+		if len(unpivot.Values) == 0 {
+			p.addError("expected value column in UNPIVOT")
+		}
+		p.setSpan(unpivot, start)
+		ref = unpivot
+	}
+
+	// The implied SELECT * FROM spans the pivot
+	span := ref.NodeSpan()
+	core := &SelectCore{
+		Columns: []SelectItem{{Span: span, Star: true}},
+		From:    &FromClause{Span: span, Source: ref},
+	}
+	defer p.setSpan(core, start)
+	if p.match(TOKEN_ORDER) {
+		p.expect(TOKEN_BY)
+		core.OrderBy = p.parseOrderByList()
+	}
+	if p.match(TOKEN_LIMIT) {
+		core.Limit = p.parseExpression()
+		if p.match(TOKEN_OFFSET) {
+			core.Offset = p.parseExpression()
+		}
+	}
+	return core
+}
+
+// parsePivotValues parses the IN list of a pivot column. Values chosen at run
+// time, IN (ANY) or IN (SELECT ...), return nil.
+func (p *Parser) parsePivotValues() []SelectItem {
+	p.expect(TOKEN_LPAREN)
+
+	if p.check(TOKEN_SELECT) || p.check(TOKEN_WITH) {
+		p.parseStatement()
+		p.expect(TOKEN_RPAREN)
+		return nil
+	}
+	if p.checkWord("ANY") {
+		p.nextToken()
+		if p.match(TOKEN_ORDER) {
+			p.expect(TOKEN_BY)
+			p.parseOrderByList()
+		}
+		p.expect(TOKEN_RPAREN)
+		return nil
+	}
+
+	var values []SelectItem
+	for {
+		values = append(values, p.parseAliasItem())
+		if !p.match(TOKEN_COMMA) {
+			break
+		}
+	}
+	p.expect(TOKEN_RPAREN)
+	return values
+}
+
+// parseAliasItem parses an expression with an optional alias. FOR is never
+// an alias, since it follows the aggregates of a PIVOT.
+func (p *Parser) parseAliasItem() SelectItem {
+	start := p.token.Pos
+	item := SelectItem{Expr: p.parseExpression()}
+	if p.match(TOKEN_AS) {
+		if p.check(TOKEN_IDENT) || p.check(TOKEN_STRING) {
+			item.Alias = p.token.Literal
+			p.nextToken()
+		} else {
+			p.addError("expected alias after AS")
+		}
+	} else if p.check(TOKEN_IDENT) && !p.checkWord("FOR") {
+		item.Alias = p.token.Literal
+		p.nextToken()
+	}
+	item.Span = p.spanFrom(start)
+	return item
+}
+
+// parsePivotAlias parses the optional alias after a PIVOT or UNPIVOT clause.
+func (p *Parser) parsePivotAlias() string {
+	if p.match(TOKEN_AS) {
+		if p.check(TOKEN_IDENT) {
+			alias := p.token.Literal
+			p.nextToken()
+			return alias
+		}
+		p.addError("expected alias after AS")
+		return ""
+	}
+	if p.check(TOKEN_IDENT) && !p.isJoinKeyword(p.token) && !p.isClauseKeyword(p.token) {
+		alias := p.token.Literal
+		p.nextToken()
+		return alias
+	}
+	return ""
+}
+
+// checkWord returns true if the current token is an identifier used as a
+// contextual keyword, such as FOR or INTO.
+func (p *Parser) checkWord(word string) bool {
+	return p.check(TOKEN_IDENT) && strings.EqualFold(p.token.Literal, word)
+}
+
+// expectWord consumes a contextual keyword, otherwise adds an error.
+func (p *Parser) expectWord(word string) bool {
+	if p.checkWord(word) {
+		p.nextToken()
+		return true
+	}
+	p.addError("expected " + word)
+	return false
+}
@@ -9,13 +9,20 @@ import (
 //
 // Grammar:
 //
-//	primary       → literal | column_ref | func_call | paren_expr | case_expr | cast_expr | exists_expr
+//	primary       → literal | column_ref | func_call | paren_expr | case_expr | cast_expr | exists_expr | list | struct
 //	literal       → NUMBER | STRING | TRUE | FALSE | NULL
-//	column_ref    → [table "."] column | [schema "." table "."] column
-//	func_call     → identifier "(" [DISTINCT] [expr_list | "*"] ")" [FILTER "(" WHERE expr ")"] [OVER window_spec]
+//	list          → "[" [expr_list] "]"
+//	struct        → "{" [struct_field ("," struct_field)*] "}"
+//	struct_field  → (identifier | STRING) ":" expr
+//	column_ref    → [table "."] column | [schema "." table "."] column | column_ref "." field
+//	func_call     → identifier "(" [DISTINCT] [func_arg ("," func_arg)* | "*"] ")" [FILTER "(" WHERE expr ")"] [OVER window_spec]
+//	func_arg      → identifier "=>" expr | lambda | expr
+//	lambda        → (identifier | "(" ident_list ")") "->" expr
 
 // parsePrimary parses primary expressions.
-func (p *Parser) parsePrimary() Expr {
+func (p *Parser) parsePrimary() (expr Expr) {
+	defer func(start Position) { p.setSpan(expr, start) }(p.token.Pos)
+
 	switch p.token.Type {
 	case TOKEN_NUMBER:
 		lit := &Literal{Type: LiteralNumber, Value: p.token.Literal}
@@ -65,6 +72,21 @@ func (p *Parser) parsePrimary() Expr {
 	case TOKEN_LPAREN:
 		return p.parseParenExpr()
 
+	case TOKEN_LBRACKET:
+		return p.parseListExpr()
+
+	case TOKEN_LBRACE:
+		return p.parseStructExpr()
+
+	case TOKEN_RANGE:
+		// range(start, stop) is a function outside window frames
+		if p.checkPeek(TOKEN_LPAREN) {
+			return p.parseIdentifierExpr()
+		}
+		p.addError(fmt.Sprintf("unexpected token in expression: %s", p.token.Type))
+		p.nextToken()
+		return nil
+
 	case TOKEN_STAR:
 		// SELECT * context
 		p.nextToken()
@@ -72,11 +94,51 @@ func (p *Parser) parsePrimary() Expr {
 
 	default:
 		p.addError(fmt.Sprintf("unexpected token in expression: %s", p.token.Type))
-		p.nextToken()
+		// Separators and clause starts are left for the parser to resume at
+		if !p.check(TOKEN_COMMA) && !p.check(TOKEN_RPAREN) && !p.check(TOKEN_SEMI) && !p.check(TOKEN_EOF) &&
+			!p.check(TOKEN_FROM) && !p.isClauseKeyword(p.token) {
+			p.nextToken()
+		}
 		return nil
 	}
 }
 
+// parseListExpr parses a list literal.
+func (p *Parser) parseListExpr() Expr {
+	p.expect(TOKEN_LBRACKET)
+	list := &ListExpr{}
+	if !p.check(TOKEN_RBRACKET) {
+		list.Elements = p.parseExpressionList()
+	}
+	p.expect(TOKEN_RBRACKET)
+	return list
+}
+
+// parseStructExpr parses a struct literal: {'a': 1, b: x}.
+func (p *Parser) parseStructExpr() Expr {
+	p.expect(TOKEN_LBRACE)
+	st := &StructExpr{}
+	for !p.check(TOKEN_RBRACE) {
+		if !p.check(TOKEN_IDENT) && !p.check(TOKEN_STRING) {
+			p.addError("expected struct field name")
+			return st
+		}
+		start := p.token.Pos
+		field := StructField{Name: p.token.Literal}
+		p.nextToken()
+		p.expect(TOKEN_COLON)
+		field.Value = p.parseExpression()
+		field.Span = p.spanFrom(start)
+		st.Fields = append(st.Fields, field)
+
+		if !p.match(TOKEN_COMMA) {
+			break
+		}
+	}
+	p.expect(TOKEN_RBRACE)
+	return st
+}
+
 // parseIdentifierExpr parses an identifier which could be a column ref or function call.
 func (p *Parser) parseIdentifierExpr() Expr {
 	name := p.token.Literal
@@ -107,14 +169,19 @@ func (p *Parser) parseQualifiedColumnRef(firstPart string) Expr {
 			return &StarExpr{Table: firstPart}
 		}
 
-		if p.check(TOKEN_IDENT) {
+		// Keywords are column names after a qualifier: r.range
+		if _, isKeyword := keywords[strings.ToLower(p.token.Literal)]; p.check(TOKEN_IDENT) || isKeyword {
 			parts = append(parts, p.token.Literal)
 			p.nextToken()
 		}
 	}
 
-	// Build column reference
+	// Build column reference. With more than two parts the qualifier may be a
+	// schema or a struct column, which only scope resolution can tell apart.
 	ref := &ColumnRef{}
+	if len(parts) > 2 {
+		ref.Path = parts
+	}
 	switch len(parts) {
 	case 2:
 		ref.Table = parts[0]
@@ -148,8 +215,7 @@ func (p *Parser) parseFuncCall(name string) Expr {
 
 		// Parse arguments
 		for {
-			arg := p.parseExpression()
-			fn.Args = append(fn.Args, arg)
+			fn.Args = append(fn.Args, p.parseFuncArg())
 
 			if !p.match(TOKEN_COMMA) {
 				break
@@ -174,3 +240,43 @@ func (p *Parser) parseFuncCall(name string) Expr {
 
 	return fn
 }
+
+// parseFuncArg parses a function argument, which may be named (name => expr)
+// or a lambda (x -> x + 1).
+func (p *Parser) parseFuncArg() Expr {
+	start := p.token.Pos
+	if p.check(TOKEN_IDENT) && p.checkPeek(TOKEN_ARROW) {
+		name := p.token.Literal
+		p.nextToken()
+		p.nextToken()
+		return p.spanned(&NamedArg{Name: name, Value: p.parseExpression()}, start)
+	}
+
+	// Lambda with several parameters: (a, b) -> a + b
+	if p.check(TOKEN_LPAREN) && p.checkPeek(TOKEN_IDENT) && p.checkPeek2(TOKEN_COMMA) {
+		params := p.parseColumnList()
+		p.expect(TOKEN_RARROW)
+		return p.spanned(&LambdaExpr{Params: params, Body: p.parseExpression()}, start)
+	}
+
+	// Lambda with one parameter: x -> ..., (x) -> ...
+	if p.check(TOKEN_IDENT) && p.checkPeek(TOKEN_RARROW) {
+		param := p.token.Literal
+		p.nextToken()
+		p.nextToken()
+		return p.spanned(&LambdaExpr{Params: []string{param}, Body: p.parseExpression()}, start)
+	}
+	expr := p.parseExpression()
+	if p.match(TOKEN_RARROW) {
+		if paren, ok := expr.(*ParenExpr); ok {
+			expr = paren.Expr
+		}
+		ref, ok := expr.(*ColumnRef)
+		if !ok || ref.Table != "" {
+			p.addError("expected lambda parameter before '->'")
+			return expr
+		}
+		return p.spanned(&LambdaExpr{Params: []string{ref.Column}, Body: p.parseExpression()}, start)
+	}
+	return expr
+}
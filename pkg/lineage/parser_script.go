@@ -0,0 +1,428 @@
+package lineage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Script parsing: semicolon-separated statements, including DDL and DML.
+//
+// Grammar:
+//
+//	script        → [statement_any] (";" [statement_any])*
+//	statement_any → statement | create_stmt | [with_clause] (insert_stmt | update_stmt | delete_stmt)
+//	                | merge_stmt
+//	create_stmt   → CREATE [OR REPLACE] [TEMP | TEMPORARY | TRANSIENT] [MATERIALIZED] (TABLE | VIEW)
+//	                [IF NOT EXISTS] object_name ["(" column_defs ")"] [AS query]
+//	insert_stmt   → INSERT [OR (REPLACE | IGNORE)] [OVERWRITE] [INTO] object_name ["(" ident_list ")"]
+//	                [BY (NAME | POSITION)] (query | VALUES row ("," row)* | DEFAULT VALUES)
+//	update_stmt   → UPDATE object_name [[AS] identifier] SET assignment ("," assignment)*
+//	                [FROM from_clause] [WHERE expr]
+//	delete_stmt   → DELETE [FROM] table_name [USING from_clause] [WHERE expr]
+//	merge_stmt    → MERGE [INTO] table_name USING table_ref ON expr merge_clause+
+//	merge_clause  → WHEN [NOT] MATCHED [BY (TARGET | SOURCE)] [AND expr] THEN merge_action
+//	merge_action  → UPDATE SET (assignment ("," assignment)* | "*") | DELETE | DO NOTHING
+//	                | INSERT ["(" ident_list ")"] (VALUES row | "*" | ROW)
+//	query         → statement | "(" statement ")"
+//	row           → "(" expr_list ")"
+//	assignment    → [table "."] identifier "=" expr
+//
+// DDL and DML words are contextual identifiers rather than keywords, so
+// columns and functions such as replace() or values keep working in queries.
+// Clauses that don't affect lineage (table options, ON CONFLICT, RETURNING)
+// are skipped, as are statements without lineage such as SET or COPY.
+
+// ParseScript parses a script of semicolon-separated statements using the
+// default dialect.
+func ParseScript(sql string) ([]Statement, error) {
+	return ParseScriptWithDialect(sql, nil)
+}
+
+// ParseScriptWithDialect parses a script of semicolon-separated statements
+// using a dialect's syntax. On syntax errors, the error is a ParseErrors listing
+// the errors of every statement.
+func ParseScriptWithDialect(sql string, dialect *Dialect) ([]Statement, error) {
+	p := NewParserWithDialect(sql, dialect)
+	stmts := p.parseScript()
+	if len(p.errors) > 0 {
+		return nil, p.errors
+	}
+	return stmts, nil
+}
+
+// parseScript parses statements until EOF. After a syntax error it skips to
+// the next statement, so errors in later statements are reported too.
+func (p *Parser) parseScript() []Statement {
+	var stmts []Statement
+	for {
+		for p.match(TOKEN_SEMI) {
+		}
+		if p.check(TOKEN_EOF) {
+			return stmts
+		}
+
+		start := p.token.Pos
+		stmt := p.parseScriptStatement()
+		p.setSpan(stmt, start)
+		stmts = append(stmts, stmt)
+
+		if !p.check(TOKEN_EOF) && !p.check(TOKEN_SEMI) {
+			p.addError(fmt.Sprintf(ErrUnexpectedToken, p.token.Type, TOKEN_SEMI))
+		}
+		if p.recovering {
+			// Resume reporting errors at the next statement
+			p.skipStatement()
+			p.recovering = false
+		}
+	}
+}
+
+// parseScriptStatement parses a single statement of a script.
+func (p *Parser) parseScriptStatement() Statement {
+	switch {
+	case p.check(TOKEN_WITH):
+		return p.parseWithStatement()
+	case p.check(TOKEN_SELECT), p.check(TOKEN_PIVOT), p.check(TOKEN_UNPIVOT):
+		return p.parseStatement()
+	case p.checkWord("CREATE"):
+		return p.parseCreate()
+	case p.checkWord("INSERT"):
+		return p.parseInsert()
+	case p.checkWord("UPDATE"):
+		return p.parseUpdate()
+	case p.checkWord("DELETE"):
+		return p.parseDelete()
+	case p.checkWord("MERGE"):
+		return p.parseMerge()
+	}
+
+	stmt := &UnsupportedStmt{Keyword: strings.ToUpper(p.token.Literal)}
+	p.skipStatement()
+	return stmt
+}
+
+// parseWithStatement parses a statement that starts with a WITH clause: a
+// query, or an INSERT, UPDATE or DELETE that reads the CTEs.
+func (p *Parser) parseWithStatement() Statement {
+	with := p.parseWithClause()
+	switch {
+	case p.checkWord("INSERT"):
+		stmt := p.parseInsert()
+		stmt.With = with
+		return stmt
+	case p.checkWord("UPDATE"):
+		stmt := p.parseUpdate()
+		stmt.With = with
+		return stmt
+	case p.checkWord("DELETE"):
+		stmt := p.parseDelete()
+		stmt.With = with
+		return stmt
+	}
+	return &SelectStmt{With: with, Body: p.parseSelectBody()}
+}
+
+// skipStatement skips tokens up to the end of the current statement.
+func (p *Parser) skipStatement() {
+	for !p.check(TOKEN_SEMI) && !p.check(TOKEN_EOF) {
+		p.nextToken()
+	}
+}
+
+// parseCreate parses CREATE TABLE and CREATE VIEW.
+func (p *Parser) parseCreate() *CreateStmt {
+	p.expectWord("CREATE")
+	stmt := &CreateStmt{}
+
+	if p.match(TOKEN_OR) {
+		p.expectWord("REPLACE")
+		stmt.OrReplace = true
+	}
+	for p.checkWord("TEMP") || p.checkWord("TEMPORARY") || p.checkWord("TRANSIENT") || p.checkWord("MATERIALIZED") {
+		p.nextToken()
+	}
+
+	switch {
+	case p.checkWord("TABLE"):
+		p.nextToken()
+	case p.checkWord("VIEW"):
+		p.nextToken()
+		stmt.View = true
+	default:
+		p.addError("expected TABLE or VIEW after CREATE")
+		return stmt
+	}
+
+	if p.checkWord("IF") {
+		p.nextToken()
+		p.expect(TOKEN_NOT)
+		p.expectWord("EXISTS")
+		stmt.IfNotExists = true
+	}
+
+	stmt.Target = p.parseObjectName()
+
+	if p.check(TOKEN_LPAREN) {
+		if p.checkPeek(TOKEN_IDENT) && (p.checkPeek2(TOKEN_COMMA) || p.checkPeek2(TOKEN_RPAREN)) {
+			stmt.Columns = p.parseColumnList()
+		} else {
+			// Column definitions: the table has no query to trace
+			p.skipParens()
+		}
+	}
+
+	// Table options such as PARTITION BY or COMMENT = '...' come before AS
+	for !p.check(TOKEN_AS) && !p.check(TOKEN_SEMI) && !p.check(TOKEN_EOF) {
+		p.nextToken()
+	}
+	if p.match(TOKEN_AS) {
+		stmt.Select = p.parseQuery()
+	}
+	return stmt
+}
+
+// parseInsert parses INSERT INTO ... SELECT and INSERT INTO ... VALUES.
+func (p *Parser) parseInsert() *InsertStmt {
+	p.expectWord("INSERT")
+	stmt := &InsertStmt{}
+
+	if p.match(TOKEN_OR) {
+		if !p.checkWord("REPLACE") && !p.checkWord("IGNORE") {
+			p.addError("expected REPLACE or IGNORE after INSERT OR")
+			return stmt
+		}
+		p.nextToken()
+	}
+	if p.checkWord("OVERWRITE") {
+		p.nextToken()
+	}
+	if p.checkWord("INTO") {
+		p.nextToken()
+	}
+
+	stmt.Target = p.parseObjectName()
+
+	if p.check(TOKEN_LPAREN) && !p.checkPeek(TOKEN_SELECT) && !p.checkPeek(TOKEN_WITH) {
+		stmt.Columns = p.parseColumnList()
+	}
+	if p.match(TOKEN_BY) {
+		if !p.checkWord("NAME") && !p.checkWord("POSITION") {
+			p.addError("expected NAME or POSITION after BY")
+			return stmt
+		}
+		stmt.ByName = p.checkWord("NAME")
+		p.nextToken()
+	}
+
+	switch {
+	case p.checkWord("VALUES"):
+		p.nextToken()
+		for {
+			p.expect(TOKEN_LPAREN)
+			stmt.Values = append(stmt.Values, p.parseExpressionList())
+			p.expect(TOKEN_RPAREN)
+			if !p.match(TOKEN_COMMA) {
+				break
+			}
+		}
+	case p.checkWord("DEFAULT"):
+		p.nextToken()
+		p.expectWord("VALUES")
+	default:
+		stmt.Select = p.parseQuery()
+	}
+
+	// ON CONFLICT and RETURNING don't change what is written
+	if p.check(TOKEN_ON) || p.checkWord("RETURNING") {
+		p.skipStatement()
+	}
+	return stmt
+}
+
+// parseUpdate parses UPDATE ... SET ... [FROM ...] [WHERE ...].
+func (p *Parser) parseUpdate() *UpdateStmt {
+	p.expectWord("UPDATE")
+	stmt := &UpdateStmt{Target: p.parseObjectName()}
+
+	if p.match(TOKEN_AS) {
+		if p.check(TOKEN_IDENT) {
+			stmt.Target.Alias = p.token.Literal
+			p.nextToken()
+		}
+	} else if p.check(TOKEN_IDENT) && !p.checkWord("SET") {
+		stmt.Target.Alias = p.token.Literal
+		p.nextToken()
+	}
+
+	p.expectWord("SET")
+	stmt.Set = p.parseAssignments()
+
+	if p.match(TOKEN_FROM) {
+		stmt.From = p.parseFromClause()
+	}
+	if p.match(TOKEN_WHERE) {
+		stmt.Where = p.parseExpression()
+	}
+	if p.checkWord("RETURNING") {
+		p.skipStatement()
+	}
+	return stmt
+}
+
+// parseDelete parses DELETE FROM ... [USING ...] [WHERE ...].
+func (p *Parser) parseDelete() *DeleteStmt {
+	p.expectWord("DELETE")
+	p.match(TOKEN_FROM)
+	stmt := &DeleteStmt{Target: p.parseTableName()}
+
+	if p.match(TOKEN_USING) {
+		stmt.Using = p.parseFromClause()
+	}
+	if p.match(TOKEN_WHERE) {
+		stmt.Where = p.parseExpression()
+	}
+	if p.checkWord("RETURNING") {
+		p.skipStatement()
+	}
+	return stmt
+}
+
+// parseMerge parses MERGE INTO target USING source ON ... WHEN ... THEN ....
+func (p *Parser) parseMerge() *MergeStmt {
+	p.expectWord("MERGE")
+	if p.checkWord("INTO") {
+		p.nextToken()
+	}
+	stmt := &MergeStmt{Target: p.parseTableName()}
+
+	p.expect(TOKEN_USING)
+	stmt.Source = p.parseTableRef()
+	p.expect(TOKEN_ON)
+	stmt.On = p.parseExpression()
+
+	for p.check(TOKEN_WHEN) {
+		stmt.Clauses = append(stmt.Clauses, p.parseMergeClause())
+	}
+	if len(stmt.Clauses) == 0 {
+		p.addError("expected WHEN clause in MERGE")
+	}
+	return stmt
+}
+
+// parseMergeClause parses WHEN [NOT] MATCHED ... THEN action.
+func (p *Parser) parseMergeClause() MergeClause {
+	start := p.token.Pos
+	p.expect(TOKEN_WHEN)
+	clause := MergeClause{Matched: !p.match(TOKEN_NOT)}
+	p.expectWord("MATCHED")
+
+	if p.match(TOKEN_BY) {
+		if !p.checkWord("TARGET") && !p.checkWord("SOURCE") {
+			p.addError("expected TARGET or SOURCE after BY")
+			clause.Span = p.spanFrom(start)
+			return clause
+		}
+		clause.BySource = p.checkWord("SOURCE")
+		p.nextToken()
+	}
+	if p.match(TOKEN_AND) {
+		clause.Condition = p.parseExpression()
+	}
+	p.expect(TOKEN_THEN)
+
+	switch {
+	case p.checkWord("UPDATE"):
+		p.nextToken()
+		clause.Action = MergeUpdate
+		p.expectWord("SET")
+		if p.match(TOKEN_STAR) {
+			clause.Star = true
+		} else {
+			clause.Set = p.parseAssignments()
+		}
+
+	case p.checkWord("DELETE"):
+		p.nextToken()
+		clause.Action = MergeDelete
+
+	case p.checkWord("DO"):
+		p.nextToken()
+		p.expectWord("NOTHING")
+		clause.Action = MergeDoNothing
+
+	case p.checkWord("INSERT"):
+		p.nextToken()
+		clause.Action = MergeInsert
+		switch {
+		case p.match(TOKEN_STAR), p.match(TOKEN_ROW):
+			// INSERT * or BigQuery's INSERT ROW: every source column
+			clause.Star = true
+		default:
+			if p.check(TOKEN_LPAREN) {
+				clause.Columns = p.parseColumnList()
+			}
+			p.expectWord("VALUES")
+			p.expect(TOKEN_LPAREN)
+			clause.Values = p.parseExpressionList()
+			p.expect(TOKEN_RPAREN)
+		}
+
+	default:
+		p.addError("expected UPDATE, DELETE, INSERT or DO NOTHING in MERGE clause")
+	}
+	clause.Span = p.spanFrom(start)
+	return clause
+}
+
+// parseAssignments parses column = expr pairs of UPDATE SET.
+func (p *Parser) parseAssignments() []Assignment {
+	var assignments []Assignment
+	for {
+		if !p.check(TOKEN_IDENT) {
+			p.addError("expected column name in SET")
+			return assignments
+		}
+		start := p.token.Pos
+		column := p.token.Literal
+		p.nextToken()
+		// A qualified target column: SET t.col = ...
+		for p.match(TOKEN_DOT) {
+			if p.check(TOKEN_IDENT) {
+				column = p.token.Literal
+				p.nextToken()
+			}
+		}
+		p.expect(TOKEN_EQ)
+		value := p.parseExpression()
+		assignments = append(assignments, Assignment{Span: p.spanFrom(start), Column: column, Value: value})
+
+		if !p.match(TOKEN_COMMA) {
+			return assignments
+		}
+	}
+}
+
+// parseQuery parses a query that may be wrapped in parentheses.
+func (p *Parser) parseQuery() *SelectStmt {
+	if p.match(TOKEN_LPAREN) {
+		stmt := p.parseStatement()
+		p.expect(TOKEN_RPAREN)
+		return stmt
+	}
+	return p.parseStatement()
+}
+
+// skipParens skips a parenthesized token sequence, including nested parentheses.
+func (p *Parser) skipParens() {
+	p.expect(TOKEN_LPAREN)
+	depth := 1
+	for depth > 0 && !p.check(TOKEN_EOF) {
+		switch p.token.Type {
+		case TOKEN_LPAREN:
+			depth++
+		case TOKEN_RPAREN:
+			depth--
+		}
+		p.nextToken()
+	}
+}
@@ -21,11 +21,14 @@ func (p *Parser) parseCaseExpr() Expr {
 	}
 
 	// WHEN clauses
-	for p.match(TOKEN_WHEN) {
+	for p.check(TOKEN_WHEN) {
+		start := p.token.Pos
+		p.nextToken()
 		when := WhenClause{}
 		when.Condition = p.parseExpression()
 		p.expect(TOKEN_THEN)
 		when.Result = p.parseExpression()
+		when.Span = p.spanFrom(start)
 		caseExpr.Whens = append(caseExpr.Whens, when)
 	}
 
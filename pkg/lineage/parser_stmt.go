@@ -7,7 +7,7 @@ package lineage
 //	statement     → [WITH cte_list] select_body
 //	cte_list      → cte ("," cte)*
 //	cte           → identifier AS "(" statement ")"
-//	select_body   → select_core [(UNION|INTERSECT|EXCEPT) [ALL|DISTINCT] select_body]
+//	select_body   → (select_core | pivot_stmt | unpivot_stmt) [(UNION|INTERSECT|EXCEPT) [ALL|DISTINCT] select_body]
 //	select_core   → SELECT [DISTINCT|ALL] select_list
 //	                [FROM from_clause]
 //	                [WHERE expr]
@@ -24,6 +24,7 @@ package lineage
 // parseStatement parses a complete SQL statement.
 func (p *Parser) parseStatement() *SelectStmt {
 	stmt := &SelectStmt{}
+	defer p.setSpan(stmt, p.token.Pos)
 
 	// Optional WITH clause
 	if p.check(TOKEN_WITH) {
@@ -38,8 +39,9 @@ func (p *Parser) parseStatement() *SelectStmt {
 
 // parseWithClause parses a WITH clause with CTEs.
 func (p *Parser) parseWithClause() *WithClause {
-	p.expect(TOKEN_WITH)
 	with := &WithClause{}
+	defer p.setSpan(with, p.token.Pos)
+	p.expect(TOKEN_WITH)
 
 	// Optional RECURSIVE
 	if p.match(TOKEN_RECURSIVE) {
@@ -62,6 +64,7 @@ func (p *Parser) parseWithClause() *WithClause {
 // parseCTE parses a single CTE.
 func (p *Parser) parseCTE() *CTE {
 	cte := &CTE{}
+	defer p.setSpan(cte, p.token.Pos)
 
 	// CTE name
 	if !p.check(TOKEN_IDENT) {
@@ -85,7 +88,12 @@ func (p *Parser) parseCTE() *CTE {
 // parseSelectBody parses a SELECT body with possible set operations.
 func (p *Parser) parseSelectBody() *SelectBody {
 	body := &SelectBody{}
-	body.Left = p.parseSelectCore()
+	defer p.setSpan(body, p.token.Pos)
+	if p.check(TOKEN_PIVOT) || p.check(TOKEN_UNPIVOT) {
+		body.Left = p.parsePivotStatement()
+	} else {
+		body.Left = p.parseSelectCore()
+	}
 
 	// Check for set operations
 	if p.check(TOKEN_UNION) || p.check(TOKEN_INTERSECT) || p.check(TOKEN_EXCEPT) {
@@ -118,8 +126,9 @@ func (p *Parser) parseSelectBody() *SelectBody {
 
 // parseSelectCore parses a single SELECT clause.
 func (p *Parser) parseSelectCore() *SelectCore {
-	p.expect(TOKEN_SELECT)
 	core := &SelectCore{}
+	defer p.setSpan(core, p.token.Pos)
+	p.expect(TOKEN_SELECT)
 
 	// DISTINCT / ALL
 	if p.match(TOKEN_DISTINCT) {
@@ -132,37 +141,44 @@ func (p *Parser) parseSelectCore() *SelectCore {
 	p.inSelectList = true
 	core.Columns = p.parseSelectList()
 	p.inSelectList = false
+	p.synchronize()
 
 	// FROM clause (required for our use case)
 	if p.match(TOKEN_FROM) {
 		core.From = p.parseFromClause()
+		p.synchronize()
 	}
 
 	// WHERE clause
 	if p.match(TOKEN_WHERE) {
 		core.Where = p.parseExpression()
+		p.synchronize()
 	}
 
 	// GROUP BY clause
 	if p.match(TOKEN_GROUP) {
 		p.expect(TOKEN_BY)
 		core.GroupBy = p.parseExpressionList()
+		p.synchronize()
 	}
 
 	// HAVING clause
 	if p.match(TOKEN_HAVING) {
 		core.Having = p.parseExpression()
+		p.synchronize()
 	}
 
 	// QUALIFY clause (DuckDB/Snowflake)
 	if p.match(TOKEN_QUALIFY) {
 		core.Qualify = p.parseExpression()
+		p.synchronize()
 	}
 
 	// ORDER BY clause
 	if p.match(TOKEN_ORDER) {
 		p.expect(TOKEN_BY)
 		core.OrderBy = p.parseOrderByList()
+		p.synchronize()
 	}
 
 	// LIMIT clause
@@ -185,6 +201,7 @@ func (p *Parser) parseSelectList() []SelectItem {
 	for {
 		item := p.parseSelectItem()
 		items = append(items, item)
+		p.synchronize(TOKEN_COMMA)
 
 		if !p.match(TOKEN_COMMA) {
 			break
@@ -196,12 +213,14 @@ func (p *Parser) parseSelectList() []SelectItem {
 
 // parseSelectItem parses a single SELECT item.
 func (p *Parser) parseSelectItem() SelectItem {
+	start := p.token.Pos
 	item := SelectItem{}
 
 	// Check for * or table.*
 	if p.check(TOKEN_STAR) {
 		item.Star = true
 		p.nextToken()
+		item.Span = p.spanFrom(start)
 		return item
 	}
 
@@ -212,6 +231,7 @@ func (p *Parser) parseSelectItem() SelectItem {
 		p.nextToken() // consume DOT
 		p.nextToken() // consume STAR
 		item.TableStar = tableName
+		item.Span = p.spanFrom(start)
 		return item
 	}
 
@@ -232,6 +252,7 @@ func (p *Parser) parseSelectItem() SelectItem {
 		p.nextToken()
 	}
 
+	item.Span = p.spanFrom(start)
 	return item
 }
 
@@ -253,6 +274,7 @@ func (p *Parser) parseOrderByList() []OrderByItem {
 
 // parseOrderByItem parses a single ORDER BY item.
 func (p *Parser) parseOrderByItem() OrderByItem {
+	start := p.token.Pos
 	item := OrderByItem{}
 	item.Expr = p.parseExpression()
 
@@ -274,6 +296,7 @@ func (p *Parser) parseOrderByItem() OrderByItem {
 		}
 	}
 
+	item.Span = p.spanFrom(start)
 	return item
 }
 
@@ -12,6 +12,7 @@ package lineage
 // parseWindowSpec parses a window specification.
 func (p *Parser) parseWindowSpec() *WindowSpec {
 	spec := &WindowSpec{}
+	defer p.setSpan(spec, p.token.Pos)
 
 	// Named window reference
 	if p.check(TOKEN_IDENT) {
@@ -46,6 +47,7 @@ func (p *Parser) parseWindowSpec() *WindowSpec {
 // parseFrameSpec parses a window frame specification.
 func (p *Parser) parseFrameSpec() *FrameSpec {
 	frame := &FrameSpec{}
+	defer p.setSpan(frame, p.token.Pos)
 
 	// Frame type
 	switch {
@@ -73,6 +75,7 @@ func (p *Parser) parseFrameSpec() *FrameSpec {
 // parseFrameBound parses a frame bound.
 func (p *Parser) parseFrameBound() *FrameBound {
 	bound := &FrameBound{}
+	defer p.setSpan(bound, p.token.Pos)
 
 	switch {
 	case p.match(TOKEN_UNBOUNDED):
@@ -0,0 +1,1665 @@
+package lineage
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Printing: SQL from an AST, laid out to a line width.
+//
+// Each construct that can span several lines is a group: it is written on
+// one line when that fits and no comment falls inside it, and otherwise
+// breaks at its own break points, leaving nested groups to decide for
+// themselves. Line breaks name the source offset of the token that follows
+// them, so comments from the source (see Format) are written before the
+// first line break past them.
+
+// KeywordCase is the letter case keywords are printed in.
+type KeywordCase string
+
+const (
+	KeywordUpper KeywordCase = "upper"
+	KeywordLower KeywordCase = "lower"
+)
+
+// CommaStyle places the commas of lists broken over several lines.
+type CommaStyle string
+
+const (
+	CommaTrailing CommaStyle = "trailing" // a,\n b
+	CommaLeading  CommaStyle = "leading"  // a\n, b
+)
+
+// PrintOptions control how SQL is laid out. The zero value prints upper-case
+// keywords and trailing commas, indents by 4 spaces and breaks lines longer
+// than 80 characters, in the default dialect.
+type PrintOptions struct {
+	Dialect     *Dialect    // identifier quoting and string escapes
+	KeywordCase KeywordCase // also applies to function and type names
+	Indent      int         // spaces per level
+	CommaStyle  CommaStyle
+	LineWidth   int
+}
+
+// Print returns the SQL of an AST node: a statement, expression, table
+// reference or clause. Names are quoted only where the dialect requires it;
+// Format keeps the quoting and comments of the source.
+func Print(node Node, opts PrintOptions) string {
+	p := newPrinter(opts)
+	p.node(node)
+	return string(p.out)
+}
+
+// printer writes SQL for AST nodes.
+type printer struct {
+	opts    PrintOptions
+	dialect *Dialect
+	out     []byte
+	col     int  // width of the current line
+	indent  int  // indentation level
+	flat    bool // measuring a one-line layout: line breaks are spaces
+
+	// The source being formatted, if any (see Format)
+	source           string
+	tokens           []Token
+	comments         []comment
+	next             int             // first comment not yet written
+	quoted           map[string]bool // names quoted in the source
+	afterLineComment bool            // a line comment ends the current line
+
+	// rename maps function names to those of another dialect (see Transpile)
+	rename func(name string) string
+}
+
+// newPrinter returns a printer with defaults filled in.
+func newPrinter(opts PrintOptions) *printer {
+	if opts.Dialect == nil {
+		opts.Dialect = DefaultDialect()
+	}
+	if opts.KeywordCase == "" {
+		opts.KeywordCase = KeywordUpper
+	}
+	if opts.Indent <= 0 {
+		opts.Indent = 4
+	}
+	if opts.CommaStyle == "" {
+		opts.CommaStyle = CommaTrailing
+	}
+	if opts.LineWidth <= 0 {
+		opts.LineWidth = 80
+	}
+	return &printer{opts: opts, dialect: opts.Dialect}
+}
+
+// ---------- Layout ----------
+
+// write writes text to the current line.
+func (p *printer) write(s string) {
+	if p.afterLineComment {
+		p.afterLineComment = false
+		p.breakLine()
+	}
+	p.out = append(p.out, s...)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.col += utf8.RuneCountInString(s)
+	}
+}
+
+// keyword writes a keyword, given in upper case.
+func (p *printer) keyword(s string) {
+	p.write(p.kw(s))
+}
+
+// kw returns a keyword, function or type name in the configured case.
+func (p *printer) kw(s string) string {
+	if p.opts.KeywordCase == KeywordLower {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+// breakLine starts a new line at the current indentation.
+func (p *printer) breakLine() {
+	if len(p.out) == 0 {
+		return
+	}
+	for len(p.out) > 0 && p.out[len(p.out)-1] == ' ' {
+		p.out = p.out[:len(p.out)-1]
+	}
+	p.out = append(p.out, '\n')
+	p.col = p.indent * p.opts.Indent
+	p.out = append(p.out, strings.Repeat(" ", p.col)...)
+}
+
+// line breaks the line before the source token at offset anchor, writing
+// the comments that come before that token first. Laid out flat, it is a
+// space.
+func (p *printer) line(anchor int) {
+	if p.flat {
+		p.write(" ")
+		return
+	}
+	p.flushComments(anchor)
+	p.afterLineComment = false
+	p.breakLine()
+}
+
+// softline is a line break that is nothing when laid out flat.
+func (p *printer) softline(anchor int) {
+	if p.flat {
+		return
+	}
+	p.line(anchor)
+}
+
+// group writes fn on one line if it fits, with extra more characters to
+// follow, and no comment falls inside span; otherwise fn breaks lines.
+func (p *printer) group(span Span, extra int, fn func()) {
+	if p.flat {
+		fn()
+		return
+	}
+	s := p.measure(fn)
+	if !p.hasComments(span) && p.fits(s, extra) {
+		p.write(s)
+		return
+	}
+	fn()
+}
+
+// measure returns what fn writes laid out flat.
+func (p *printer) measure(fn func()) string {
+	out, col, after := p.out, p.col, p.afterLineComment
+	p.out, p.flat, p.afterLineComment = nil, true, false
+	fn()
+	s := string(p.out)
+	p.out, p.col, p.flat, p.afterLineComment = out, col, false, after
+	return s
+}
+
+// fits reports whether s and extra more characters fit on the current line.
+func (p *printer) fits(s string, extra int) bool {
+	if strings.ContainsRune(s, '\n') {
+		return false
+	}
+	return p.col+utf8.RuneCountInString(s)+extra <= p.opts.LineWidth
+}
+
+// lineEmpty reports whether nothing but indentation is on the current line.
+func (p *printer) lineEmpty() bool {
+	i := len(p.out) - 1
+	for i >= 0 && p.out[i] == ' ' {
+		i--
+	}
+	return i < 0 || p.out[i] == '\n'
+}
+
+// list writes n items separated by commas, breaking the line before each
+// item after the first; start returns the source offset of item i.
+func (p *printer) list(n int, start func(i int) int, item func(i int)) {
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			switch {
+			case p.flat:
+				p.write(", ")
+			case p.opts.CommaStyle == CommaLeading:
+				p.line(p.before(start(i), 1))
+				p.write(", ")
+			default:
+				p.write(",")
+				p.line(start(i))
+			}
+		}
+		item(i)
+	}
+}
+
+// block writes n items of a list on their own lines, indented, or on the
+// current line when laid out flat.
+func (p *printer) block(n int, start func(i int) int, item func(i int)) {
+	if p.flat {
+		p.write(" ")
+		p.list(n, start, item)
+		return
+	}
+	p.indent++
+	p.line(start(0))
+	p.list(n, start, item)
+	p.indent--
+}
+
+// ---------- Comments ----------
+
+// flushComments writes the comments before the source offset anchor, each on
+// its own line, or at the end of the current one if it followed code on its
+// source line.
+func (p *printer) flushComments(anchor int) {
+	for p.next < len(p.comments) && p.comments[p.next].Span.Start.Offset < anchor {
+		c := p.comments[p.next]
+		p.next++
+
+		if p.trailing(c) && !p.afterLineComment && !p.lineEmpty() {
+			p.write(" " + c.Text)
+		} else {
+			if !p.lineEmpty() || p.afterLineComment {
+				p.afterLineComment = false
+				p.breakLine()
+			}
+			if p.blankBefore(c.Span.Start.Offset) && len(p.out) > 0 {
+				p.breakLine()
+			}
+			p.write(c.Text)
+		}
+		p.afterLineComment = strings.HasPrefix(c.Text, "--")
+	}
+}
+
+// hasComments reports whether a comment not yet written falls inside span.
+func (p *printer) hasComments(span Span) bool {
+	i := sort.Search(len(p.comments), func(i int) bool {
+		return p.comments[i].Span.Start.Offset >= span.Start.Offset
+	})
+	i = max(i, p.next)
+	return i < len(p.comments) && p.comments[i].Span.Start.Offset < span.End.Offset
+}
+
+// trailing reports whether a comment follows a token on its source line.
+func (p *printer) trailing(c comment) bool {
+	i := p.tokenIndex(c.Span.Start.Offset) - 1
+	return i >= 0 && p.tokens[i].End.Line == c.Span.Start.Line
+}
+
+// blankBefore reports whether an empty line precedes the source offset, past
+// the token or comment before it.
+func (p *printer) blankBefore(offset int) bool {
+	start := 0
+	if i := p.tokenIndex(offset) - 1; i >= 0 {
+		start = p.tokens[i].End.Offset
+	}
+	for _, c := range p.comments {
+		if end := c.Span.End.Offset; end <= offset && end > start {
+			start = end
+		}
+	}
+	gap := strings.NewReplacer(" ", "", "\t", "", "\r", "").Replace(p.source[start:offset])
+	return strings.Contains(gap, "\n\n")
+}
+
+// tokenIndex returns the index of the first source token at or after offset.
+func (p *printer) tokenIndex(offset int) int {
+	return sort.Search(len(p.tokens), func(i int) bool {
+		return p.tokens[i].Pos.Offset >= offset
+	})
+}
+
+// before returns the offset of the nth source token before offset, which
+// anchors keywords the AST doesn't record, such as WHERE.
+func (p *printer) before(offset, n int) int {
+	i := p.tokenIndex(offset) - n
+	if i < 0 || len(p.tokens) == 0 {
+		return -1
+	}
+	return p.tokens[i].Pos.Offset
+}
+
+// after returns the offset of the first source token at or after offset.
+func (p *printer) after(offset int) int {
+	i := p.tokenIndex(offset)
+	if i == len(p.tokens) {
+		return len(p.source)
+	}
+	return p.tokens[i].Pos.Offset
+}
+
+// last returns the offset of the last source token of span.
+func (p *printer) last(span Span) int {
+	return p.before(span.End.Offset, 1)
+}
+
+// ---------- Names and literals ----------
+
+// name returns an identifier, quoted if it was quoted in the source or
+// wouldn't read back as the same name otherwise. Keywords are names
+// unquoted after a dot.
+func (p *printer) name(name string, afterDot bool) string {
+	_, keyword := keywords[strings.ToLower(name)]
+	if !p.quoted[name] && plainIdent(name) && (afterDot || !keyword) {
+		return name
+	}
+	quote, end := p.dialect.Identifiers.Quote, p.dialect.Identifiers.QuoteEnd
+	if quote == "" {
+		quote = `"`
+	}
+	if end == "" {
+		end = quote
+	}
+	escape := p.dialect.Identifiers.Escape
+	if escape == "" {
+		escape = end + end
+	}
+	return quote + strings.ReplaceAll(name, end, escape) + end
+}
+
+// plainIdent reports whether name lexes as an unquoted identifier.
+func plainIdent(name string) bool {
+	if name == "" || isDigit(name[0]) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if ch := name[i]; !isLetter(ch) && !isDigit(ch) && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ident writes an identifier.
+func (p *printer) ident(name string) {
+	p.write(p.name(name, false))
+}
+
+// path writes a dotted name such as schema.table.
+func (p *printer) path(parts ...string) {
+	for i, part := range parts {
+		if i > 0 {
+			p.write(".")
+		}
+		p.write(p.name(part, i > 0))
+	}
+}
+
+// names writes a parenthesized list of names: (a, b).
+func (p *printer) names(names []string) {
+	p.write("(")
+	for i, name := range names {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.ident(name)
+	}
+	p.write(")")
+}
+
+// alias writes AS alias, if there is one.
+func (p *printer) alias(alias string) {
+	if alias != "" {
+		p.write(" " + p.kw("AS") + " " + p.name(alias, false))
+	}
+}
+
+// aliasWidth returns the width alias adds.
+func (p *printer) aliasWidth(alias string) int {
+	if alias == "" {
+		return 0
+	}
+	return utf8.RuneCountInString(" AS " + p.name(alias, false))
+}
+
+// fieldName returns the name of a struct field: a string, unless it was
+// written as an identifier in the source.
+func (p *printer) fieldName(field StructField) string {
+	if i := p.tokenIndex(field.Span.Start.Offset); i < len(p.tokens) && p.tokens[i].Type == TOKEN_IDENT {
+		return p.name(field.Name, false)
+	}
+	return p.quoteString(field.Name)
+}
+
+// quoteString returns a string literal with the dialect's escapes.
+func (p *printer) quoteString(s string) string {
+	if p.dialect.Syntax.BackslashEscapes {
+		s = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+	} else {
+		s = strings.ReplaceAll(s, "'", "''")
+	}
+	return "'" + s + "'"
+}
+
+// ---------- Nodes ----------
+
+// node writes any AST node.
+func (p *printer) node(node Node) {
+	switch n := node.(type) {
+	case Statement:
+		p.statement(n)
+	case Expr:
+		p.expr(n)
+	case TableRef:
+		p.tableRef(n)
+	case *WithClause:
+		p.with(n)
+	case *CTE:
+		p.cte(n)
+	case *SelectBody:
+		p.selectBody(n)
+	case *SelectCore:
+		p.selectCore(n)
+	case SelectItem:
+		p.selectItem(n)
+	case *SelectItem:
+		p.selectItem(*n)
+	case *FromClause:
+		p.from(n)
+	case *Join:
+		p.join(n)
+	case OrderByItem:
+		p.orderByItem(n)
+	case *OrderByItem:
+		p.orderByItem(*n)
+	case *WindowSpec:
+		p.window(n)
+	case *FrameSpec:
+		p.frame(n)
+	case WhenClause:
+		p.when(n)
+	case *WhenClause:
+		p.when(*n)
+	case MergeClause:
+		p.mergeClause(n)
+	case *MergeClause:
+		p.mergeClause(*n)
+	case Assignment:
+		p.assignment(n)
+	case *Assignment:
+		p.assignment(*n)
+	}
+}
+
+// statement writes a statement.
+func (p *printer) statement(stmt Statement) {
+	switch s := stmt.(type) {
+	case *SelectStmt:
+		p.selectStmt(s)
+	case *CreateStmt:
+		p.create(s)
+	case *InsertStmt:
+		p.insert(s)
+	case *UpdateStmt:
+		p.update(s)
+	case *DeleteStmt:
+		p.delete(s)
+	case *MergeStmt:
+		p.merge(s)
+	case *UnsupportedStmt:
+		p.unsupported(s)
+	}
+}
+
+// selectStmt writes a query.
+func (p *printer) selectStmt(s *SelectStmt) {
+	if s.With != nil {
+		p.with(s.With)
+		p.line(s.Body.Span.Start.Offset)
+	}
+	p.selectBody(s.Body)
+}
+
+// query writes a parenthesized query, with the query on its own lines.
+func (p *printer) query(s *SelectStmt) {
+	p.write("(")
+	p.indent++
+	p.softline(s.Span.Start.Offset)
+	p.selectStmt(s)
+	p.indent--
+	p.softline(p.after(s.Span.End.Offset))
+	p.write(")")
+}
+
+// with writes a WITH clause.
+func (p *printer) with(w *WithClause) {
+	p.keyword("WITH")
+	if w.Recursive {
+		p.write(" ")
+		p.keyword("RECURSIVE")
+	}
+	p.write(" ")
+	p.list(len(w.CTEs), func(i int) int { return w.CTEs[i].Span.Start.Offset }, func(i int) { p.cte(w.CTEs[i]) })
+}
+
+// cte writes a common table expression; its query always starts a new line.
+func (p *printer) cte(c *CTE) {
+	p.ident(c.Name)
+	p.write(" ")
+	p.keyword("AS")
+	p.write(" ")
+	p.query(c.Select)
+}
+
+// selectBody writes a SELECT with its set operations, each on its own line.
+func (p *printer) selectBody(b *SelectBody) {
+	p.selectCore(b.Left)
+	if b.Op == SetOpNone || b.Right == nil {
+		return
+	}
+	p.line(p.after(b.Left.Span.End.Offset))
+	op := string(b.Op)
+	if b.Op == SetOpUnion && b.All {
+		op = string(SetOpUnionAll)
+	}
+	p.keyword(op)
+	p.line(b.Right.Span.Start.Offset)
+	p.selectBody(b.Right)
+}
+
+// selectCore writes a SELECT, with each clause on its own line unless the
+// whole SELECT fits on one.
+func (p *printer) selectCore(c *SelectCore) {
+	if p.pivotStatement(c) {
+		return
+	}
+	p.group(c.Span, 0, func() {
+		p.keyword("SELECT")
+		if c.Distinct {
+			p.write(" ")
+			p.keyword("DISTINCT")
+		}
+		p.selectList(c.Columns)
+
+		if c.From != nil {
+			p.line(p.before(c.From.Span.Start.Offset, 1))
+			p.keyword("FROM")
+			p.write(" ")
+			p.from(c.From)
+		}
+		if c.Where != nil {
+			p.line(p.before(c.Where.NodeSpan().Start.Offset, 1))
+			p.condition("WHERE", c.Where)
+		}
+		if len(c.GroupBy) > 0 {
+			p.line(p.before(c.GroupBy[0].NodeSpan().Start.Offset, 2))
+			p.exprs("GROUP BY", c.GroupBy)
+		}
+		if c.Having != nil {
+			p.line(p.before(c.Having.NodeSpan().Start.Offset, 1))
+			p.condition("HAVING", c.Having)
+		}
+		if c.Qualify != nil {
+			p.line(p.before(c.Qualify.NodeSpan().Start.Offset, 1))
+			p.condition("QUALIFY", c.Qualify)
+		}
+		p.orderByLimit(c)
+	})
+}
+
+// orderByLimit writes the ORDER BY, LIMIT and OFFSET clauses of a SELECT.
+func (p *printer) orderByLimit(c *SelectCore) {
+	if len(c.OrderBy) > 0 {
+		p.line(p.before(c.OrderBy[0].Span.Start.Offset, 2))
+		p.orderBy(c.OrderBy)
+	}
+	if c.Limit != nil {
+		p.line(p.before(c.Limit.NodeSpan().Start.Offset, 1))
+		p.keyword("LIMIT")
+		p.write(" ")
+		p.expr(c.Limit)
+		if c.Offset != nil {
+			p.write(" ")
+			p.keyword("OFFSET")
+			p.write(" ")
+			p.expr(c.Offset)
+		}
+	}
+}
+
+// selectList writes the items of a SELECT: one per line, or a single item
+// on the SELECT line when it fits there.
+func (p *printer) selectList(items []SelectItem) {
+	start := func(i int) int { return items[i].Span.Start.Offset }
+	item := func(i int) { p.selectItem(items[i]) }
+	if len(items) == 0 {
+		return
+	}
+	if !p.flat && len(items) == 1 {
+		if s := p.measure(func() { item(0) }); p.fits(" "+s, 0) && !p.hasComments(items[0].Span) {
+			p.write(" " + s)
+			return
+		}
+	}
+	p.block(len(items), start, item)
+}
+
+// selectItem writes an item of a SELECT list.
+func (p *printer) selectItem(item SelectItem) {
+	switch {
+	case item.Star:
+		p.write("*")
+	case item.TableStar != "":
+		p.ident(item.TableStar)
+		p.write(".*")
+	default:
+		p.exprFit(item.Expr, p.aliasWidth(item.Alias)+1)
+		p.alias(item.Alias)
+	}
+}
+
+// condition writes a clause keyword and its condition: on one line if it
+// fits, otherwise below it, with each operand of a top-level AND or OR on
+// its own line.
+func (p *printer) condition(keyword string, cond Expr) {
+	p.keyword(keyword)
+	p.group(cond.NodeSpan(), 0, func() {
+		op, operands := logicalOperands(cond)
+		if p.flat || operands == nil {
+			p.write(" ")
+			p.expr(cond)
+			return
+		}
+		p.indent++
+		p.line(cond.NodeSpan().Start.Offset)
+		p.chain(op, operands)
+		p.indent--
+	})
+}
+
+// exprs writes a clause keyword and a list of expressions, as in GROUP BY.
+func (p *printer) exprs(keyword string, exprs []Expr) {
+	p.keyword(keyword)
+	span := Span{Start: exprs[0].NodeSpan().Start, End: exprs[len(exprs)-1].NodeSpan().End}
+	p.group(span, 0, func() {
+		p.block(len(exprs), func(i int) int { return exprs[i].NodeSpan().Start.Offset }, func(i int) { p.expr(exprs[i]) })
+	})
+}
+
+// orderBy writes an ORDER BY clause.
+func (p *printer) orderBy(items []OrderByItem) {
+	p.keyword("ORDER BY")
+	span := Span{Start: items[0].Span.Start, End: items[len(items)-1].Span.End}
+	p.group(span, 0, func() {
+		p.block(len(items), func(i int) int { return items[i].Span.Start.Offset }, func(i int) { p.orderByItem(items[i]) })
+	})
+}
+
+// orderByItem writes an ORDER BY item.
+func (p *printer) orderByItem(item OrderByItem) {
+	p.expr(item.Expr)
+	if item.Desc {
+		p.write(" ")
+		p.keyword("DESC")
+	}
+	if item.NullsFirst != nil {
+		p.write(" ")
+		if *item.NullsFirst {
+			p.keyword("NULLS FIRST")
+		} else {
+			p.keyword("NULLS LAST")
+		}
+	}
+}
+
+// ---------- FROM ----------
+
+// from writes the table references of a FROM clause, each JOIN on its own
+// line.
+func (p *printer) from(f *FromClause) {
+	p.tableRef(f.Source)
+	for _, j := range f.Joins {
+		if j.Type == JoinComma {
+			p.write(", ")
+			p.tableRef(j.Right)
+			continue
+		}
+		p.line(j.Span.Start.Offset)
+		p.join(j)
+	}
+}
+
+// join writes a JOIN, with its ON condition on the next line if the JOIN
+// doesn't fit on one.
+func (p *printer) join(j *Join) {
+	if j.Type == JoinComma {
+		p.write(", ")
+		p.tableRef(j.Right)
+		return
+	}
+	p.group(j.Span, 0, func() {
+		if j.Natural {
+			p.keyword("NATURAL ")
+		}
+		if j.Type == JoinInner || j.Type == "" {
+			p.keyword("JOIN")
+		} else {
+			p.keyword(string(j.Type) + " JOIN")
+		}
+		p.write(" ")
+		p.tableRef(j.Right)
+
+		if j.Condition != nil {
+			p.indent++
+			p.line(p.before(j.Condition.NodeSpan().Start.Offset, 1))
+			p.keyword("ON")
+			p.write(" ")
+			if op, operands := logicalOperands(j.Condition); operands != nil {
+				p.chain(op, operands)
+			} else {
+				p.expr(j.Condition)
+			}
+			p.indent--
+		}
+		if len(j.Using) > 0 {
+			p.write(" ")
+			p.keyword("USING")
+			p.write(" ")
+			p.names(j.Using)
+		}
+	})
+}
+
+// tableRef writes a table reference.
+func (p *printer) tableRef(ref TableRef) {
+	switch t := ref.(type) {
+	case *TableName:
+		p.tableName(t)
+		p.alias(t.Alias)
+
+	case *DerivedTable:
+		p.group(t.Span, p.aliasWidth(t.Alias), func() { p.query(t.Select) })
+		p.alias(t.Alias)
+
+	case *LateralTable:
+		p.keyword("LATERAL")
+		p.write(" ")
+		p.group(t.Span, p.aliasWidth(t.Alias), func() { p.query(t.Select) })
+		p.alias(t.Alias)
+
+	case *TableFunction:
+		if t.Lateral {
+			p.keyword("LATERAL")
+			p.write(" ")
+		}
+		if t.Table {
+			p.keyword("TABLE")
+			p.write("(")
+		}
+		p.call(t.Name, false, t.Args, t.Span)
+		if t.Table {
+			p.write(")")
+		}
+		p.alias(t.Alias)
+		if len(t.Columns) > 0 {
+			p.names(t.Columns)
+		}
+
+	case *PivotTable:
+		if t.Statement {
+			p.write("(")
+			p.pivot(t)
+			p.write(")")
+			return
+		}
+		p.pivot(t)
+
+	case *UnpivotTable:
+		if t.Statement {
+			p.write("(")
+			p.unpivot(t)
+			p.write(")")
+			return
+		}
+		p.unpivot(t)
+	}
+}
+
+// tableName writes a possibly qualified table name.
+func (p *printer) tableName(t *TableName) {
+	var parts []string
+	for _, part := range []string{t.Catalog, t.Schema, t.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	// BigQuery's `project.dataset.table` stays one quoted name
+	if joined := strings.Join(parts, "."); p.dialect.Syntax.QuotedPaths && len(parts) > 1 && p.quoted[joined] {
+		p.ident(joined)
+		return
+	}
+	p.path(parts...)
+}
+
+// pivotStatement writes a SELECT that is a PIVOT or UNPIVOT statement in the
+// statement form, and reports whether it was one.
+func (p *printer) pivotStatement(c *SelectCore) bool {
+	if c.From == nil || len(c.From.Joins) > 0 || len(c.Columns) != 1 || !c.Columns[0].Star || c.Distinct ||
+		c.Where != nil || len(c.GroupBy) > 0 || c.Having != nil || c.Qualify != nil {
+		return false
+	}
+	switch t := c.From.Source.(type) {
+	case *PivotTable:
+		if !t.Statement {
+			return false
+		}
+		p.pivot(t)
+	case *UnpivotTable:
+		if !t.Statement {
+			return false
+		}
+		p.unpivot(t)
+	default:
+		return false
+	}
+	p.orderByLimit(c)
+	return true
+}
+
+// pivot writes a PIVOT, in the form it was written in.
+func (p *printer) pivot(t *PivotTable) {
+	aggregates := func() {
+		for i, item := range t.Aggregates {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.selectItem(item)
+		}
+	}
+	values := func(col PivotColumn) {
+		p.expr(col.Expr)
+		p.write(" ")
+		p.keyword("IN")
+		p.write(" (")
+		if col.Values == nil {
+			p.keyword("ANY")
+		}
+		for i, item := range col.Values {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.selectItem(item)
+		}
+		p.write(")")
+	}
+	groupBy := func() {
+		if len(t.GroupBy) > 0 {
+			p.write(" ")
+			p.keyword("GROUP BY")
+			p.write(" ")
+			p.exprList(t.GroupBy)
+		}
+	}
+
+	if t.Statement {
+		p.keyword("PIVOT")
+		p.write(" ")
+		p.tableRef(t.Source)
+		p.write(" ")
+		p.keyword("ON")
+		p.write(" ")
+		for i, col := range t.On {
+			if i > 0 {
+				p.write(", ")
+			}
+			if col.Values == nil {
+				p.expr(col.Expr)
+			} else {
+				values(col)
+			}
+		}
+		if len(t.Aggregates) > 0 {
+			p.write(" ")
+			p.keyword("USING")
+			p.write(" ")
+			aggregates()
+		}
+		groupBy()
+		return
+	}
+
+	p.tableRef(t.Source)
+	p.write(" ")
+	p.keyword("PIVOT")
+	p.write(" (")
+	aggregates()
+	p.write(" ")
+	p.keyword("FOR")
+	for _, col := range t.On {
+		p.write(" ")
+		values(col)
+	}
+	groupBy()
+	p.write(")")
+	p.alias(t.Alias)
+}
+
+// unpivot writes an UNPIVOT, in the form it was written in.
+func (p *printer) unpivot(t *UnpivotTable) {
+	if t.Statement {
+		p.keyword("UNPIVOT")
+		p.write(" ")
+		p.tableRef(t.Source)
+		p.write(" ")
+		p.keyword("ON")
+		p.write(" ")
+		p.exprList(t.On)
+		p.write(" ")
+		p.keyword("INTO NAME")
+		p.write(" ")
+		p.ident(t.Name)
+		p.write(" ")
+		p.keyword("VALUE")
+		p.write(" ")
+		for i, v := range t.Values {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.ident(v)
+		}
+		return
+	}
+
+	p.tableRef(t.Source)
+	p.write(" ")
+	p.keyword("UNPIVOT")
+	if t.IncludeNulls {
+		p.write(" ")
+		p.keyword("INCLUDE NULLS")
+	}
+	p.write(" (")
+	if len(t.Values) == 1 {
+		p.ident(t.Values[0])
+	} else {
+		p.names(t.Values)
+	}
+	p.write(" ")
+	p.keyword("FOR")
+	p.write(" ")
+	p.ident(t.Name)
+	p.write(" ")
+	p.keyword("IN")
+	p.write(" (")
+	p.exprList(t.On)
+	p.write("))")
+	p.alias(t.Alias)
+}
+
+// ---------- Expressions ----------
+
+// expr writes an expression.
+func (p *printer) expr(e Expr) {
+	p.exprFit(e, 0)
+}
+
+// exprFit writes an expression followed by extra more characters.
+func (p *printer) exprFit(e Expr, extra int) {
+	if e == nil {
+		return
+	}
+	p.group(e.NodeSpan(), extra, func() { p.exprBody(e) })
+}
+
+// exprList writes expressions separated by commas, on one line.
+func (p *printer) exprList(exprs []Expr) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(e)
+	}
+}
+
+// operand writes an expression inside another of precedence min,
+// parenthesized if it binds less tightly.
+func (p *printer) operand(e Expr, min int) {
+	if e != nil && precedence(e) < min {
+		p.write("(")
+		p.expr(e)
+		p.write(")")
+		return
+	}
+	p.expr(e)
+}
+
+// exprBody writes an expression, breaking lines at its own break points.
+func (p *printer) exprBody(e Expr) {
+	switch x := e.(type) {
+	case *ColumnRef:
+		if len(x.Path) > 0 {
+			p.path(x.Path...)
+		} else if x.Table != "" {
+			p.path(x.Table, x.Column)
+		} else if niladic[strings.ToUpper(x.Column)] && !p.quoted[x.Column] {
+			// CURRENT_DATE and the like, written without parentheses
+			p.keyword(x.Column)
+		} else {
+			p.ident(x.Column)
+		}
+
+	case *Literal:
+		switch x.Type {
+		case LiteralString:
+			p.write(p.quoteString(x.Value))
+		case LiteralBool, LiteralNull:
+			p.keyword(x.Value)
+		default:
+			p.write(x.Value)
+		}
+
+	case *BinaryExpr:
+		prec := precedence(x)
+		if op, operands := logicalOperands(x); operands != nil {
+			p.indent++
+			p.chain(op, operands)
+			p.indent--
+			return
+		}
+		p.operand(x.Left, prec)
+		p.write(" " + x.Op + " ")
+		p.operand(x.Right, prec+1)
+
+	case *UnaryExpr:
+		if x.Op == "NOT" {
+			p.keyword("NOT")
+			p.write(" ")
+			p.operand(x.Expr, precedence(x))
+			return
+		}
+		p.write(x.Op)
+		if _, ok := x.Expr.(*UnaryExpr); ok {
+			// - -x, not the comment --x
+			p.write(" ")
+		}
+		p.operand(x.Expr, precedence(x))
+
+	case *FuncCall:
+		switch {
+		case x.Star:
+			p.write(p.function(x.Name) + "(*)")
+		case p.rename != nil && len(x.Args) == 0 && niladic[strings.ToUpper(p.rename(x.Name))]:
+			p.write(p.function(x.Name))
+		default:
+			p.call(x.Name, x.Distinct, x.Args, x.Span)
+		}
+		if x.Filter != nil {
+			p.write(" ")
+			p.keyword("FILTER")
+			p.write(" (")
+			p.keyword("WHERE")
+			p.write(" ")
+			p.expr(x.Filter)
+			p.write(")")
+		}
+		if x.Window != nil {
+			p.write(" ")
+			p.keyword("OVER")
+			p.write(" ")
+			p.window(x.Window)
+		}
+
+	case *CaseExpr:
+		p.keyword("CASE")
+		if x.Operand != nil {
+			p.write(" ")
+			p.expr(x.Operand)
+		}
+		p.indent++
+		for _, when := range x.Whens {
+			p.line(when.Span.Start.Offset)
+			p.when(when)
+		}
+		if x.Else != nil {
+			p.line(p.before(x.Else.NodeSpan().Start.Offset, 1))
+			p.keyword("ELSE")
+			p.write(" ")
+			p.expr(x.Else)
+		}
+		p.indent--
+		p.line(p.last(x.Span))
+		p.keyword("END")
+
+	case *CastExpr:
+		if x.DoubleColon && p.dialect.Syntax.DoubleColonCast {
+			p.operand(x.Expr, precedence(x))
+			p.write("::" + p.kw(x.TypeName))
+			return
+		}
+		p.keyword("CAST")
+		p.write("(")
+		p.expr(x.Expr)
+		p.write(" ")
+		p.keyword("AS")
+		p.write(" " + p.kw(x.TypeName) + ")")
+
+	case *InExpr:
+		p.operand(x.Expr, precedence(x)+1)
+		p.write(" ")
+		if x.Not {
+			p.keyword("NOT ")
+		}
+		p.keyword("IN")
+		p.write(" ")
+		if x.Query != nil {
+			p.query(x.Query)
+		} else {
+			p.args("(", x.Values, ")", x.Span)
+		}
+
+	case *BetweenExpr:
+		p.operand(x.Expr, precedence(x)+1)
+		p.write(" ")
+		if x.Not {
+			p.keyword("NOT ")
+		}
+		p.keyword("BETWEEN")
+		p.write(" ")
+		p.operand(x.Low, precedence(x)+1)
+		p.write(" ")
+		p.keyword("AND")
+		p.write(" ")
+		p.operand(x.High, precedence(x)+1)
+
+	case *IsNullExpr:
+		p.operand(x.Expr, precedence(x)+1)
+		p.write(" ")
+		if x.Not {
+			p.keyword("IS NOT NULL")
+		} else {
+			p.keyword("IS NULL")
+		}
+
+	case *LikeExpr:
+		p.operand(x.Expr, precedence(x)+1)
+		p.write(" ")
+		if x.Not {
+			p.keyword("NOT ")
+		}
+		if x.ILike {
+			p.keyword("ILIKE")
+		} else {
+			p.keyword("LIKE")
+		}
+		p.write(" ")
+		p.operand(x.Pattern, precedence(x)+1)
+
+	case *ParenExpr:
+		op, operands := logicalOperands(x.Expr)
+		if operands == nil {
+			p.write("(")
+			p.expr(x.Expr)
+			p.write(")")
+			return
+		}
+		p.write("(")
+		p.indent++
+		p.softline(x.Expr.NodeSpan().Start.Offset)
+		p.chain(op, operands)
+		p.indent--
+		p.softline(p.last(x.Span))
+		p.write(")")
+
+	case *StarExpr:
+		if x.Table != "" {
+			p.ident(x.Table)
+			p.write(".")
+		}
+		p.write("*")
+
+	case *SubqueryExpr:
+		p.query(x.Select)
+
+	case *ExistsExpr:
+		if x.Not {
+			p.keyword("NOT ")
+		}
+		p.keyword("EXISTS")
+		p.write(" ")
+		p.query(x.Select)
+
+	case *ListExpr:
+		p.args("[", x.Elements, "]", x.Span)
+
+	case *IndexExpr:
+		p.operand(x.Expr, precedence(x))
+		p.write("[")
+		p.expr(x.Index)
+		if x.Slice {
+			p.write(":")
+			p.expr(x.End)
+		}
+		p.write("]")
+
+	case *FieldExpr:
+		p.operand(x.Expr, precedence(x))
+		p.write("." + p.name(x.Field, true))
+
+	case *StructExpr:
+		p.write("{")
+		for i, field := range x.Fields {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(p.fieldName(field) + ": ")
+			p.expr(field.Value)
+		}
+		p.write("}")
+
+	case *LambdaExpr:
+		if len(x.Params) == 1 {
+			p.ident(x.Params[0])
+		} else {
+			p.names(x.Params)
+		}
+		p.write(" -> ")
+		p.expr(x.Body)
+
+	case *NamedArg:
+		p.ident(x.Name)
+		p.write(" => ")
+		p.expr(x.Value)
+	}
+}
+
+// chain writes the operands of an AND or OR chain, breaking the line before
+// each operator.
+func (p *printer) chain(op string, operands []Expr) {
+	for i, operand := range operands {
+		if i > 0 {
+			p.line(p.before(operand.NodeSpan().Start.Offset, 1))
+			p.keyword(op)
+			p.write(" ")
+		}
+		p.operand(operand, precedence(&BinaryExpr{Op: op})+1)
+	}
+}
+
+// logicalOperands flattens a chain of ANDs, or of ORs, into its operands,
+// or returns nil if e is neither.
+func logicalOperands(e Expr) (string, []Expr) {
+	b, ok := e.(*BinaryExpr)
+	if !ok || (b.Op != "AND" && b.Op != "OR") {
+		return "", nil
+	}
+	var operands []Expr
+	var walk func(Expr)
+	walk = func(e Expr) {
+		if c, ok := e.(*BinaryExpr); ok && c.Op == b.Op {
+			walk(c.Left)
+			walk(c.Right)
+			return
+		}
+		operands = append(operands, e)
+	}
+	walk(b)
+	return b.Op, operands
+}
+
+// function returns the name of a function, as the dialect writes it.
+func (p *printer) function(name string) string {
+	if p.rename != nil {
+		name = p.rename(name)
+	}
+	return p.kw(name)
+}
+
+// call writes a function call's name and arguments.
+func (p *printer) call(name string, distinct bool, args []Expr, span Span) {
+	p.write(p.function(name))
+	if distinct {
+		p.write("(")
+		p.keyword("DISTINCT")
+		p.write(" ")
+		p.exprList(args)
+		p.write(")")
+		return
+	}
+	p.args("(", args, ")", span)
+}
+
+// args writes a bracketed argument list, with each argument on its own line
+// if they don't fit on one.
+func (p *printer) args(open string, args []Expr, close string, span Span) {
+	if len(args) == 0 {
+		p.write(open + close)
+		return
+	}
+	argSpan := Span{Start: args[0].NodeSpan().Start, End: args[len(args)-1].NodeSpan().End}
+	p.group(argSpan, len(close), func() {
+		p.write(open)
+		p.indent++
+		p.softline(argSpan.Start.Offset)
+		p.list(len(args), func(i int) int { return args[i].NodeSpan().Start.Offset }, func(i int) { p.expr(args[i]) })
+		p.indent--
+		p.softline(p.after(argSpan.End.Offset))
+		p.write(close)
+	})
+}
+
+// when writes a WHEN ... THEN ... clause of a CASE.
+func (p *printer) when(w WhenClause) {
+	p.keyword("WHEN")
+	p.write(" ")
+	p.expr(w.Condition)
+	p.write(" ")
+	p.keyword("THEN")
+	p.write(" ")
+	p.expr(w.Result)
+}
+
+// window writes a window specification: a name or a parenthesized
+// definition, with each part on its own line if it doesn't fit on one.
+func (p *printer) window(w *WindowSpec) {
+	if w.Name != "" {
+		p.ident(w.Name)
+		return
+	}
+	p.group(w.Span, 0, func() {
+		p.write("(")
+		p.indent++
+		first := true
+		part := func(anchor int) {
+			if first {
+				p.softline(anchor)
+			} else {
+				p.line(anchor)
+			}
+			first = false
+		}
+		if len(w.PartitionBy) > 0 {
+			part(p.before(w.PartitionBy[0].NodeSpan().Start.Offset, 2))
+			p.keyword("PARTITION BY")
+			p.write(" ")
+			p.exprList(w.PartitionBy)
+		}
+		if len(w.OrderBy) > 0 {
+			part(p.before(w.OrderBy[0].Span.Start.Offset, 2))
+			p.keyword("ORDER BY")
+			p.write(" ")
+			for i, item := range w.OrderBy {
+				if i > 0 {
+					p.write(", ")
+				}
+				p.orderByItem(item)
+			}
+		}
+		if w.Frame != nil {
+			part(w.Frame.Span.Start.Offset)
+			p.frame(w.Frame)
+		}
+		p.indent--
+		if !first {
+			p.softline(p.last(w.Span))
+		}
+		p.write(")")
+	})
+}
+
+// frame writes a window frame.
+func (p *printer) frame(f *FrameSpec) {
+	p.keyword(string(f.Type))
+	p.write(" ")
+	if f.End == nil {
+		p.frameBound(f.Start)
+		return
+	}
+	p.keyword("BETWEEN")
+	p.write(" ")
+	p.frameBound(f.Start)
+	p.write(" ")
+	p.keyword("AND")
+	p.write(" ")
+	p.frameBound(f.End)
+}
+
+// frameBound writes a window frame bound.
+func (p *printer) frameBound(b *FrameBound) {
+	switch b.Type {
+	case FrameExprPreceding:
+		p.expr(b.Offset)
+		p.write(" ")
+		p.keyword("PRECEDING")
+	case FrameExprFollowing:
+		p.expr(b.Offset)
+		p.write(" ")
+		p.keyword("FOLLOWING")
+	default:
+		p.keyword(string(b.Type))
+	}
+}
+
+// precedence returns how tightly an expression binds; see parser_expr.go.
+func precedence(e Expr) int {
+	switch x := e.(type) {
+	case *BinaryExpr:
+		switch x.Op {
+		case "OR":
+			return 1
+		case "AND":
+			return 2
+		case "=", "!=", "<>", "<", ">", "<=", ">=":
+			return 4
+		case "+", "-", "||":
+			return 5
+		default:
+			return 6
+		}
+	case *UnaryExpr:
+		if x.Op == "NOT" {
+			return 3
+		}
+		return 7
+	case *InExpr, *BetweenExpr, *IsNullExpr, *LikeExpr:
+		return 4
+	case *CastExpr:
+		if x.DoubleColon {
+			return 8
+		}
+	case *IndexExpr, *FieldExpr:
+		return 8
+	case *LambdaExpr:
+		return 0
+	}
+	return 9
+}
+
+// ---------- Scripts ----------
+
+// create writes CREATE TABLE or CREATE VIEW.
+func (p *printer) create(s *CreateStmt) {
+	p.group(s.Span, 0, func() {
+		p.keyword("CREATE")
+		if s.OrReplace {
+			p.keyword(" OR REPLACE")
+		}
+		if s.View {
+			p.keyword(" VIEW")
+		} else {
+			p.keyword(" TABLE")
+		}
+		if s.IfNotExists {
+			p.keyword(" IF NOT EXISTS")
+		}
+		p.write(" ")
+		p.tableName(s.Target)
+		if len(s.Columns) > 0 {
+			p.write(" ")
+			p.names(s.Columns)
+		}
+		if s.Select != nil {
+			p.write(" ")
+			p.keyword("AS")
+			p.line(s.Select.Span.Start.Offset)
+			p.selectStmt(s.Select)
+		}
+	})
+}
+
+// insert writes INSERT INTO.
+func (p *printer) insert(s *InsertStmt) {
+	p.statementWith(s.With)
+	p.group(s.Span, 0, func() {
+		p.keyword("INSERT INTO")
+		p.write(" ")
+		p.tableName(s.Target)
+		if len(s.Columns) > 0 {
+			p.write(" ")
+			p.names(s.Columns)
+		}
+		if s.ByName {
+			p.keyword(" BY NAME")
+		}
+		switch {
+		case len(s.Values) > 0:
+			p.line(p.before(s.Values[0][0].NodeSpan().Start.Offset, 2))
+			p.keyword("VALUES")
+			p.block(len(s.Values), func(i int) int { return p.before(s.Values[i][0].NodeSpan().Start.Offset, 1) }, func(i int) {
+				p.args("(", s.Values[i], ")", Span{})
+			})
+		case s.Select != nil:
+			p.line(s.Select.Span.Start.Offset)
+			p.selectStmt(s.Select)
+		default:
+			p.keyword(" DEFAULT VALUES")
+		}
+	})
+}
+
+// statementWith writes the WITH clause that starts a statement, if any,
+// with the statement on the next line.
+func (p *printer) statementWith(w *WithClause) {
+	if w == nil {
+		return
+	}
+	p.with(w)
+	p.line(p.after(w.Span.End.Offset))
+}
+
+// update writes UPDATE.
+func (p *printer) update(s *UpdateStmt) {
+	p.statementWith(s.With)
+	p.group(s.Span, 0, func() {
+		p.keyword("UPDATE")
+		p.write(" ")
+		p.tableName(s.Target)
+		p.alias(s.Target.Alias)
+		if len(s.Set) > 0 {
+			p.line(p.before(s.Set[0].Span.Start.Offset, 1))
+			p.keyword("SET")
+			p.assignments(s.Set)
+		}
+		if s.From != nil {
+			p.line(p.before(s.From.Span.Start.Offset, 1))
+			p.keyword("FROM")
+			p.write(" ")
+			p.from(s.From)
+		}
+		if s.Where != nil {
+			p.line(p.before(s.Where.NodeSpan().Start.Offset, 1))
+			p.condition("WHERE", s.Where)
+		}
+	})
+}
+
+// assignments writes the assignments of a SET, one per line unless they fit
+// on one.
+func (p *printer) assignments(set []Assignment) {
+	span := Span{Start: set[0].Span.Start, End: set[len(set)-1].Span.End}
+	p.group(span, 0, func() {
+		p.block(len(set), func(i int) int { return set[i].Span.Start.Offset }, func(i int) { p.assignment(set[i]) })
+	})
+}
+
+// assignment writes column = value.
+func (p *printer) assignment(a Assignment) {
+	p.ident(a.Column)
+	p.write(" = ")
+	p.expr(a.Value)
+}
+
+// delete writes DELETE FROM.
+func (p *printer) delete(s *DeleteStmt) {
+	p.statementWith(s.With)
+	p.group(s.Span, 0, func() {
+		p.keyword("DELETE FROM")
+		p.write(" ")
+		p.tableName(s.Target)
+		p.alias(s.Target.Alias)
+		if s.Using != nil {
+			p.line(p.before(s.Using.Span.Start.Offset, 1))
+			p.keyword("USING")
+			p.write(" ")
+			p.from(s.Using)
+		}
+		if s.Where != nil {
+			p.line(p.before(s.Where.NodeSpan().Start.Offset, 1))
+			p.condition("WHERE", s.Where)
+		}
+	})
+}
+
+// merge writes MERGE INTO, with each WHEN clause on its own line.
+func (p *printer) merge(s *MergeStmt) {
+	p.keyword("MERGE INTO")
+	p.write(" ")
+	p.tableName(s.Target)
+	p.alias(s.Target.Alias)
+	p.line(p.before(s.Source.NodeSpan().Start.Offset, 1))
+	p.keyword("USING")
+	p.write(" ")
+	p.tableRef(s.Source)
+	p.line(p.before(s.On.NodeSpan().Start.Offset, 1))
+	p.condition("ON", s.On)
+	for _, clause := range s.Clauses {
+		p.line(clause.Span.Start.Offset)
+		p.mergeClause(clause)
+	}
+}
+
+// mergeClause writes a WHEN [NOT] MATCHED clause of a MERGE.
+func (p *printer) mergeClause(c MergeClause) {
+	p.keyword("WHEN")
+	if !c.Matched {
+		p.keyword(" NOT")
+	}
+	p.keyword(" MATCHED")
+	if c.BySource {
+		p.keyword(" BY SOURCE")
+	}
+	if c.Condition != nil {
+		p.write(" ")
+		p.keyword("AND")
+		p.write(" ")
+		p.expr(c.Condition)
+	}
+	p.write(" ")
+	p.keyword("THEN")
+	p.write(" ")
+
+	switch c.Action {
+	case MergeUpdate:
+		p.keyword("UPDATE SET")
+		if c.Star {
+			p.write(" *")
+			return
+		}
+		p.indent++
+		p.assignments(c.Set)
+		p.indent--
+	case MergeInsert:
+		p.keyword("INSERT")
+		if c.Star {
+			p.write(" *")
+			return
+		}
+		if len(c.Columns) > 0 {
+			p.write(" ")
+			p.names(c.Columns)
+		}
+		p.write(" ")
+		p.keyword("VALUES")
+		p.write(" ")
+		p.args("(", c.Values, ")", Span{})
+	default:
+		p.keyword(string(c.Action))
+	}
+}
+
+// unsupported writes a statement the parser skipped: its source text when
+// formatting, otherwise just its keyword.
+func (p *printer) unsupported(s *UnsupportedStmt) {
+	if p.source == "" {
+		p.keyword(s.Keyword)
+		return
+	}
+	p.write(p.source[s.Span.Start.Offset:s.Span.End.Offset])
+	for p.next < len(p.comments) && p.comments[p.next].Span.Start.Offset < s.Span.End.Offset {
+		p.next++
+	}
+}
@@ -162,16 +162,79 @@ func (r *Resolver) resolveFromClause(scope *Scope, from *FromClause) error {
 		return err
 	}
 
+	// Entries of this FROM clause, for USING and NATURAL joins
+	var left []*ScopeEntry
+	if entry := scope.lastEntry(); entry != nil {
+		left = append(left, entry)
+	}
+
 	// Resolve joined tables
 	for _, join := range from.Joins {
 		if err := r.resolveTableRef(scope, join.Right); err != nil {
 			return err
 		}
+		right := scope.lastEntry()
+		if right == nil {
+			continue
+		}
+		if join.Natural || len(join.Using) > 0 {
+			r.mergeJoinColumns(scope, join, left, right)
+		}
+		left = append(left, right)
 	}
 
 	return nil
 }
 
+// mergeJoinColumns records the key columns of a USING or NATURAL join, so the
+// merged column is attributed to both sides. The left side is every earlier
+// FROM entry with the column, or the one just before the join when columns
+// are unknown.
+func (r *Resolver) mergeJoinColumns(scope *Scope, join *Join, left []*ScopeEntry, right *ScopeEntry) {
+	if len(left) == 0 {
+		return
+	}
+
+	columns := join.Using
+	if join.Natural {
+		// NATURAL joins need column info for both sides
+		columns = nil
+		for _, col := range right.Columns {
+			for _, entry := range left {
+				if entryHasColumn(scope, entry, col) {
+					columns = append(columns, col)
+					break
+				}
+			}
+		}
+	}
+
+	for _, col := range columns {
+		sides := scope.MergedEntries(col)
+		if len(sides) == 0 {
+			for _, entry := range left {
+				if entryHasColumn(scope, entry, col) {
+					sides = append(sides, entry)
+				}
+			}
+		}
+		if len(sides) == 0 {
+			sides = append(sides, left[len(left)-1])
+		}
+		scope.MergeColumn(col, append(sides, right))
+	}
+}
+
+// entryHasColumn reports whether a scope entry is known to have a column.
+func entryHasColumn(scope *Scope, entry *ScopeEntry, column string) bool {
+	for _, c := range entry.Columns {
+		if scope.normalize(c) == scope.normalize(column) {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveTableRef resolves a table reference and registers it in scope.
 func (r *Resolver) resolveTableRef(scope *Scope, ref TableRef) error {
 	if ref == nil {
@@ -190,8 +253,7 @@ func (r *Resolver) resolveTableRef(scope *Scope, ref TableRef) error {
 				Columns:           cte.Columns,
 				UnderlyingSources: cte.UnderlyingSources,
 			}
-			normalized := scope.normalize(entry.EffectiveName())
-			scope.entries[normalized] = entry
+			scope.register(entry.EffectiveName(), entry)
 		} else {
 			// Physical table
 			scope.RegisterTable(t)
@@ -244,11 +306,155 @@ func (r *Resolver) resolveTableRef(scope *Scope, ref TableRef) error {
 				scope.RegisterDerived(t.Alias, columns)
 			}
 		}
+
+	case *TableFunction:
+		scope.RegisterTableFunction(t, r.dialect)
+
+	case *PivotTable:
+		// Only the pivoted table is visible to the rest of the query
+		subScope := scope.Child()
+		if err := r.resolveTableRef(subScope, t.Source); err != nil {
+			return err
+		}
+		columns := r.pivotColumns(subScope, t)
+		scope.RegisterDerivedWithSources(pivotName(t.Alias, t.Source, "pivot"), columns, r.collectUnderlyingSources(subScope))
+
+	case *UnpivotTable:
+		subScope := scope.Child()
+		if err := r.resolveTableRef(subScope, t.Source); err != nil {
+			return err
+		}
+		columns := r.unpivotColumns(subScope, t)
+		scope.RegisterDerivedWithSources(pivotName(t.Alias, t.Source, "unpivot"), columns, r.collectUnderlyingSources(subScope))
 	}
 
 	return nil
 }
 
+// pivotName returns the name a PIVOT or UNPIVOT is referenced by: its alias,
+// else the name of the table it reads.
+func pivotName(alias string, source TableRef, fallback string) string {
+	if alias != "" {
+		return alias
+	}
+	if t, ok := source.(*TableName); ok {
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return t.Name
+	}
+	return fallback
+}
+
+// pivotColumns returns the output columns of a PIVOT: the group columns, then
+// one column per pivoted value and aggregate. Returns nil when the group
+// columns or pivoted values are not known. The scope holds only the source.
+func (r *Resolver) pivotColumns(scope *Scope, pivot *PivotTable) []string {
+	source := scope.lastEntry()
+	var columns []string
+	if len(pivot.GroupBy) > 0 {
+		for i, expr := range pivot.GroupBy {
+			columns = append(columns, r.inferColumnName(expr, i))
+		}
+	} else {
+		// Every source column not pivoted or aggregated is a group column
+		if source == nil || len(source.Columns) == 0 {
+			return nil
+		}
+		used := make(map[string]struct{})
+		cr := NewColumnResolver(scope, r.dialect)
+		for _, on := range pivot.On {
+			for _, ref := range cr.CollectColumns(on.Expr) {
+				used[scope.normalize(ref.Column)] = struct{}{}
+			}
+		}
+		for _, agg := range pivot.Aggregates {
+			for _, ref := range cr.CollectColumns(agg.Expr) {
+				used[scope.normalize(ref.Column)] = struct{}{}
+			}
+		}
+		for _, col := range source.Columns {
+			if _, ok := used[scope.normalize(col)]; !ok {
+				columns = append(columns, col)
+			}
+		}
+	}
+
+	// Values of several pivot columns combine: 2020_east, 2020_west, ...
+	names := []string{""}
+	for _, on := range pivot.On {
+		if len(on.Values) == 0 {
+			return nil
+		}
+		var next []string
+		for _, prefix := range names {
+			for _, value := range on.Values {
+				name := pivotValueName(value)
+				if prefix != "" {
+					name = prefix + "_" + name
+				}
+				next = append(next, name)
+			}
+		}
+		names = next
+	}
+
+	for _, name := range names {
+		if len(pivot.Aggregates) <= 1 {
+			columns = append(columns, name)
+			continue
+		}
+		for i, agg := range pivot.Aggregates {
+			suffix := agg.Alias
+			if suffix == "" {
+				suffix = r.inferColumnName(agg.Expr, i)
+			}
+			columns = append(columns, name+"_"+suffix)
+		}
+	}
+	return columns
+}
+
+// pivotValueName returns the column name a pivoted value produces.
+func pivotValueName(value SelectItem) string {
+	if value.Alias != "" {
+		return value.Alias
+	}
+	switch v := value.Expr.(type) {
+	case *Literal:
+		return v.Value
+	case *ColumnRef:
+		return v.Column
+	}
+	return "value"
+}
+
+// unpivotColumns returns the output columns of an UNPIVOT: the source columns
+// not unpivoted, then the name and value columns. Returns nil when the source
+// columns are not known. The scope holds only the source.
+func (r *Resolver) unpivotColumns(scope *Scope, unpivot *UnpivotTable) []string {
+	source := scope.lastEntry()
+	if source == nil || len(source.Columns) == 0 {
+		return nil
+	}
+	used := make(map[string]struct{})
+	cr := NewColumnResolver(scope, r.dialect)
+	for _, expr := range unpivot.On {
+		for _, ref := range cr.CollectColumns(expr) {
+			used[scope.normalize(ref.Column)] = struct{}{}
+		}
+	}
+
+	var columns []string
+	for _, col := range source.Columns {
+		if _, ok := used[scope.normalize(col)]; !ok {
+			columns = append(columns, col)
+		}
+	}
+	columns = append(columns, unpivot.Name)
+	return append(columns, unpivot.Values...)
+}
+
 // extractSelectColumns extracts column names from a SELECT list.
 // Returns the list of output column names.
 func (r *Resolver) extractSelectColumns(scope *Scope, body *SelectBody) []string {
@@ -309,6 +515,10 @@ func (r *Resolver) inferColumnName(expr Expr, index int) string {
 		// Use the inner expression's name
 		return r.inferColumnName(e.Expr, index)
 
+	case *FieldExpr:
+		// Struct field access is named after the field
+		return e.Field
+
 	case *ParenExpr:
 		// Use the inner expression's name
 		return r.inferColumnName(e.Expr, index)
@@ -417,6 +627,37 @@ func (cr *ColumnResolver) collectColumnsRecursive(expr Expr, refs *[]*ColumnRef)
 	case *CastExpr:
 		cr.collectColumnsRecursive(e.Expr, refs)
 
+	case *NamedArg:
+		cr.collectColumnsRecursive(e.Value, refs)
+
+	case *ListExpr:
+		for _, el := range e.Elements {
+			cr.collectColumnsRecursive(el, refs)
+		}
+
+	case *StructExpr:
+		for _, f := range e.Fields {
+			cr.collectColumnsRecursive(f.Value, refs)
+		}
+
+	case *IndexExpr:
+		cr.collectColumnsRecursive(e.Expr, refs)
+		cr.collectColumnsRecursive(e.Index, refs)
+		cr.collectColumnsRecursive(e.End, refs)
+
+	case *FieldExpr:
+		cr.collectColumnsRecursive(e.Expr, refs)
+
+	case *LambdaExpr:
+		// Lambda parameters are bound to list elements, not columns
+		var body []*ColumnRef
+		cr.collectColumnsRecursive(e.Body, &body)
+		for _, ref := range body {
+			if !cr.isLambdaParam(e, ref) {
+				*refs = append(*refs, ref)
+			}
+		}
+
 	case *InExpr:
 		cr.collectColumnsRecursive(e.Expr, refs)
 		for _, v := range e.Values {
@@ -451,6 +692,23 @@ func (cr *ColumnResolver) collectColumnsRecursive(expr Expr, refs *[]*ColumnRef)
 	}
 }
 
+// isLambdaParam reports whether a column reference inside a lambda body names
+// one of its parameters, directly (x) or through a field (x.name).
+func (cr *ColumnResolver) isLambdaParam(lambda *LambdaExpr, ref *ColumnRef) bool {
+	name := ref.Column
+	if len(ref.Path) > 0 {
+		name = ref.Path[0]
+	} else if ref.Table != "" {
+		name = ref.Table
+	}
+	for _, param := range lambda.Params {
+		if cr.dialect.NormalizeName(param) == cr.dialect.NormalizeName(name) {
+			return true
+		}
+	}
+	return false
+}
+
 // ResolveColumnRef resolves a column reference to its source.
 func (cr *ColumnResolver) ResolveColumnRef(ref *ColumnRef) (*ColumnSource, bool) {
 	return cr.scope.ResolveColumnFull(ref)
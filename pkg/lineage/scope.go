@@ -21,11 +21,12 @@ const (
 // ScopeEntry represents a table/CTE/derived table in scope.
 type ScopeEntry struct {
 	Type              ScopeType
-	Name              string   // Original table/CTE name
-	Alias             string   // Alias (if any)
-	Columns           []string // Known columns (from schema or derived query)
-	SourceTable       string   // For physical tables: fully qualified name (schema.table)
-	UnderlyingSources []string // For CTEs/derived tables: underlying physical tables
+	Name              string         // Original table/CTE name
+	Alias             string         // Alias (if any)
+	Columns           []string       // Known columns (from schema or derived query)
+	SourceTable       string         // For physical tables: fully qualified name (schema.table)
+	UnderlyingSources []string       // For CTEs/derived tables: underlying physical tables
+	Function          *TableFunction // For table functions: the call its columns are computed from
 }
 
 // EffectiveName returns the name used to reference this entry (alias if present, else name).
@@ -40,8 +41,13 @@ func (e *ScopeEntry) EffectiveName() string {
 type Scope struct {
 	parent  *Scope                 // Parent scope (for nested queries)
 	entries map[string]*ScopeEntry // Name/alias -> entry (normalized to lowercase)
+	order   []string               // Normalized entry names in registration order
 	dialect *Dialect               // For name normalization
 	schema  Schema                 // External schema information
+
+	// Columns merged by USING or NATURAL joins: normalized column -> joined entries
+	merged      map[string][]*ScopeEntry
+	mergedOrder []string // Merged column names in the order they were joined
 }
 
 // NewScope creates a new root scope.
@@ -66,30 +72,44 @@ func (s *Scope) Child() *Scope {
 	}
 }
 
-// normalize normalizes an identifier according to dialect rules.
+// normalize returns the key an identifier is compared by.
 func (s *Scope) normalize(name string) string {
-	return s.dialect.NormalizeName(name)
+	return s.dialect.identKey(name)
+}
+
+// register adds an entry under name, replacing any entry with the same name.
+// The entry moves to the end of the registration order.
+func (s *Scope) register(name string, entry *ScopeEntry) {
+	normalized := s.normalize(name)
+	if _, ok := s.entries[normalized]; ok {
+		for i, key := range s.order {
+			if key == normalized {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	s.order = append(s.order, normalized)
+	s.entries[normalized] = entry
 }
 
 // RegisterCTE registers a CTE with its resolved columns.
 func (s *Scope) RegisterCTE(name string, columns []string) {
-	normalized := s.normalize(name)
-	s.entries[normalized] = &ScopeEntry{
+	s.register(name, &ScopeEntry{
 		Type:    ScopeCTE,
 		Name:    name,
 		Columns: columns,
-	}
+	})
 }
 
 // RegisterCTEWithSources registers a CTE with its resolved columns and underlying sources.
 func (s *Scope) RegisterCTEWithSources(name string, columns []string, underlyingSources []string) {
-	normalized := s.normalize(name)
-	s.entries[normalized] = &ScopeEntry{
+	s.register(name, &ScopeEntry{
 		Type:              ScopeCTE,
 		Name:              name,
 		Columns:           columns,
 		UnderlyingSources: underlyingSources,
-	}
+	})
 }
 
 // RegisterTable registers a physical table from a FROM clause.
@@ -100,15 +120,7 @@ func (s *Scope) RegisterTable(table *TableName) {
 	}
 
 	// Build fully qualified source name
-	var parts []string
-	if table.Catalog != "" {
-		parts = append(parts, table.Catalog)
-	}
-	if table.Schema != "" {
-		parts = append(parts, table.Schema)
-	}
-	parts = append(parts, table.Name)
-	entry.SourceTable = strings.Join(parts, ".")
+	entry.SourceTable = s.normalize(qualifiedTableName(table))
 
 	if table.Alias != "" {
 		entry.Alias = table.Alias
@@ -116,46 +128,129 @@ func (s *Scope) RegisterTable(table *TableName) {
 
 	// Try to get columns from schema
 	if s.schema != nil {
-		// Try various forms of the table name
-		for _, key := range []string{
-			entry.SourceTable,
-			table.Name,
-			s.normalize(entry.SourceTable),
-			s.normalize(table.Name),
-		} {
-			if cols, ok := s.schema[key]; ok {
-				entry.Columns = cols
-				break
-			}
-		}
+		entry.Columns = s.schemaColumns(qualifiedTableName(table), table.Name)
 	}
 
 	// Register by effective name (alias or table name)
-	normalized := s.normalize(entry.EffectiveName())
-	s.entries[normalized] = entry
+	s.register(entry.EffectiveName(), entry)
+}
+
+// schemaColumns returns the schema's columns for the first of names it lists.
+// Schema names are written without quotes, so when none is listed as written
+// they match as unquoted identifiers, and so do their columns.
+func (s *Scope) schemaColumns(names ...string) []string {
+	for _, name := range names {
+		if cols, ok := s.schema[name]; ok {
+			return cols
+		}
+	}
+	for _, name := range names {
+		for table, cols := range s.schema {
+			if s.normalize(s.dialect.foldUnquoted(table)) != s.normalize(name) {
+				continue
+			}
+			folded := make([]string, len(cols))
+			for i, col := range cols {
+				folded[i] = s.dialect.foldUnquoted(col)
+			}
+			return folded
+		}
+	}
+	return nil
 }
 
 // RegisterDerived registers a derived table (subquery in FROM).
 func (s *Scope) RegisterDerived(alias string, columns []string) {
-	normalized := s.normalize(alias)
-	s.entries[normalized] = &ScopeEntry{
+	s.register(alias, &ScopeEntry{
 		Type:    ScopeDerived,
 		Name:    alias,
 		Alias:   alias,
 		Columns: columns,
-	}
+	})
 }
 
 // RegisterDerivedWithSources registers a derived table with its underlying sources.
 func (s *Scope) RegisterDerivedWithSources(alias string, columns []string, underlyingSources []string) {
-	normalized := s.normalize(alias)
-	s.entries[normalized] = &ScopeEntry{
+	s.register(alias, &ScopeEntry{
 		Type:              ScopeDerived,
 		Name:              alias,
 		Alias:             alias,
 		Columns:           columns,
 		UnderlyingSources: underlyingSources,
+	})
+}
+
+// RegisterTableFunction registers a table function from a FROM clause.
+// Columns come from the alias list (AS t(a, b)), the dialect's catalog, or the
+// schema for a file path. A file reader is a table whose source is its path;
+// other table functions read their arguments, which resolve through scope.
+func (s *Scope) RegisterTableFunction(fn *TableFunction, dialect *Dialect) {
+	name := fn.Alias
+	if name == "" {
+		name = s.dialect.foldUnquoted(strings.ToLower(fn.Name))
+	}
+
+	columns := fn.Columns
+	if len(columns) == 0 {
+		columns, _ = dialect.TableFunctionColumns(fn.Name)
+	}
+
+	paths := FilePaths(fn, dialect)
+	if len(paths) == 0 {
+		s.register(name, &ScopeEntry{
+			Type:     ScopeDerived,
+			Name:     name,
+			Alias:    fn.Alias,
+			Columns:  columns,
+			Function: fn,
+		})
+		return
+	}
+
+	if len(columns) == 0 && s.schema != nil {
+		columns = s.schema[paths[0]]
 	}
+	entry := &ScopeEntry{
+		Type:    ScopeTable,
+		Name:    name,
+		Alias:   fn.Alias,
+		Columns: columns,
+	}
+	if len(paths) == 1 {
+		entry.SourceTable = paths[0]
+	} else {
+		// Several files: columns resolve to the reader, sources are every path
+		entry.Type = ScopeDerived
+		entry.UnderlyingSources = paths
+	}
+	s.register(name, entry)
+}
+
+// FilePaths returns the literal file paths a file reader table function
+// reads, e.g. read_parquet('orders.parquet') or read_csv(['a.csv', 'b.csv']).
+// Paths built from expressions are not known and are skipped.
+func FilePaths(fn *TableFunction, dialect *Dialect) []string {
+	if dialect == nil {
+		dialect = DefaultDialect()
+	}
+	if !dialect.IsFileReader(fn.Name) || len(fn.Args) == 0 {
+		return nil
+	}
+
+	var paths []string
+	switch arg := fn.Args[0].(type) {
+	case *Literal:
+		if arg.Type == LiteralString {
+			paths = append(paths, arg.Value)
+		}
+	case *ListExpr:
+		for _, el := range arg.Elements {
+			if lit, ok := el.(*Literal); ok && lit.Type == LiteralString {
+				paths = append(paths, lit.Value)
+			}
+		}
+	}
+	return paths
 }
 
 // Lookup finds a scope entry by name (table name or alias).
@@ -191,15 +286,69 @@ func (s *Scope) LookupCTE(name string) (*ScopeEntry, bool) {
 	return nil, false
 }
 
-// AllEntries returns all scope entries in the current scope (not including parent).
+// AllEntries returns all scope entries in the current scope (not including parent),
+// in registration order.
 func (s *Scope) AllEntries() []*ScopeEntry {
-	entries := make([]*ScopeEntry, 0, len(s.entries))
-	for _, e := range s.entries {
-		entries = append(entries, e)
+	entries := make([]*ScopeEntry, 0, len(s.order))
+	for _, key := range s.order {
+		entries = append(entries, s.entries[key])
 	}
 	return entries
 }
 
+// lastEntry returns the most recently registered entry, or nil if there is none.
+func (s *Scope) lastEntry() *ScopeEntry {
+	if len(s.order) == 0 {
+		return nil
+	}
+	return s.entries[s.order[len(s.order)-1]]
+}
+
+// MergeColumn records that a USING or NATURAL join merged column across entries.
+// Entries already merged on the column stay merged with the new ones.
+func (s *Scope) MergeColumn(column string, entries []*ScopeEntry) {
+	if s.merged == nil {
+		s.merged = make(map[string][]*ScopeEntry)
+	}
+	normalized := s.normalize(column)
+	existing, ok := s.merged[normalized]
+	if !ok {
+		s.mergedOrder = append(s.mergedOrder, column)
+	}
+	for _, entry := range entries {
+		if !containsEntry(existing, entry) {
+			existing = append(existing, entry)
+		}
+	}
+	s.merged[normalized] = existing
+}
+
+// MergedEntries returns the entries a join merged column across, or nil if the
+// column was not merged.
+func (s *Scope) MergedEntries(column string) []*ScopeEntry {
+	return s.merged[s.normalize(column)]
+}
+
+// hasColumns reports whether any of entries has known columns.
+func hasColumns(entries []*ScopeEntry) bool {
+	for _, e := range entries {
+		if len(e.Columns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// containsEntry reports whether entries contains entry.
+func containsEntry(entries []*ScopeEntry, entry *ScopeEntry) bool {
+	for _, e := range entries {
+		if e == entry {
+			return true
+		}
+	}
+	return false
+}
+
 // ResolveColumn attempts to resolve a column reference to its source table.
 // Returns the scope entry and true if found, nil and false otherwise.
 //
@@ -213,7 +362,7 @@ func (s *Scope) ResolveColumn(ref *ColumnRef) (*ScopeEntry, bool) {
 
 	// Unqualified - search all entries
 	// Return first match (ambiguous references would need more complex handling)
-	for _, entry := range s.entries {
+	for _, entry := range s.AllEntries() {
 		for _, col := range entry.Columns {
 			if s.normalize(col) == s.normalize(ref.Column) {
 				return entry, true
@@ -230,6 +379,39 @@ func (s *Scope) ResolveColumn(ref *ColumnRef) (*ScopeEntry, bool) {
 	return nil, false
 }
 
+// StructColumn returns the column a reference reads when its qualifier is not
+// a table in scope but a struct column: s.field reads column s, t.s.field
+// reads column s of t. Other references are returned unchanged.
+func (s *Scope) StructColumn(ref *ColumnRef) *ColumnRef {
+	if ref.Table == "" {
+		return ref
+	}
+	if _, ok := s.Lookup(ref.Table); ok {
+		return ref
+	}
+	if !s.hasEntries() {
+		// Nothing in scope to tell tables from structs
+		return ref
+	}
+	if len(ref.Path) > 2 {
+		if _, ok := s.Lookup(ref.Path[0]); ok {
+			return &ColumnRef{Table: ref.Path[0], Column: ref.Path[1]}
+		}
+		return &ColumnRef{Column: ref.Path[0]}
+	}
+	return &ColumnRef{Column: ref.Table}
+}
+
+// hasEntries reports whether this scope or a parent has any entries.
+func (s *Scope) hasEntries() bool {
+	for sc := s; sc != nil; sc = sc.parent {
+		if len(sc.order) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // ExpandStar expands a SELECT * to column references.
 // If tableName is empty, expands * for all tables in scope.
 // If tableName is provided, expands only for that table.
@@ -253,10 +435,19 @@ func (s *Scope) ExpandStar(tableName string) []*ColumnRef {
 		return refs
 	}
 
-	// Expand * for all tables
+	// Expand * for all tables. Columns merged by USING or NATURAL joins come
+	// first and once, unqualified so they resolve to every joined side.
 	var refs []*ColumnRef
-	for _, entry := range s.entries {
+	for _, col := range s.mergedOrder {
+		if hasColumns(s.MergedEntries(col)) {
+			refs = append(refs, &ColumnRef{Column: col})
+		}
+	}
+	for _, entry := range s.AllEntries() {
 		for _, col := range entry.Columns {
+			if containsEntry(s.MergedEntries(col), entry) {
+				continue
+			}
 			refs = append(refs, &ColumnRef{
 				Table:  entry.EffectiveName(),
 				Column: col,
@@ -268,7 +459,7 @@ func (s *Scope) ExpandStar(tableName string) []*ColumnRef {
 
 // HasSchemaInfo returns true if the scope has column information for any table.
 func (s *Scope) HasSchemaInfo() bool {
-	for _, entry := range s.entries {
+	for _, entry := range s.AllEntries() {
 		if len(entry.Columns) > 0 {
 			return true
 		}
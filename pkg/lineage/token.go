@@ -34,6 +34,13 @@ const (
 	TOKEN_RPAREN   // )
 	TOKEN_LBRACKET // [
 	TOKEN_RBRACKET // ]
+	TOKEN_DCOLON   // ::
+	TOKEN_ARROW    // =>
+	TOKEN_RARROW   // ->
+	TOKEN_COLON    // :
+	TOKEN_LBRACE   // {
+	TOKEN_RBRACE   // }
+	TOKEN_SEMI     // ;
 
 	// Keywords (alphabetical)
 	TOKEN_ALL
@@ -71,6 +78,7 @@ const (
 	TOKEN_LEFT
 	TOKEN_LIKE
 	TOKEN_LIMIT
+	TOKEN_NATURAL
 	TOKEN_NOT
 	TOKEN_NULL
 	TOKEN_NULLS
@@ -81,6 +89,7 @@ const (
 	TOKEN_OUTER
 	TOKEN_OVER
 	TOKEN_PARTITION
+	TOKEN_PIVOT
 	TOKEN_PRECEDING
 	TOKEN_QUALIFY
 	TOKEN_RANGE
@@ -93,6 +102,8 @@ const (
 	TOKEN_TRUE
 	TOKEN_UNBOUNDED
 	TOKEN_UNION
+	TOKEN_UNPIVOT
+	TOKEN_USING
 	TOKEN_WHEN
 	TOKEN_WHERE
 	TOKEN_WITH
@@ -104,6 +115,8 @@ type Token struct {
 	Type    TokenType
 	Literal string
 	Pos     Position
+	End     Position // just past the last character
+	Quoted  bool     // a quoted identifier
 }
 
 // Position represents a location in the source code.
@@ -148,6 +161,13 @@ var tokenNames = map[TokenType]string{
 	TOKEN_RPAREN:   ")",
 	TOKEN_LBRACKET: "[",
 	TOKEN_RBRACKET: "]",
+	TOKEN_DCOLON:   "::",
+	TOKEN_ARROW:    "=>",
+	TOKEN_RARROW:   "->",
+	TOKEN_COLON:    ":",
+	TOKEN_LBRACE:   "{",
+	TOKEN_RBRACE:   "}",
+	TOKEN_SEMI:     ";",
 
 	TOKEN_ALL:       "ALL",
 	TOKEN_AND:       "AND",
@@ -184,6 +204,7 @@ var tokenNames = map[TokenType]string{
 	TOKEN_LEFT:      "LEFT",
 	TOKEN_LIKE:      "LIKE",
 	TOKEN_LIMIT:     "LIMIT",
+	TOKEN_NATURAL:   "NATURAL",
 	TOKEN_NOT:       "NOT",
 	TOKEN_NULL:      "NULL",
 	TOKEN_NULLS:     "NULLS",
@@ -194,6 +215,7 @@ var tokenNames = map[TokenType]string{
 	TOKEN_OUTER:     "OUTER",
 	TOKEN_OVER:      "OVER",
 	TOKEN_PARTITION: "PARTITION",
+	TOKEN_PIVOT:     "PIVOT",
 	TOKEN_PRECEDING: "PRECEDING",
 	TOKEN_QUALIFY:   "QUALIFY",
 	TOKEN_RANGE:     "RANGE",
@@ -206,6 +228,8 @@ var tokenNames = map[TokenType]string{
 	TOKEN_TRUE:      "TRUE",
 	TOKEN_UNBOUNDED: "UNBOUNDED",
 	TOKEN_UNION:     "UNION",
+	TOKEN_UNPIVOT:   "UNPIVOT",
+	TOKEN_USING:     "USING",
 	TOKEN_WHEN:      "WHEN",
 	TOKEN_WHERE:     "WHERE",
 	TOKEN_WITH:      "WITH",
@@ -249,6 +273,7 @@ var keywords = map[string]TokenType{
 	"left":      TOKEN_LEFT,
 	"like":      TOKEN_LIKE,
 	"limit":     TOKEN_LIMIT,
+	"natural":   TOKEN_NATURAL,
 	"not":       TOKEN_NOT,
 	"null":      TOKEN_NULL,
 	"nulls":     TOKEN_NULLS,
@@ -259,6 +284,7 @@ var keywords = map[string]TokenType{
 	"outer":     TOKEN_OUTER,
 	"over":      TOKEN_OVER,
 	"partition": TOKEN_PARTITION,
+	"pivot":     TOKEN_PIVOT,
 	"preceding": TOKEN_PRECEDING,
 	"qualify":   TOKEN_QUALIFY,
 	"range":     TOKEN_RANGE,
@@ -271,6 +297,8 @@ var keywords = map[string]TokenType{
 	"true":      TOKEN_TRUE,
 	"unbounded": TOKEN_UNBOUNDED,
 	"union":     TOKEN_UNION,
+	"unpivot":   TOKEN_UNPIVOT,
+	"using":     TOKEN_USING,
 	"when":      TOKEN_WHEN,
 	"where":     TOKEN_WHERE,
 	"with":      TOKEN_WITH,
@@ -0,0 +1,50 @@
+package lineage
+
+// Transpiling: a script written in one dialect reprinted for another.
+//
+// Names are written with the target dialect's quotes, and quoted where the
+// source quoted them or the target requires it. Casts and string literals
+// use the target's syntax. A function name is kept if the target knows it
+// as an alias; otherwise it is resolved through the source dialect's aliases
+// to a canonical name, which is written as the target spells it. Functions
+// that neither dialect maps are written as in the source.
+
+// niladic are functions written without parentheses in standard SQL.
+var niladic = map[string]bool{
+	"CURRENT_DATE":      true,
+	"CURRENT_TIME":      true,
+	"CURRENT_TIMESTAMP": true,
+	"LOCALTIME":         true,
+	"LOCALTIMESTAMP":    true,
+}
+
+// Transpile reprints a script of semicolon-separated statements written in
+// one dialect as SQL for another, keeping its comments. Statements the
+// parser doesn't model are kept as written. Nil dialects are the default
+// dialect.
+func Transpile(sql string, from, to *Dialect) (string, error) {
+	if from == nil {
+		from = DefaultDialect()
+	}
+	p := newPrinter(PrintOptions{Dialect: to})
+	p.rename = func(name string) string {
+		return transpileFunction(name, from, p.dialect)
+	}
+	return p.script(sql, from)
+}
+
+// transpileFunction returns the name in one dialect of a function written
+// in another.
+func transpileFunction(name string, from, to *Dialect) string {
+	if _, ok := to.aliases[to.NormalizeName(name)]; ok {
+		return name
+	}
+	canonical := from.CanonicalFunctionName(name)
+	if spelling, ok := to.spellings[to.NormalizeName(canonical)]; ok {
+		return spelling
+	}
+	if canonical == from.NormalizeName(name) {
+		return name
+	}
+	return canonical
+}
@@ -0,0 +1,314 @@
+package lineage
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Walking and rewriting: traversal of every node of an AST, in source order.
+//
+// The children of a node are its statements, clauses, table references and
+// expressions, including those in CTEs, derived tables and subqueries. List
+// elements held by value, such as the SelectItems of a SelectCore, are
+// passed as pointers into their list, so they can be modified in place.
+
+// Visitor's Visit method is called for each node Walk encounters. If the
+// visitor it returns is not nil, Walk visits each child of the node with it,
+// then calls its Visit method with nil.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, calling v.Visit(node) and
+// walking the children of node with the visitor it returns.
+func Walk(node Node, v Visitor) {
+	if isNil(node) {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	editChildren(node, func(child Node) Node {
+		Walk(child, v)
+		return child
+	})
+	v.Visit(nil)
+}
+
+// inspector is a Visitor that calls a function.
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if node != nil && f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order, calling fn for each node.
+// The children of a node are skipped if fn returns false for it.
+func Inspect(node Node, fn func(Node) bool) {
+	Walk(node, inspector(fn))
+}
+
+// Rewrite rewrites an AST bottom-up: it rewrites the children of node, then
+// returns fn(node). The node fn returns takes the place of the one it was
+// given, and must be one its parent can hold: an Expr for an expression, a
+// TableRef for a table reference, or a node of the same type otherwise. A
+// nil result removes the node: an optional field such as a WHERE condition
+// is cleared and a list element is dropped. Nodes may also be modified in
+// place. Print writes the SQL of the rewritten AST.
+//
+// Rewrite panics if fn returns a node its parent can't hold.
+func Rewrite(node Node, fn func(Node) Node) Node {
+	if isNil(node) {
+		return node
+	}
+	editChildren(node, func(child Node) Node {
+		return Rewrite(child, fn)
+	})
+	return fn(node)
+}
+
+// editChildren replaces each child of node with fn(child), in source order.
+func editChildren(node Node, fn func(Node) Node) {
+	switch n := node.(type) {
+	// Statements
+	case *SelectStmt:
+		edit(&n.With, fn)
+		edit(&n.Body, fn)
+	case *CreateStmt:
+		edit(&n.Target, fn)
+		edit(&n.Select, fn)
+	case *InsertStmt:
+		edit(&n.With, fn)
+		edit(&n.Target, fn)
+		edit(&n.Select, fn)
+		for i := range n.Values {
+			editList(&n.Values[i], fn)
+		}
+	case *UpdateStmt:
+		edit(&n.With, fn)
+		edit(&n.Target, fn)
+		editValues(&n.Set, fn)
+		edit(&n.From, fn)
+		edit(&n.Where, fn)
+	case *DeleteStmt:
+		edit(&n.With, fn)
+		edit(&n.Target, fn)
+		edit(&n.Using, fn)
+		edit(&n.Where, fn)
+	case *MergeStmt:
+		edit(&n.Target, fn)
+		edit(&n.Source, fn)
+		edit(&n.On, fn)
+		editValues(&n.Clauses, fn)
+	case *MergeClause:
+		edit(&n.Condition, fn)
+		editValues(&n.Set, fn)
+		editList(&n.Values, fn)
+	case *Assignment:
+		edit(&n.Value, fn)
+	case *UnsupportedStmt:
+
+	// Clauses
+	case *WithClause:
+		editList(&n.CTEs, fn)
+	case *CTE:
+		edit(&n.Select, fn)
+	case *SelectBody:
+		edit(&n.Left, fn)
+		edit(&n.Right, fn)
+	case *SelectCore:
+		editValues(&n.Columns, fn)
+		edit(&n.From, fn)
+		edit(&n.Where, fn)
+		editList(&n.GroupBy, fn)
+		edit(&n.Having, fn)
+		edit(&n.Qualify, fn)
+		editValues(&n.OrderBy, fn)
+		edit(&n.Limit, fn)
+		edit(&n.Offset, fn)
+	case *SelectItem:
+		edit(&n.Expr, fn)
+	case *FromClause:
+		edit(&n.Source, fn)
+		editList(&n.Joins, fn)
+	case *Join:
+		edit(&n.Right, fn)
+		edit(&n.Condition, fn)
+	case *OrderByItem:
+		edit(&n.Expr, fn)
+
+	// Table references
+	case *TableName:
+	case *DerivedTable:
+		edit(&n.Select, fn)
+	case *LateralTable:
+		edit(&n.Select, fn)
+	case *TableFunction:
+		editList(&n.Args, fn)
+	case *PivotTable:
+		edit(&n.Source, fn)
+		editValues(&n.Aggregates, fn)
+		editValues(&n.On, fn)
+		editList(&n.GroupBy, fn)
+	case *PivotColumn:
+		edit(&n.Expr, fn)
+		editValues(&n.Values, fn)
+	case *UnpivotTable:
+		edit(&n.Source, fn)
+		editList(&n.On, fn)
+
+	// Expressions
+	case *ColumnRef, *Literal, *StarExpr:
+	case *BinaryExpr:
+		edit(&n.Left, fn)
+		edit(&n.Right, fn)
+	case *UnaryExpr:
+		edit(&n.Expr, fn)
+	case *FuncCall:
+		editList(&n.Args, fn)
+		edit(&n.Filter, fn)
+		edit(&n.Window, fn)
+	case *WindowSpec:
+		editList(&n.PartitionBy, fn)
+		editValues(&n.OrderBy, fn)
+		edit(&n.Frame, fn)
+	case *FrameSpec:
+		edit(&n.Start, fn)
+		edit(&n.End, fn)
+	case *FrameBound:
+		edit(&n.Offset, fn)
+	case *CaseExpr:
+		edit(&n.Operand, fn)
+		editValues(&n.Whens, fn)
+		edit(&n.Else, fn)
+	case *WhenClause:
+		edit(&n.Condition, fn)
+		edit(&n.Result, fn)
+	case *CastExpr:
+		edit(&n.Expr, fn)
+	case *InExpr:
+		edit(&n.Expr, fn)
+		editList(&n.Values, fn)
+		edit(&n.Query, fn)
+	case *BetweenExpr:
+		edit(&n.Expr, fn)
+		edit(&n.Low, fn)
+		edit(&n.High, fn)
+	case *IsNullExpr:
+		edit(&n.Expr, fn)
+	case *LikeExpr:
+		edit(&n.Expr, fn)
+		edit(&n.Pattern, fn)
+	case *ParenExpr:
+		edit(&n.Expr, fn)
+	case *SubqueryExpr:
+		edit(&n.Select, fn)
+	case *ExistsExpr:
+		edit(&n.Select, fn)
+	case *ListExpr:
+		editList(&n.Elements, fn)
+	case *IndexExpr:
+		edit(&n.Expr, fn)
+		edit(&n.Index, fn)
+		edit(&n.End, fn)
+	case *FieldExpr:
+		edit(&n.Expr, fn)
+	case *StructExpr:
+		editValues(&n.Fields, fn)
+	case *StructField:
+		edit(&n.Value, fn)
+	case *LambdaExpr:
+		edit(&n.Body, fn)
+	case *NamedArg:
+		edit(&n.Value, fn)
+	}
+}
+
+// edit replaces the node in a field with fn(node), leaving the field alone
+// if it is empty or the node is unchanged.
+func edit[T Node](field *T, fn func(Node) Node) {
+	if isNil(*field) {
+		return
+	}
+	replace(field, fn(*field))
+}
+
+// replace sets a field to a node, or clears it for nil.
+func replace[T Node](field *T, node Node) {
+	old := *field
+	switch {
+	case node == Node(old):
+	case isNil(node):
+		var zero T
+		*field = zero
+	default:
+		t, ok := node.(T)
+		if !ok {
+			panic(fmt.Sprintf("lineage: can't replace %T with %T", old, node))
+		}
+		*field = t
+	}
+}
+
+// editList edits each node of a list, dropping those replaced with nil.
+func editList[T Node](list *[]T, fn func(Node) Node) {
+	dropped := false
+	for i := range *list {
+		edit(&(*list)[i], fn)
+		dropped = dropped || isNil((*list)[i])
+	}
+	if dropped {
+		kept := (*list)[:0]
+		for _, n := range *list {
+			if !isNil(n) {
+				kept = append(kept, n)
+			}
+		}
+		*list = kept
+	}
+}
+
+// editValues edits each element of a list of nodes held by value, passing
+// fn a pointer to the element. Elements replaced with nil are dropped.
+func editValues[T any, P interface {
+	*T
+	Node
+}](list *[]T, fn func(Node) Node) {
+	var dropped []bool
+	for i := range *list {
+		elem := P(&(*list)[i])
+		node := fn(elem)
+		if isNil(node) {
+			if dropped == nil {
+				dropped = make([]bool, len(*list))
+			}
+			dropped[i] = true
+			continue
+		}
+		if node != Node(elem) {
+			replace(&elem, node)
+			(*list)[i] = *elem
+		}
+	}
+	if dropped != nil {
+		kept := (*list)[:0]
+		for i, v := range *list {
+			if !dropped[i] {
+				kept = append(kept, v)
+			}
+		}
+		*list = kept
+	}
+}
+
+// isNil reports whether a node is nil or a nil pointer.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}